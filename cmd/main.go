@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/dana-team/platform-backend/internal/auth"
+	"github.com/dana-team/platform-backend/internal/controllers"
 	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/routes"
 	"github.com/dana-team/platform-backend/internal/routes/v1"
 	dnsrecordv1alpha1 "github.com/dana-team/provider-dns/apis/record/v1alpha1"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -24,8 +29,53 @@ func main() {
 	logger := initializeLogger()
 	defer syncLogger(logger)
 
-	tokenProvider := auth.DefaultTokenProvider{}
-	engine := initializeRouter(logger, tokenProvider)
+	tokenProvider, err := auth.NewTokenProviderFromEnv(context.Background())
+	if err != nil {
+		logger.Fatal("Failed to initialize token provider", zap.Error(err))
+	}
+
+	clientCache, err := middleware.NewClientCacheFromEnv(newScheme())
+	if err != nil {
+		logger.Fatal("Failed to initialize client cache", zap.Error(err))
+	}
+	go clientCache.ReportStats(context.Background(), logger)
+
+	sessionManager, err := middleware.NewSessionManagerFromEnv()
+	if err != nil {
+		logger.Fatal("Failed to initialize session manager", zap.Error(err))
+	}
+
+	loginLimiter, err := auth.NewLoginLimiterFromEnv()
+	if err != nil {
+		logger.Fatal("Failed to initialize login limiter", zap.Error(err))
+	}
+
+	ticketStore, err := middleware.NewWSTicketStoreFromEnv()
+	if err != nil {
+		logger.Fatal("Failed to initialize WebSocket ticket store", zap.Error(err))
+	}
+
+	tokenPolicy, err := controllers.NewTokenExpirationPolicyFromEnv()
+	if err != nil {
+		logger.Fatal("Failed to initialize token expiration policy", zap.Error(err))
+	}
+
+	roleCatalog, err := controllers.NewRoleCatalogFromEnv()
+	if err != nil {
+		logger.Fatal("Failed to initialize role catalog", zap.Error(err))
+	}
+
+	accessReaper, err := controllers.NewAccessReaperFromEnv(logger, roleCatalog)
+	if err != nil {
+		logger.Fatal("Failed to initialize access reaper", zap.Error(err))
+	} else if accessReaper != nil {
+		go accessReaper.Run(context.Background())
+	}
+
+	engine, err := initializeRouter(logger, tokenProvider, clientCache, sessionManager, loginLimiter, ticketStore, tokenPolicy, roleCatalog)
+	if err != nil {
+		logger.Fatal("Failed to initialize router", zap.Error(err))
+	}
 	if err := engine.Run(); err != nil {
 		panic(err.Error())
 	}
@@ -48,12 +98,15 @@ func syncLogger(logger *zap.Logger) {
 }
 
 // initializeRouter initializes the Gin router with routes for API v1.
-func initializeRouter(logger *zap.Logger, tokenProvider auth.TokenProvider) *gin.Engine {
+func initializeRouter(logger *zap.Logger, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, loginLimiter *auth.LoginLimiter, ticketStore *middleware.WSTicketStore, tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) (*gin.Engine, error) {
 	engine := gin.Default()
+	if err := routes.SetTrustedProxiesFromEnv(engine); err != nil {
+		return nil, err
+	}
 	engine.Use(middleware.LoggerMiddleware(logger))
-	v1.SetupRoutes(engine, tokenProvider, newScheme())
+	v1.SetupRoutes(engine, tokenProvider, clientCache, sessionManager, loginLimiter, ticketStore, tokenPolicy, roleCatalog)
 
-	return engine
+	return engine, nil
 }
 
 // newScheme adds the relevant APIs to the scheme for the K8S client.
@@ -63,6 +116,8 @@ func newScheme() *runtime.Scheme {
 	utilruntime.Must(cappv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(dnsrecordv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(clusterv1beta1.AddToScheme(scheme))
+	utilruntime.Must(oauthv1.AddToScheme(scheme))
+	utilruntime.Must(userv1.AddToScheme(scheme))
 
 	return scheme
 }
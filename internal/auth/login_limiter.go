@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"k8s.io/utils/clock"
+)
+
+const (
+	envLoginUserRateLimit   = "LOGIN_USER_RATE_LIMIT"
+	envLoginIPRateLimit     = "LOGIN_IP_RATE_LIMIT"
+	envLoginRateWindow      = "LOGIN_RATE_WINDOW"
+	envLoginUserMaxFailures = "LOGIN_USER_MAX_FAILURES"
+	envLoginIPMaxFailures   = "LOGIN_IP_MAX_FAILURES"
+	envLoginLockoutDuration = "LOGIN_LOCKOUT_DURATION"
+)
+
+const (
+	defaultLoginUserRateLimit   = 10
+	defaultLoginIPRateLimit     = 50
+	defaultLoginRateWindow      = time.Minute
+	defaultLoginUserMaxFailures = 5
+	defaultLoginIPMaxFailures   = 20
+	defaultLoginLockoutDuration = 15 * time.Minute
+)
+
+const (
+	attemptsKeyPrefix = "attempts"
+	failuresKeyPrefix = "failures"
+	lockoutKeyPrefix  = "lockout"
+	userKeyPrefix     = "user"
+	ipKeyPrefix       = "ip"
+)
+
+// sweepInterval is the minimum interval between two removals of expired counters from a MemoryLoginAttemptStore.
+const sweepInterval = time.Minute
+
+// LoginAttemptStore holds counters of login attempts. Each counter lives in a fixed window which starts
+// on its first increment. Stores shared between replicas, such as Redis, can implement it so that
+// the limits apply to the whole deployment rather than to each replica.
+type LoginAttemptStore interface {
+	// Increment increments the counter of the key and returns its value and the end of its window.
+	// A new window of the given length starts if the counter does not exist or its window has ended.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+
+	// Get returns the value of the counter of the key and the end of its window.
+	// It returns zero if the counter does not exist or its window has ended.
+	Get(ctx context.Context, key string) (int, time.Time, error)
+
+	// Reset removes the counter of the key.
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginLimiterConfig holds the limits of a LoginLimiter. A limit of zero disables it.
+type LoginLimiterConfig struct {
+	// UserRateLimit is the maximum number of login attempts for a username within RateWindow.
+	UserRateLimit int
+	// IPRateLimit is the maximum number of login attempts from a client IP within RateWindow.
+	IPRateLimit int
+	RateWindow  time.Duration
+	// UserMaxFailures is the number of failed logins for a username within LockoutDuration which locks it out.
+	UserMaxFailures int
+	// IPMaxFailures is the number of failed logins from a client IP within LockoutDuration which locks it out.
+	IPMaxFailures   int
+	LockoutDuration time.Duration
+}
+
+// LoginLimiter throttles login attempts per username and per client IP, and temporarily locks out
+// usernames and client IPs after repeated failed logins.
+type LoginLimiter struct {
+	store  LoginAttemptStore
+	config LoginLimiterConfig
+	clock  clock.PassiveClock
+}
+
+// NewLoginLimiterFromEnv returns a new LoginLimiter backed by a MemoryLoginAttemptStore, with the limits
+// set by the LOGIN_* environment variables.
+func NewLoginLimiterFromEnv() (*LoginLimiter, error) {
+	config := LoginLimiterConfig{}
+	var err error
+
+	if config.UserRateLimit, err = utils.GetEnvNumber(envLoginUserRateLimit, defaultLoginUserRateLimit); err != nil {
+		return nil, err
+	}
+	if config.IPRateLimit, err = utils.GetEnvNumber(envLoginIPRateLimit, defaultLoginIPRateLimit); err != nil {
+		return nil, err
+	}
+	if config.RateWindow, err = utils.GetEnvDuration(envLoginRateWindow, defaultLoginRateWindow); err != nil {
+		return nil, err
+	}
+	if config.UserMaxFailures, err = utils.GetEnvNumber(envLoginUserMaxFailures, defaultLoginUserMaxFailures); err != nil {
+		return nil, err
+	}
+	if config.IPMaxFailures, err = utils.GetEnvNumber(envLoginIPMaxFailures, defaultLoginIPMaxFailures); err != nil {
+		return nil, err
+	}
+	if config.LockoutDuration, err = utils.GetEnvDuration(envLoginLockoutDuration, defaultLoginLockoutDuration); err != nil {
+		return nil, err
+	}
+
+	return NewLoginLimiter(NewMemoryLoginAttemptStore(), config)
+}
+
+// NewLoginLimiter returns a new LoginLimiter which keeps its counters in the given store.
+func NewLoginLimiter(store LoginAttemptStore, config LoginLimiterConfig) (*LoginLimiter, error) {
+	return newLoginLimiterWithClock(store, config, clock.RealClock{})
+}
+
+// newLoginLimiterWithClock returns a new LoginLimiter which uses the given clock to compute retry delays.
+func newLoginLimiterWithClock(store LoginAttemptStore, config LoginLimiterConfig, clock clock.PassiveClock) (*LoginLimiter, error) {
+	if config.UserRateLimit < 0 || config.IPRateLimit < 0 || config.UserMaxFailures < 0 || config.IPMaxFailures < 0 {
+		return nil, fmt.Errorf("login limits must not be negative")
+	}
+	if (config.UserRateLimit > 0 || config.IPRateLimit > 0) && config.RateWindow <= 0 {
+		return nil, fmt.Errorf("login rate window must be positive, got %v", config.RateWindow)
+	}
+	if (config.UserMaxFailures > 0 || config.IPMaxFailures > 0) && config.LockoutDuration <= 0 {
+		return nil, fmt.Errorf("login lockout duration must be positive, got %v", config.LockoutDuration)
+	}
+
+	return &LoginLimiter{
+		store:  store,
+		config: config,
+		clock:  clock,
+	}, nil
+}
+
+// Allow records a login attempt of the username from the client IP. It returns how long the client
+// has to wait before retrying if the username or client IP is locked out or exceeded its rate limit,
+// and zero if the attempt is allowed.
+func (l *LoginLimiter) Allow(ctx context.Context, username, clientIP string) (time.Duration, error) {
+	userKey, ipKey := limiterKey(userKeyPrefix, username), limiterKey(ipKeyPrefix, clientIP)
+
+	for _, key := range []string{userKey, ipKey} {
+		count, resetAt, err := l.store.Get(ctx, limiterKey(lockoutKeyPrefix, key))
+		if err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			return l.retryAfter(resetAt), nil
+		}
+	}
+
+	var retryAfter time.Duration
+	for key, limit := range map[string]int{userKey: l.config.UserRateLimit, ipKey: l.config.IPRateLimit} {
+		if limit == 0 {
+			continue
+		}
+
+		count, resetAt, err := l.store.Increment(ctx, limiterKey(attemptsKeyPrefix, key), l.config.RateWindow)
+		if err != nil {
+			return 0, err
+		}
+		if count > limit {
+			retryAfter = max(retryAfter, l.retryAfter(resetAt))
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// RecordFailure records a failed login of the username from the client IP, and locks out
+// the username or client IP once it reaches its maximum number of failures.
+func (l *LoginLimiter) RecordFailure(ctx context.Context, username, clientIP string) error {
+	userKey, ipKey := limiterKey(userKeyPrefix, username), limiterKey(ipKeyPrefix, clientIP)
+
+	for key, maxFailures := range map[string]int{userKey: l.config.UserMaxFailures, ipKey: l.config.IPMaxFailures} {
+		if maxFailures == 0 {
+			continue
+		}
+
+		count, _, err := l.store.Increment(ctx, limiterKey(failuresKeyPrefix, key), l.config.LockoutDuration)
+		if err != nil {
+			return err
+		}
+		if count < maxFailures {
+			continue
+		}
+
+		if _, _, err := l.store.Increment(ctx, limiterKey(lockoutKeyPrefix, key), l.config.LockoutDuration); err != nil {
+			return err
+		}
+		if err := l.store.Reset(ctx, limiterKey(failuresKeyPrefix, key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the failed logins of the username. Failures of the client IP are kept,
+// so that a single valid account does not allow guessing the passwords of others.
+func (l *LoginLimiter) RecordSuccess(ctx context.Context, username string) error {
+	return l.store.Reset(ctx, limiterKey(failuresKeyPrefix, limiterKey(userKeyPrefix, username)))
+}
+
+// retryAfter returns the duration until resetAt, rounded up to whole seconds, and at least one second.
+func (l *LoginLimiter) retryAfter(resetAt time.Time) time.Duration {
+	retryAfter := (resetAt.Sub(l.clock.Now()) + time.Second - 1).Truncate(time.Second)
+	return max(retryAfter, time.Second)
+}
+
+// limiterKey joins the prefix and the value into a store key.
+func limiterKey(prefix, value string) string {
+	return prefix + ":" + value
+}
+
+// loginCounter is a counter of a MemoryLoginAttemptStore.
+type loginCounter struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryLoginAttemptStore is an in-memory LoginAttemptStore. Its counters are not shared between replicas.
+type MemoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	counters  map[string]*loginCounter
+	clock     clock.PassiveClock
+	lastSweep time.Time
+}
+
+// NewMemoryLoginAttemptStore returns a new empty MemoryLoginAttemptStore.
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return newMemoryLoginAttemptStoreWithClock(clock.RealClock{})
+}
+
+// newMemoryLoginAttemptStoreWithClock returns a new empty MemoryLoginAttemptStore which uses the given clock to expire counters.
+func newMemoryLoginAttemptStoreWithClock(clock clock.PassiveClock) *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		counters:  map[string]*loginCounter{},
+		clock:     clock,
+		lastSweep: clock.Now(),
+	}
+}
+
+func (m *MemoryLoginAttemptStore) Increment(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.sweep(now)
+
+	counter, ok := m.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = &loginCounter{resetAt: now.Add(window)}
+		m.counters[key] = counter
+	}
+	counter.count++
+
+	return counter.count, counter.resetAt, nil
+}
+
+func (m *MemoryLoginAttemptStore) Get(_ context.Context, key string) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counter, ok := m.counters[key]
+	if !ok || !m.clock.Now().Before(counter.resetAt) {
+		return 0, time.Time{}, nil
+	}
+
+	return counter.count, counter.resetAt, nil
+}
+
+func (m *MemoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.counters, key)
+	return nil
+}
+
+// sweep removes the expired counters, at most once per sweepInterval, so that attempts
+// with many different usernames do not grow the store forever. It must be called with the lock held.
+func (m *MemoryLoginAttemptStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, counter := range m.counters {
+		if !now.Before(counter.resetAt) {
+			delete(m.counters, key)
+		}
+	}
+	m.lastSweep = now
+}
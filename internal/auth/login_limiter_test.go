@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+const (
+	limiterTestUser      = "test_user"
+	limiterTestOtherUser = "other_user"
+	limiterTestIP        = "10.0.0.1"
+	limiterTestOtherIP   = "10.0.0.2"
+)
+
+// limiterStep is a single login attempt, or a move of the clock when advance is set.
+type limiterStep struct {
+	username   string
+	clientIP   string
+	succeed    bool
+	advance    time.Duration
+	retryAfter time.Duration
+}
+
+func TestLoginLimiter(t *testing.T) {
+	config := LoginLimiterConfig{
+		UserRateLimit:   3,
+		IPRateLimit:     5,
+		RateWindow:      time.Minute,
+		UserMaxFailures: 2,
+		IPMaxFailures:   4,
+		LockoutDuration: 10 * time.Minute,
+	}
+
+	cases := map[string]struct {
+		config LoginLimiterConfig
+		steps  []limiterStep
+	}{
+		"ShouldThrottleUsernameAboveRateLimit": {
+			config: config,
+			steps: []limiterStep{
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+				{username: limiterTestUser, clientIP: limiterTestOtherIP, succeed: true},
+				{username: limiterTestUser, clientIP: limiterTestOtherIP, retryAfter: time.Minute},
+				{advance: 30 * time.Second},
+				{username: limiterTestUser, clientIP: limiterTestIP, retryAfter: 30 * time.Second},
+				{username: limiterTestOtherUser, clientIP: limiterTestIP, succeed: true},
+				{advance: 30 * time.Second},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+			},
+		},
+		"ShouldThrottleClientIPAboveRateLimit": {
+			config: config,
+			steps: []limiterStep{
+				{username: "user-1", clientIP: limiterTestIP, succeed: true},
+				{username: "user-2", clientIP: limiterTestIP, succeed: true},
+				{username: "user-3", clientIP: limiterTestIP, succeed: true},
+				{username: "user-4", clientIP: limiterTestIP, succeed: true},
+				{username: "user-5", clientIP: limiterTestIP, succeed: true},
+				{username: "user-6", clientIP: limiterTestIP, retryAfter: time.Minute},
+				{username: "user-6", clientIP: limiterTestOtherIP, succeed: true},
+			},
+		},
+		"ShouldLockOutUsernameAfterFailures": {
+			config: config,
+			steps: []limiterStep{
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestOtherIP},
+				{username: limiterTestUser, clientIP: limiterTestIP, retryAfter: 10 * time.Minute},
+				{advance: 9 * time.Minute},
+				{username: limiterTestUser, clientIP: limiterTestOtherIP, retryAfter: time.Minute},
+				{username: limiterTestOtherUser, clientIP: limiterTestOtherIP, succeed: true},
+				{advance: time.Minute},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+			},
+		},
+		"ShouldLockOutClientIPAfterFailures": {
+			config: config,
+			steps: []limiterStep{
+				{username: "user-1", clientIP: limiterTestIP},
+				{username: "user-2", clientIP: limiterTestIP},
+				{username: "user-3", clientIP: limiterTestIP},
+				{username: "user-4", clientIP: limiterTestIP},
+				{username: "user-5", clientIP: limiterTestIP, retryAfter: 10 * time.Minute},
+				{username: "user-5", clientIP: limiterTestOtherIP, succeed: true},
+			},
+		},
+		"ShouldClearUsernameFailuresAfterSuccess": {
+			config: config,
+			steps: []limiterStep{
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+				{advance: time.Minute},
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+			},
+		},
+		"ShouldNotLimitWhenDisabled": {
+			config: LoginLimiterConfig{},
+			steps: []limiterStep{
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP},
+				{username: limiterTestUser, clientIP: limiterTestIP, succeed: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := testingclock.NewFakePassiveClock(time.Now())
+			limiter, err := newLoginLimiterWithClock(newMemoryLoginAttemptStoreWithClock(clock), tc.config, clock)
+			assert.NoError(t, err)
+
+			for i, step := range tc.steps {
+				if step.advance > 0 {
+					clock.SetTime(clock.Now().Add(step.advance))
+					continue
+				}
+
+				retryAfter, err := limiter.Allow(ctx, step.username, step.clientIP)
+				assert.NoError(t, err)
+				assert.Equal(t, step.retryAfter, retryAfter, "step %d", i)
+				if retryAfter > 0 {
+					continue
+				}
+
+				if step.succeed {
+					assert.NoError(t, limiter.RecordSuccess(ctx, step.username))
+				} else {
+					assert.NoError(t, limiter.RecordFailure(ctx, step.username, step.clientIP))
+				}
+			}
+		})
+	}
+}
+
+func TestNewLoginLimiterWithInvalidConfig(t *testing.T) {
+	_, err := NewLoginLimiter(NewMemoryLoginAttemptStore(), LoginLimiterConfig{UserRateLimit: -1})
+	assert.Error(t, err)
+
+	_, err = NewLoginLimiter(NewMemoryLoginAttemptStore(), LoginLimiterConfig{UserRateLimit: 1})
+	assert.Error(t, err)
+
+	_, err = NewLoginLimiter(NewMemoryLoginAttemptStore(), LoginLimiterConfig{IPMaxFailures: 1})
+	assert.Error(t, err)
+}
+
+func TestMemoryLoginAttemptStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	clock := testingclock.NewFakePassiveClock(time.Now())
+	store := newMemoryLoginAttemptStoreWithClock(clock)
+
+	_, _, err := store.Increment(ctx, "short", time.Second)
+	assert.NoError(t, err)
+	_, _, err = store.Increment(ctx, "long", time.Hour)
+	assert.NoError(t, err)
+
+	clock.SetTime(clock.Now().Add(sweepInterval))
+	count, _, err := store.Increment(ctx, "new", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, store.counters, 2)
+
+	count, _, err = store.Get(ctx, "long")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
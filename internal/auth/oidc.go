@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	envOIDCIssuerURL     = "OIDC_ISSUER_URL"
+	envOIDCClientID      = "OIDC_CLIENT_ID"
+	envOIDCClientSecret  = "OIDC_CLIENT_SECRET"
+	envOIDCUsernameClaim = "OIDC_USERNAME_CLAIM"
+	envOIDCGroupsClaim   = "OIDC_GROUPS_CLAIM"
+	envOIDCScopes        = "OIDC_SCOPES"
+)
+
+const (
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+	idTokenKey               = "id_token"
+	scopesSeparator          = ","
+)
+
+var (
+	ErrMissingIDToken    = errors.New("identity provider did not return an id_token")
+	ErrMissingClaim      = errors.New("token is missing a required claim")
+	ErrInvalidClaimValue = errors.New("token claim has an unexpected type")
+)
+
+// OIDCConfig holds the settings needed to talk to a standard OIDC issuer.
+type OIDCConfig struct {
+	IssuerURL          string
+	ClientID           string
+	ClientSecret       string
+	UsernameClaim      string
+	GroupsClaim        string
+	Scopes             []string
+	InsecureSkipVerify bool
+}
+
+// OIDCTokenProvider is an implementation of TokenProvider for clusters which
+// authenticate users through a standard OIDC issuer.
+type OIDCTokenProvider struct {
+	config       OIDCConfig
+	provider     *oidc.Provider
+	verifier     *oidc.IDTokenVerifier
+	oauth2Config *oauth2.Config
+	httpCtx      context.Context
+}
+
+// NewOIDCConfigFromEnv returns an OIDCConfig based on environment variables.
+func NewOIDCConfigFromEnv() (OIDCConfig, error) {
+	skipTlsVerify, err := utils.GetEnvBool(envInsecureSkipVerify, true)
+	if err != nil {
+		return OIDCConfig{}, err
+	}
+
+	config := OIDCConfig{
+		IssuerURL:          os.Getenv(envOIDCIssuerURL),
+		ClientID:           os.Getenv(envOIDCClientID),
+		ClientSecret:       os.Getenv(envOIDCClientSecret),
+		UsernameClaim:      os.Getenv(envOIDCUsernameClaim),
+		GroupsClaim:        os.Getenv(envOIDCGroupsClaim),
+		InsecureSkipVerify: skipTlsVerify,
+	}
+
+	if scopes := os.Getenv(envOIDCScopes); scopes != "" {
+		config.Scopes = strings.Split(scopes, scopesSeparator)
+	}
+
+	return config, nil
+}
+
+// NewOIDCTokenProvider discovers the issuer endpoints from its
+// .well-known/openid-configuration document and returns a new OIDCTokenProvider.
+// The signing keys of the issuer are fetched lazily and cached by the verifier.
+func NewOIDCTokenProvider(ctx context.Context, config OIDCConfig) (*OIDCTokenProvider, error) {
+	if config.IssuerURL == "" {
+		return nil, fmt.Errorf("%q must be set to use the OIDC token provider", envOIDCIssuerURL)
+	}
+	if config.ClientID == "" {
+		return nil, fmt.Errorf("%q must be set to use the OIDC token provider", envOIDCClientID)
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = defaultOIDCUsernameClaim
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = defaultOIDCGroupsClaim
+	}
+
+	httpClient := createHTTPClient(config.InsecureSkipVerify)
+	httpCtx := oidc.ClientContext(context.Background(), httpClient)
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, httpClient), config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %q: %v", config.IssuerURL, err)
+	}
+
+	return &OIDCTokenProvider{
+		config:   config,
+		provider: provider,
+		verifier: provider.VerifierContext(httpCtx, &oidc.Config{ClientID: config.ClientID}),
+		oauth2Config: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, config.Scopes...),
+		},
+		httpCtx: httpCtx,
+	}, nil
+}
+
+// ObtainToken exchanges the username and password for an ID token using the
+// Resource Owner Password Credentials grant of the issuer. The ID token is returned
+// since it is the token which the Kubernetes API server validates.
+func (o *OIDCTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	ctxWithClient := context.WithValue(ctx.Request.Context(), oauth2.HTTPClient, createHTTPClient(o.config.InsecureSkipVerify))
+
+	logger.Debug(fmt.Sprintf("trying to get token from: %q", o.oauth2Config.Endpoint.TokenURL))
+	tok, err := o.oauth2Config.PasswordCredentialsToken(ctxWithClient, username, password)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == errorCodeInvalidGrant {
+			return nil, ErrInvalidCredentials
+		}
+		logger.Error("failed to obtain OIDC token", zap.Error(err))
+		return nil, fmt.Errorf("failed to obtain OIDC token: %v", err)
+	}
+
+	return o.convertToIDToken(tok)
+}
+
+// RefreshToken exchanges the refresh token for a new ID token through the token endpoint of the issuer.
+func (o *OIDCTokenProvider) RefreshToken(refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	ctxWithClient := context.WithValue(ctx.Request.Context(), oauth2.HTTPClient, createHTTPClient(o.config.InsecureSkipVerify))
+
+	logger.Debug(fmt.Sprintf("trying to refresh token from: %q", o.oauth2Config.Endpoint.TokenURL))
+	tok, err := refreshOAuth2Token(ctxWithClient, o.oauth2Config, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.convertToIDToken(tok)
+}
+
+// convertToIDToken returns a token which holds the ID token issued alongside the access token,
+// together with the expiry of the ID token and the refresh token, if one was issued.
+func (o *OIDCTokenProvider) convertToIDToken(tok *oauth2.Token) (*oauth2.Token, error) {
+	rawIDToken, ok := tok.Extra(idTokenKey).(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	idToken, err := o.verifier.Verify(o.httpCtx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify OIDC token: %v", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken:  rawIDToken,
+		TokenType:    tok.TokenType,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       idToken.Expiry,
+	}, nil
+}
+
+// ObtainUsername validates the token and returns the username claim from it.
+func (o *OIDCTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
+	identity, err := o.ObtainIdentity(token, logger)
+	if err != nil {
+		return "", err
+	}
+
+	return identity.Username, nil
+}
+
+// ObtainIdentity validates the token locally against the cached signing keys of
+// the issuer and returns the username and groups from the configured claims.
+// The subject of the token is used as the UID.
+func (o *OIDCTokenProvider) ObtainIdentity(token string, logger *zap.Logger) (Identity, error) {
+	idToken, err := o.verifier.Verify(o.httpCtx, token)
+	if err != nil {
+		logger.Error("failed to verify OIDC token", zap.Error(err))
+		return Identity{}, fmt.Errorf("failed to verify OIDC token: %v", err)
+	}
+
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("failed to decode OIDC token claims: %v", err)
+	}
+
+	username, err := getStringClaim(claims, o.config.UsernameClaim)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	groups, err := getStringSliceClaim(claims, o.config.GroupsClaim)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{Username: username, UID: idToken.Subject, Groups: groups}, nil
+}
+
+// getStringClaim returns the value of a required string claim.
+func getStringClaim(claims map[string]interface{}, name string) (string, error) {
+	value, ok := claims[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrMissingClaim, name)
+	}
+
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidClaimValue, name)
+	}
+
+	return str, nil
+}
+
+// getStringSliceClaim returns the value of an optional claim which holds either
+// a single string or a list of strings.
+func getStringSliceClaim(claims map[string]interface{}, name string) ([]string, error) {
+	value, ok := claims[name]
+	if !ok {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidClaimValue, name)
+			}
+			values = append(values, str)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidClaimValue, name)
+	}
+}
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	oidcTestClientID = "platform-backend"
+	oidcTestKeyID    = "test-key"
+	oidcTestUser     = "test_user"
+	oidcTestPassword = "test_password"
+	oidcTestSubject  = "1234"
+
+	oidcTestRefreshToken = "test_refresh_token"
+)
+
+// fakeIssuer is an in-process OIDC issuer used for tests.
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+// newFakeIssuer starts a fake OIDC issuer which serves discovery, JWKS and
+// password-grant token endpoints.
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	issuer := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.server.URL,
+			"authorization_endpoint":                issuer.server.URL + "/auth",
+			"token_endpoint":                        issuer.server.URL + "/token",
+			"jwks_uri":                              issuer.server.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{string(jose.RS256)},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: oidcTestKeyID, Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		validPassword := r.FormValue("grant_type") == "password" &&
+			r.FormValue("username") == oidcTestUser && r.FormValue("password") == oidcTestPassword
+		validRefresh := r.FormValue("grant_type") == "refresh_token" && r.FormValue("refresh_token") == oidcTestRefreshToken
+		if !validPassword && !validRefresh {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "test_access_token",
+			"token_type":    "bearer",
+			"refresh_token": oidcTestRefreshToken,
+			"expires_in":    300,
+			"id_token":      issuer.sign(t, issuer.claims(oidcTestUser, []string{"dev"}), key),
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+// claims returns a set of valid claims for the given user.
+func (f *fakeIssuer) claims(username string, groups []string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                f.server.URL,
+		"aud":                oidcTestClientID,
+		"sub":                oidcTestSubject,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"preferred_username": username,
+		"groups":             groups,
+	}
+}
+
+// sign signs the claims with the given key.
+func (f *fakeIssuer) sign(t *testing.T, claims map[string]interface{}, key *rsa.PrivateKey) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", oidcTestKeyID),
+	)
+	assert.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	assert.NoError(t, err)
+
+	return token
+}
+
+func newTestOIDCTokenProvider(t *testing.T, issuer *fakeIssuer) *OIDCTokenProvider {
+	provider, err := NewOIDCTokenProvider(context.Background(), OIDCConfig{
+		IssuerURL: issuer.server.URL,
+		ClientID:  oidcTestClientID,
+	})
+	assert.NoError(t, err)
+
+	return provider
+}
+
+func TestOIDCObtainToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.server.Close()
+	provider := newTestOIDCTokenProvider(t, issuer)
+
+	type args struct {
+		username string
+		password string
+	}
+	type want struct {
+		expectedError error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSuccessObtainingToken": {
+			args: args{
+				username: oidcTestUser,
+				password: oidcTestPassword,
+			},
+		},
+		"ShouldFailWithInvalidCredentials": {
+			args: args{
+				username: oidcTestUser,
+				password: "invalid_password",
+			},
+			want: want{
+				expectedError: ErrInvalidCredentials,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := zap.NewProduction()
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+
+			token, err := provider.ObtainToken(tc.args.username, tc.args.password, logger, c)
+			if tc.want.expectedError != nil {
+				assert.ErrorIs(t, err, tc.want.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, oidcTestRefreshToken, token.RefreshToken)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+
+			identity, err := provider.ObtainIdentity(token.AccessToken, logger)
+			assert.NoError(t, err)
+			assert.Equal(t, Identity{Username: oidcTestUser, UID: oidcTestSubject, Groups: []string{"dev"}}, identity)
+		})
+	}
+}
+
+func TestOIDCRefreshToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.server.Close()
+	provider := newTestOIDCTokenProvider(t, issuer)
+
+	logger, _ := zap.NewProduction()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/login/refresh", nil)
+
+	token, err := provider.RefreshToken(oidcTestRefreshToken, logger, c)
+	assert.NoError(t, err)
+	assert.Equal(t, oidcTestRefreshToken, token.RefreshToken)
+
+	identity, err := provider.ObtainIdentity(token.AccessToken, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, oidcTestUser, identity.Username)
+
+	_, err = provider.RefreshToken("invalid_refresh_token", logger, c)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestOIDCObtainIdentity(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.server.Close()
+	provider := newTestOIDCTokenProvider(t, issuer)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	expiredClaims := issuer.claims(oidcTestUser, nil)
+	expiredClaims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	wrongAudienceClaims := issuer.claims(oidcTestUser, nil)
+	wrongAudienceClaims["aud"] = "other-client"
+
+	missingUsernameClaims := issuer.claims(oidcTestUser, nil)
+	delete(missingUsernameClaims, defaultOIDCUsernameClaim)
+
+	singleGroupClaims := issuer.claims(oidcTestUser, nil)
+	singleGroupClaims[defaultOIDCGroupsClaim] = "admins"
+
+	type want struct {
+		identity      Identity
+		expectedError error
+		shouldFail    bool
+	}
+	cases := map[string]struct {
+		token string
+		want  want
+	}{
+		"ShouldSucceedWithValidToken": {
+			token: issuer.sign(t, issuer.claims(oidcTestUser, []string{"dev", "ops"}), issuer.key),
+			want: want{
+				identity: Identity{Username: oidcTestUser, UID: oidcTestSubject, Groups: []string{"dev", "ops"}},
+			},
+		},
+		"ShouldSucceedWithSingleGroupString": {
+			token: issuer.sign(t, singleGroupClaims, issuer.key),
+			want: want{
+				identity: Identity{Username: oidcTestUser, UID: oidcTestSubject, Groups: []string{"admins"}},
+			},
+		},
+		"ShouldFailWithUnknownSigningKey": {
+			token: issuer.sign(t, issuer.claims(oidcTestUser, nil), otherKey),
+			want:  want{shouldFail: true},
+		},
+		"ShouldFailWithExpiredToken": {
+			token: issuer.sign(t, expiredClaims, issuer.key),
+			want:  want{shouldFail: true},
+		},
+		"ShouldFailWithWrongAudience": {
+			token: issuer.sign(t, wrongAudienceClaims, issuer.key),
+			want:  want{shouldFail: true},
+		},
+		"ShouldFailWithMissingUsernameClaim": {
+			token: issuer.sign(t, missingUsernameClaims, issuer.key),
+			want:  want{shouldFail: true, expectedError: ErrMissingClaim},
+		},
+		"ShouldFailWithMalformedToken": {
+			token: "not-a-jwt",
+			want:  want{shouldFail: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := zap.NewProduction()
+			identity, err := provider.ObtainIdentity(tc.token, logger)
+			if tc.want.shouldFail {
+				assert.Error(t, err)
+				if tc.want.expectedError != nil {
+					assert.True(t, errors.Is(err, tc.want.expectedError))
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.identity, identity)
+		})
+	}
+}
+
+func TestNewOIDCTokenProviderWithCustomClaims(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.server.Close()
+
+	provider, err := NewOIDCTokenProvider(context.Background(), OIDCConfig{
+		IssuerURL:     issuer.server.URL,
+		ClientID:      oidcTestClientID,
+		UsernameClaim: "email",
+		GroupsClaim:   "roles",
+	})
+	assert.NoError(t, err)
+
+	claims := issuer.claims(oidcTestUser, nil)
+	claims["email"] = "user@example.com"
+	claims["roles"] = []string{"viewer"}
+
+	logger, _ := zap.NewProduction()
+	identity, err := provider.ObtainIdentity(issuer.sign(t, claims, issuer.key), logger)
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Username: "user@example.com", UID: oidcTestSubject, Groups: []string{"viewer"}}, identity)
+}
+
+func TestNewOIDCTokenProviderWithUnreachableIssuer(t *testing.T) {
+	_, err := NewOIDCTokenProvider(context.Background(), OIDCConfig{
+		IssuerURL: "http://127.0.0.1:1",
+		ClientID:  oidcTestClientID,
+	})
+	assert.Error(t, err)
+}
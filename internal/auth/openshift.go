@@ -2,17 +2,25 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/gin-gonic/gin"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"io"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"net/url"
 	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 const (
@@ -22,6 +30,8 @@ const (
 	httpBearerTokenPrefix   = "Bearer"
 	stateToken              = "state"
 	keyCodeParam            = "code"
+	errorCodeInvalidGrant   = "invalid_grant"
+	sha256TokenPrefix       = "sha256~"
 )
 
 const (
@@ -36,17 +46,19 @@ const (
 type OpenshiftUserInfo struct {
 	Metadata struct {
 		Name string `json:"name"`
+		UID  string `json:"uid"`
 	} `json:"metadata"`
+	Groups []string `json:"groups"`
 }
 
 // ObtainOpenshiftToken obtains an OAuth token from OpenShift using the provided username and password.
-// It returns the access token on success, or an error on failure.
+// It returns the token, including its expiry and refresh token, on success, or an error on failure.
 // This code was taken from https://gist.github.com/kadel/c30b3085e2e90a93393b99a2b39f4806, with minor adjustments.
-func ObtainOpenshiftToken(username, password string, logger *zap.Logger, ctx *gin.Context) (string, error) {
+func ObtainOpenshiftToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
 	skipTlsVerify, err := utils.GetEnvBool(envInsecureSkipVerify, true)
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to parse environment variable %q", envInsecureSkipVerify), zap.Error(err))
-		return "", err
+		return nil, err
 	}
 
 	httpClient := createHTTPClient(skipTlsVerify)
@@ -57,31 +69,63 @@ func ObtainOpenshiftToken(username, password string, logger *zap.Logger, ctx *gi
 	authCodeReq, err := createAuthCodeRequest(conf, username, password)
 	if err != nil {
 		logger.Error("failed to create AuthCode request", zap.Error(err))
-		return "", err
+		return nil, err
 	}
 
 	logger.Debug(fmt.Sprintf("tyring to get auth code from: %q", authCodeReq.URL.String()))
 	authCodeResp, err := codeHTTPClient.Do(authCodeReq)
 	if err != nil {
 		logger.Error("failed to obtain auth code", zap.Error(err))
-		return "", fmt.Errorf("failed to obtain auth code: %v", err)
+		return nil, fmt.Errorf("failed to obtain auth code: %v", err)
 	}
 
 	if err := checkAuthCodeResponse(authCodeResp); err != nil {
 		logger.Error("failed to check AuthCode response", zap.Error(err))
-		return "", err
+		return nil, err
 	}
 	logger.Debug(fmt.Sprintf("fetched auth code successfully from: %q with status: %q", authCodeResp.Request.URL.String(), authCodeResp.StatusCode))
 
 	code, err := parseAuthCode(authCodeResp)
 	if err != nil {
 		logger.Error("failed to parse AuthCode", zap.Error(err))
-		return "", err
+		return nil, err
 	}
 
 	return exchangeCodeForToken(ctxWithClient, conf, code)
 }
 
+// RefreshOpenshiftToken exchanges the refresh token for a new OAuth token from OpenShift.
+func RefreshOpenshiftToken(refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	skipTlsVerify, err := utils.GetEnvBool(envInsecureSkipVerify, true)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to parse environment variable %q", envInsecureSkipVerify), zap.Error(err))
+		return nil, err
+	}
+
+	ctxWithClient := context.WithValue(ctx.Request.Context(), oauth2.HTTPClient, createHTTPClient(skipTlsVerify))
+	conf := getOAuthConfig()
+
+	logger.Debug(fmt.Sprintf("trying to refresh token from: %q", conf.Endpoint.TokenURL))
+	return refreshOAuth2Token(ctxWithClient, conf, refreshToken)
+}
+
+// RevokeOpenshiftToken deletes the OAuthAccessToken object of the token, using the client of the user who owns it.
+// Deleting a token which was already deleted is not considered an error.
+func RevokeOpenshiftToken(ctx context.Context, client client.Client, token string, logger *zap.Logger) error {
+	accessToken := &oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: OAuthAccessTokenName(token),
+		},
+	}
+
+	logger.Debug("trying to delete OAuthAccessToken")
+	if err := client.Delete(ctx, accessToken); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
 // ObtainOpenshiftUsername fetches the username from the OpenShift userinfo endpoint.
 func ObtainOpenshiftUsername(token string, logger *zap.Logger) (string, error) {
 	userInfo, err := fetchOpenshiftUserInfo(token, logger)
@@ -93,6 +137,21 @@ func ObtainOpenshiftUsername(token string, logger *zap.Logger) (string, error) {
 	return userInfo.Metadata.Name, nil
 }
 
+// ObtainOpenshiftIdentity fetches the username, UID and groups from the OpenShift userinfo endpoint.
+func ObtainOpenshiftIdentity(token string, logger *zap.Logger) (Identity, error) {
+	userInfo, err := fetchOpenshiftUserInfo(token, logger)
+	if err != nil {
+		logger.Error("failed to fetch Openshift user info", zap.Error(err))
+		return Identity{}, fmt.Errorf("failed to obtain OpenShift identity: %v", err)
+	}
+
+	return Identity{
+		Username: userInfo.Metadata.Name,
+		UID:      userInfo.Metadata.UID,
+		Groups:   userInfo.Groups,
+	}, nil
+}
+
 // getOAuthConfig returns an OAuth2 configuration based on environment variables.
 func getOAuthConfig() *oauth2.Config {
 	return &oauth2.Config{
@@ -180,14 +239,39 @@ func parseAuthCode(authCodeResp *http.Response) (string, error) {
 	return code, nil
 }
 
-// exchangeCodeForToken exchanges the authorization code for an access token
+// exchangeCodeForToken exchanges the authorization code for a token
 // using the provided OAuth2 configuration.
-func exchangeCodeForToken(ctx context.Context, conf *oauth2.Config, code string) (string, error) {
+func exchangeCodeForToken(ctx context.Context, conf *oauth2.Config, code string) (*oauth2.Token, error) {
 	tok, err := conf.Exchange(ctx, code)
 	if err != nil {
-		return "", fmt.Errorf("failed to exchange code for token: %v", err)
+		return nil, fmt.Errorf("failed to exchange code for token: %v", err)
+	}
+	return tok, nil
+}
+
+// refreshOAuth2Token exchanges the refresh token for a new token using the provided OAuth2 configuration.
+// If the identity provider does not issue a new refresh token, the given one is kept.
+func refreshOAuth2Token(ctx context.Context, conf *oauth2.Config, refreshToken string) (*oauth2.Token, error) {
+	tok, err := conf.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == errorCodeInvalidGrant {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to refresh token: %v", err)
 	}
-	return tok.AccessToken, nil
+	return tok, nil
+}
+
+// OAuthAccessTokenName returns the name of the OAuthAccessToken object of the token. The objects of
+// sha256-prefixed tokens are named after the hash of the token, while legacy tokens are named after the token itself.
+func OAuthAccessTokenName(token string) string {
+	if !strings.HasPrefix(token, sha256TokenPrefix) {
+		return token
+	}
+
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(token, sha256TokenPrefix)))
+	return sha256TokenPrefix + base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
 // fetchOpenshiftUserInfo retrieves user information from the OpenShift userinfo endpoint.
@@ -2,12 +2,20 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"github.com/gin-gonic/gin"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
 
@@ -107,8 +115,78 @@ func TestObtainOpenshiftToken(t *testing.T) {
 				t.Errorf("ObtainOpenshiftToken() expected error: %v, got: %v", tc.want.expectedError, err)
 				return
 			}
-			if token != tc.want.expectedToken {
-				t.Errorf("ObtainOpenshiftToken() expected token: %v, got: %v", tc.want.expectedToken, token)
+			if tc.want.expectedError == nil && token.AccessToken != tc.want.expectedToken {
+				t.Errorf("ObtainOpenshiftToken() expected token: %v, got: %v", tc.want.expectedToken, token.AccessToken)
+			}
+		})
+	}
+}
+
+func TestRefreshOpenshiftToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" && r.FormValue("grant_type") == "refresh_token" && r.FormValue("refresh_token") == "test_refresh_token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "test_refreshed_access_token", "token_type": "bearer", "expires_in": 3600}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+	}))
+	defer ts.Close()
+
+	_ = os.Setenv(envInsecureSkipVerify, "true")
+	_ = os.Setenv(envKubeClientID, "clientid")
+	_ = os.Setenv(envKubeTokenURL, ts.URL+"/token")
+
+	type want struct {
+		expectedToken string
+		expectedError error
+	}
+	cases := map[string]struct {
+		refreshToken string
+		want         want
+	}{
+		"ShouldSuccessRefreshingToken": {
+			refreshToken: "test_refresh_token",
+			want: want{
+				expectedToken: "test_refreshed_access_token",
+			},
+		},
+		"ShouldFailWithInvalidRefreshToken": {
+			refreshToken: "invalid_refresh_token",
+			want: want{
+				expectedError: ErrInvalidRefreshToken,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := zap.NewProduction()
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/login/refresh", nil)
+
+			token, err := RefreshOpenshiftToken(tc.refreshToken, logger, c)
+			if tc.want.expectedError != nil {
+				if !errors.Is(err, tc.want.expectedError) {
+					t.Errorf("RefreshOpenshiftToken() expected error: %v, got: %v", tc.want.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("RefreshOpenshiftToken() unexpected error: %v", err)
+				return
+			}
+			if token.AccessToken != tc.want.expectedToken {
+				t.Errorf("RefreshOpenshiftToken() expected token: %v, got: %v", tc.want.expectedToken, token.AccessToken)
+			}
+			if token.RefreshToken != tc.refreshToken {
+				t.Errorf("RefreshOpenshiftToken() expected refresh token to be kept, got: %v", token.RefreshToken)
+			}
+			if token.Expiry.IsZero() {
+				t.Errorf("RefreshOpenshiftToken() expected token expiry to be set")
 			}
 		})
 	}
@@ -183,3 +261,73 @@ func TestObtainOpenshiftUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestOAuthAccessTokenName(t *testing.T) {
+	sum := sha256.Sum256([]byte("abc"))
+	cases := map[string]struct {
+		token        string
+		expectedName string
+	}{
+		"ShouldHashSHA256PrefixedToken": {
+			token:        "sha256~abc",
+			expectedName: "sha256~" + base64.RawURLEncoding.EncodeToString(sum[:]),
+		},
+		"ShouldKeepLegacyToken": {
+			token:        "abc",
+			expectedName: "abc",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if objectName := OAuthAccessTokenName(tc.token); objectName != tc.expectedName {
+				t.Errorf("OAuthAccessTokenName() expected name: %v, got: %v", tc.expectedName, objectName)
+			}
+		})
+	}
+}
+
+func TestRevokeOpenshiftToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = oauthv1.AddToScheme(scheme)
+
+	cases := map[string]struct {
+		token          string
+		existingTokens []string
+	}{
+		"ShouldSuccessRevokingToken": {
+			token:          "sha256~valid_token",
+			existingTokens: []string{"sha256~valid_token", "sha256~other_token"},
+		},
+		"ShouldSuccessRevokingAlreadyRevokedToken": {
+			token:          "sha256~valid_token",
+			existingTokens: []string{"sha256~other_token"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, token := range tc.existingTokens {
+				builder.WithObjects(&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: OAuthAccessTokenName(token)}})
+			}
+			fakeClient := builder.Build()
+
+			logger, _ := zap.NewProduction()
+			if err := RevokeOpenshiftToken(context.Background(), fakeClient, tc.token, logger); err != nil {
+				t.Errorf("RevokeOpenshiftToken() unexpected error: %v", err)
+				return
+			}
+
+			err := fakeClient.Get(context.Background(), client.ObjectKey{Name: OAuthAccessTokenName(tc.token)}, &oauthv1.OAuthAccessToken{})
+			if !k8serrors.IsNotFound(err) {
+				t.Errorf("RevokeOpenshiftToken() expected token to be deleted, got: %v", err)
+			}
+
+			err = fakeClient.Get(context.Background(), client.ObjectKey{Name: OAuthAccessTokenName("sha256~other_token")}, &oauthv1.OAuthAccessToken{})
+			if err != nil {
+				t.Errorf("RevokeOpenshiftToken() expected other tokens to be kept, got: %v", err)
+			}
+		})
+	}
+}
@@ -1,28 +1,170 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	envAuthProvider     = "AUTH_PROVIDER"
+	envIdentityResolver = "IDENTITY_RESOLVER"
+)
+
+const (
+	OpenshiftAuthProvider = "openshift"
+	OIDCAuthProvider      = "oidc"
+)
+
+const (
+	ProviderIdentityResolver    = "provider"
+	TokenReviewIdentityResolver = "tokenreview"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshNotSupported = errors.New("refreshing tokens is not supported by the configured token provider")
+	ErrRevokeNotSupported  = errors.New("revoking tokens is not supported by the configured token provider")
 )
 
 // TokenProvider defines an interface for obtaining a token.
+// The returned token holds the expiry and, where the identity provider issues one, a refresh token.
 type TokenProvider interface {
-	ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (string, error)
+	ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error)
 	ObtainUsername(token string, logger *zap.Logger) (string, error)
 }
 
+// TokenRefresher defines an interface for exchanging a refresh token for a new token.
+type TokenRefresher interface {
+	RefreshToken(refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error)
+}
+
+// Identity represents the user information resolved from a token.
+type Identity struct {
+	Username string
+	UID      string
+	Groups   []string
+	Extra    map[string][]string
+}
+
+// IdentityResolver defines an interface for resolving the full identity behind a token.
+// TokenProviders which implement it expose groups and other user attributes, not just the username.
+type IdentityResolver interface {
+	ObtainIdentity(token string, logger *zap.Logger) (Identity, error)
+}
+
+// ResolveIdentity returns the identity behind the token. If the TokenProvider does not
+// implement IdentityResolver, the identity only holds the username.
+func ResolveIdentity(tokenProvider TokenProvider, token string, logger *zap.Logger) (Identity, error) {
+	if resolver, ok := tokenProvider.(IdentityResolver); ok {
+		return resolver.ObtainIdentity(token, logger)
+	}
+
+	username, err := tokenProvider.ObtainUsername(token, logger)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{Username: username}, nil
+}
+
+// TokenRevoker defines an interface for revoking a token, so that it can no longer be used.
+// The token is revoked through the client of the user who owns it.
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, client client.Client, token string, logger *zap.Logger) error
+}
+
+// RevokeToken revokes the token. If the TokenProvider does not implement TokenRevoker,
+// ErrRevokeNotSupported is returned.
+func RevokeToken(ctx context.Context, tokenProvider TokenProvider, client client.Client, token string, logger *zap.Logger) error {
+	revoker, ok := tokenProvider.(TokenRevoker)
+	if !ok {
+		return ErrRevokeNotSupported
+	}
+
+	return revoker.RevokeToken(ctx, client, token, logger)
+}
+
+// RefreshToken exchanges the refresh token for a new token. If the TokenProvider does not
+// implement TokenRefresher, ErrRefreshNotSupported is returned.
+func RefreshToken(tokenProvider TokenProvider, refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	refresher, ok := tokenProvider.(TokenRefresher)
+	if !ok {
+		return nil, ErrRefreshNotSupported
+	}
+
+	return refresher.RefreshToken(refreshToken, logger, ctx)
+}
+
 // DefaultTokenProvider is a default implementation of TokenProvider.
 type DefaultTokenProvider struct{}
 
-func (d DefaultTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (string, error) {
+func (d DefaultTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
 	return ObtainOpenshiftToken(username, password, logger, ctx)
 }
 
+func (d DefaultTokenProvider) RefreshToken(refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	return RefreshOpenshiftToken(refreshToken, logger, ctx)
+}
+
 func (d DefaultTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
 	return ObtainOpenshiftUsername(token, logger)
 }
+
+func (d DefaultTokenProvider) RevokeToken(ctx context.Context, client client.Client, token string, logger *zap.Logger) error {
+	return RevokeOpenshiftToken(ctx, client, token, logger)
+}
+
+func (d DefaultTokenProvider) ObtainIdentity(token string, logger *zap.Logger) (Identity, error) {
+	return ObtainOpenshiftIdentity(token, logger)
+}
+
+// NewTokenProviderFromEnv returns the TokenProvider chosen by the AUTH_PROVIDER environment variable.
+// The OpenShift provider is used when the variable is unset. When IDENTITY_RESOLVER is set to
+// "tokenreview", identities are resolved through the Kubernetes TokenReview API instead.
+func NewTokenProviderFromEnv(ctx context.Context) (TokenProvider, error) {
+	tokenProvider, err := newLoginTokenProviderFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch identityResolver := os.Getenv(envIdentityResolver); identityResolver {
+	case "", ProviderIdentityResolver:
+		return tokenProvider, nil
+	case TokenReviewIdentityResolver:
+		client, err := newTokenReviewClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewTokenReviewTokenProvider(tokenProvider, client), nil
+	default:
+		return nil, fmt.Errorf("unsupported %s %q", envIdentityResolver, identityResolver)
+	}
+}
+
+// newLoginTokenProviderFromEnv returns the TokenProvider used for logging in, based on the AUTH_PROVIDER environment variable.
+func newLoginTokenProviderFromEnv(ctx context.Context) (TokenProvider, error) {
+	switch authProvider := os.Getenv(envAuthProvider); authProvider {
+	case "", OpenshiftAuthProvider:
+		return DefaultTokenProvider{}, nil
+	case OIDCAuthProvider:
+		config, err := NewOIDCConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		provider, err := NewOIDCTokenProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unsupported %s %q", envAuthProvider, authProvider)
+	}
+}
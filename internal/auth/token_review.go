@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	ErrLoginNotSupported     = errors.New("login is not supported by the configured token provider")
+	ErrTokenNotAuthenticated = errors.New("token was not authenticated")
+)
+
+// TokenReviewTokenProvider is an implementation of TokenProvider which resolves identities
+// through the authentication.k8s.io/v1 TokenReview API, which is available on any Kubernetes cluster.
+// Logins are delegated to the wrapped TokenProvider, if one is set.
+type TokenReviewTokenProvider struct {
+	loginProvider TokenProvider
+	client        kubernetes.Interface
+}
+
+// NewTokenReviewTokenProvider returns a new TokenReviewTokenProvider. The client must be allowed
+// to create TokenReviews, which is usually granted through the system:auth-delegator ClusterRole.
+func NewTokenReviewTokenProvider(loginProvider TokenProvider, client kubernetes.Interface) *TokenReviewTokenProvider {
+	return &TokenReviewTokenProvider{
+		loginProvider: loginProvider,
+		client:        client,
+	}
+}
+
+// newTokenReviewClient creates a Kubernetes client using the credentials of the backend itself.
+func newTokenReviewClient() (kubernetes.Interface, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backend Kubernetes config: %v", err)
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+func (t *TokenReviewTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	if t.loginProvider == nil {
+		return nil, ErrLoginNotSupported
+	}
+
+	return t.loginProvider.ObtainToken(username, password, logger, ctx)
+}
+
+func (t *TokenReviewTokenProvider) RefreshToken(refreshToken string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	if t.loginProvider == nil {
+		return nil, ErrRefreshNotSupported
+	}
+
+	return RefreshToken(t.loginProvider, refreshToken, logger, ctx)
+}
+
+func (t *TokenReviewTokenProvider) RevokeToken(ctx context.Context, client client.Client, token string, logger *zap.Logger) error {
+	if t.loginProvider == nil {
+		return ErrRevokeNotSupported
+	}
+
+	return RevokeToken(ctx, t.loginProvider, client, token, logger)
+}
+
+func (t *TokenReviewTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
+	identity, err := t.ObtainIdentity(token, logger)
+	if err != nil {
+		return "", err
+	}
+
+	return identity.Username, nil
+}
+
+// ObtainIdentity creates a TokenReview for the token and returns the username, UID,
+// groups and extra fields of the authenticated user.
+func (t *TokenReviewTokenProvider) ObtainIdentity(token string, logger *zap.Logger) (Identity, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+
+	logger.Debug("trying to review token")
+	result, err := t.client.AuthenticationV1().TokenReviews().Create(context.Background(), review, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error("failed to create TokenReview", zap.Error(err))
+		return Identity{}, fmt.Errorf("failed to create TokenReview: %v", err)
+	}
+
+	if !result.Status.Authenticated {
+		if result.Status.Error != "" {
+			return Identity{}, fmt.Errorf("%w: %s", ErrTokenNotAuthenticated, result.Status.Error)
+		}
+		return Identity{}, ErrTokenNotAuthenticated
+	}
+
+	return convertUserInfoToIdentity(result.Status.User), nil
+}
+
+// convertUserInfoToIdentity converts the UserInfo of a TokenReview to an Identity.
+func convertUserInfoToIdentity(userInfo authenticationv1.UserInfo) Identity {
+	identity := Identity{
+		Username: userInfo.Username,
+		UID:      userInfo.UID,
+		Groups:   userInfo.Groups,
+	}
+
+	if len(userInfo.Extra) > 0 {
+		identity.Extra = make(map[string][]string, len(userInfo.Extra))
+		for key, value := range userInfo.Extra {
+			identity.Extra[key] = value
+		}
+	}
+
+	return identity
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	validReviewToken   = "valid_token"
+	invalidReviewToken = "invalid_token"
+	failingReviewToken = "failing_token"
+)
+
+// newTokenReviewFakeClient returns a fake client which authenticates only validReviewToken.
+func newTokenReviewFakeClient() *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+
+		switch review.Spec.Token {
+		case validReviewToken:
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User: authenticationv1.UserInfo{
+					Username: "test_user",
+					UID:      "1234",
+					Groups:   []string{"dev", "system:authenticated"},
+					Extra:    map[string]authenticationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:full"}},
+				},
+			}
+		case failingReviewToken:
+			return true, nil, errors.New("tokenreviews.authentication.k8s.io is forbidden")
+		default:
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: false, Error: "token has expired"}
+		}
+
+		return true, review, nil
+	})
+
+	return client
+}
+
+func TestTokenReviewObtainIdentity(t *testing.T) {
+	type want struct {
+		identity      Identity
+		expectedError error
+		shouldFail    bool
+	}
+	cases := map[string]struct {
+		token string
+		want  want
+	}{
+		"ShouldSucceedWithAuthenticatedToken": {
+			token: validReviewToken,
+			want: want{
+				identity: Identity{
+					Username: "test_user",
+					UID:      "1234",
+					Groups:   []string{"dev", "system:authenticated"},
+					Extra:    map[string][]string{"scopes.authorization.openshift.io": {"user:full"}},
+				},
+			},
+		},
+		"ShouldFailWithUnauthenticatedToken": {
+			token: invalidReviewToken,
+			want:  want{shouldFail: true, expectedError: ErrTokenNotAuthenticated},
+		},
+		"ShouldFailWhenTokenReviewCannotBeCreated": {
+			token: failingReviewToken,
+			want:  want{shouldFail: true},
+		},
+	}
+
+	provider := NewTokenReviewTokenProvider(nil, newTokenReviewFakeClient())
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := zap.NewProduction()
+			identity, err := provider.ObtainIdentity(tc.token, logger)
+			if tc.want.shouldFail {
+				assert.Error(t, err)
+				if tc.want.expectedError != nil {
+					assert.ErrorIs(t, err, tc.want.expectedError)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.identity, identity)
+		})
+	}
+}
+
+func TestTokenReviewUnsupportedLogin(t *testing.T) {
+	provider := NewTokenReviewTokenProvider(nil, newTokenReviewFakeClient())
+	logger, _ := zap.NewProduction()
+
+	_, err := provider.ObtainToken("test_user", "test_password", logger, nil)
+	assert.ErrorIs(t, err, ErrLoginNotSupported)
+
+	_, err = provider.RefreshToken("refresh_token", logger, nil)
+	assert.ErrorIs(t, err, ErrRefreshNotSupported)
+
+	_, err = NewTokenReviewTokenProvider(usernameOnlyTokenProvider{}, newTokenReviewFakeClient()).RefreshToken("refresh_token", logger, nil)
+	assert.ErrorIs(t, err, ErrRefreshNotSupported)
+}
+
+// usernameOnlyTokenProvider is a TokenProvider which does not implement IdentityResolver.
+type usernameOnlyTokenProvider struct{}
+
+func (u usernameOnlyTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	return nil, ErrLoginNotSupported
+}
+
+func (u usernameOnlyTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
+	return "test_user", nil
+}
+
+func TestResolveIdentity(t *testing.T) {
+	logger, _ := zap.NewProduction()
+
+	identity, err := ResolveIdentity(NewTokenReviewTokenProvider(nil, newTokenReviewFakeClient()), validReviewToken, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev", "system:authenticated"}, identity.Groups)
+
+	identity, err = ResolveIdentity(usernameOnlyTokenProvider{}, validReviewToken, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Username: "test_user"}, identity)
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"go.uber.org/zap"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/clock"
+)
+
+const (
+	envAccessReaperEnabled     = "ACCESS_REAPER_ENABLED"
+	envAccessReaperInterval    = "ACCESS_REAPER_INTERVAL"
+	envLeaderElectionNamespace = "LEADER_ELECTION_NAMESPACE"
+)
+
+const (
+	defaultAccessReaperInterval = time.Minute
+	accessReaperLeaseName       = "platform-backend-access-reaper"
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+const (
+	ErrCouldNotListExpiringRoleBindings = "Could not list rolebindings to remove expired access"
+	ErrCouldNotRemoveExpiredAccess      = "Could not remove expired access of %s %q to namespace %q"
+)
+
+// AccessReaper removes the RoleBindings of members whose access has expired. Several replicas of the backend
+// may run an AccessReaper, since only the one holding the lease of the AccessReaper removes RoleBindings.
+type AccessReaper struct {
+	client         kubernetes.Interface
+	interval       time.Duration
+	clock          clock.WithTicker
+	logger         *zap.Logger
+	leaseNamespace string
+	identity       string
+	roleCatalog    *RoleCatalog
+}
+
+// NewAccessReaperFromEnv returns a new AccessReaper which authenticates as the serviceaccount of the backend and
+// removes expired access every ACCESS_REAPER_INTERVAL, or nil if ACCESS_REAPER_ENABLED is not set. Its lease is
+// held in LEADER_ELECTION_NAMESPACE, which defaults to the namespace of the backend.
+func NewAccessReaperFromEnv(logger *zap.Logger, roleCatalog *RoleCatalog) (*AccessReaper, error) {
+	enabled, err := utils.GetEnvBool(envAccessReaperEnabled, false)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	interval, err := utils.GetEnvDuration(envAccessReaperInterval, defaultAccessReaperInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	reaper, err := NewAccessReaper(client, interval, logger, roleCatalog)
+	if err != nil {
+		return nil, err
+	}
+
+	reaper.leaseNamespace = os.Getenv(envLeaderElectionNamespace)
+	if reaper.leaseNamespace == "" {
+		namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the namespace of the lease, set %s: %v", envLeaderElectionNamespace, err)
+		}
+		reaper.leaseNamespace = strings.TrimSpace(string(namespace))
+	}
+
+	return reaper, nil
+}
+
+// NewAccessReaper returns a new AccessReaper which removes expired access every interval.
+func NewAccessReaper(client kubernetes.Interface, interval time.Duration, logger *zap.Logger, roleCatalog *RoleCatalog) (*AccessReaper, error) {
+	return newAccessReaperWithClock(client, interval, clock.RealClock{}, logger, roleCatalog)
+}
+
+// newAccessReaperWithClock returns a new AccessReaper which uses the given clock to expire access.
+func newAccessReaperWithClock(client kubernetes.Interface, interval time.Duration, clock clock.WithTicker, logger *zap.Logger, roleCatalog *RoleCatalog) (*AccessReaper, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("access reaper interval must be positive, got %v", interval)
+	}
+
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessReaper{
+		client:      client,
+		interval:    interval,
+		clock:       clock,
+		logger:      logger,
+		identity:    identity,
+		roleCatalog: roleCatalog,
+	}, nil
+}
+
+// Run removes expired access every interval while the AccessReaper holds its lease, and campaigns
+// for the lease again whenever it is lost, until the context is done.
+func (r *AccessReaper) Run(ctx context.Context) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: accessReaperLeaseName, Namespace: r.leaseNamespace},
+		Client:     r.client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: r.identity},
+	}
+
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			Name:            accessReaperLeaseName,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: r.reapPeriodically,
+				OnStoppedLeading: func() {
+					r.logger.Info(fmt.Sprintf("%q stopped removing expired access", r.identity))
+				},
+			},
+		})
+	}
+}
+
+// reapPeriodically removes expired access every interval until the context is done.
+func (r *AccessReaper) reapPeriodically(ctx context.Context) {
+	r.logger.Info(fmt.Sprintf("%q started removing expired access every %v", r.identity, r.interval))
+
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Reap(ctx); err != nil {
+			r.logger.Error(fmt.Sprintf("Failed to remove expired access with error: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Reap removes the RoleBindings whose access has expired in all namespaces, and logs each removal.
+// RoleBindings which changed since they were listed are left for the next run.
+func (r *AccessReaper) Reap(ctx context.Context) error {
+	roleBindings, err := r.client.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: utils.ManagedLabelSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %v", ErrCouldNotListExpiringRoleBindings, err)
+	}
+
+	now := r.clock.Now()
+	var errs []error
+	for _, roleBinding := range roleBindings.Items {
+		expiresAt, ok := getExpiration(roleBinding)
+		if !ok || now.Before(expiresAt) {
+			continue
+		}
+
+		if err := r.removeExpiredAccess(ctx, roleBinding, expiresAt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// removeExpiredAccess deletes the RoleBinding of a member whose access has expired, unless it has changed.
+func (r *AccessReaper) removeExpiredAccess(ctx context.Context, roleBinding rbacv1.RoleBinding, expiresAt time.Time) error {
+	member := convertRoleBindingToUser(roleBinding, r.roleCatalog, expiresAt)
+	err := r.client.RbacV1().RoleBindings(roleBinding.Namespace).Delete(ctx, roleBinding.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &roleBinding.UID, ResourceVersion: &roleBinding.ResourceVersion},
+	})
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		r.logger.Debug(fmt.Sprintf("Skipped rolebinding %q in namespace %q which changed since it was listed", roleBinding.Name, roleBinding.Namespace))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: %v", fmt.Sprintf(ErrCouldNotRemoveExpiredAccess, member.Type, member.Name, roleBinding.Namespace), err)
+	}
+
+	r.logger.Info(fmt.Sprintf("Removed access of %s %q with role %q to namespace %q, which expired at %v",
+		member.Type, member.Name, member.Role, roleBinding.Namespace, expiresAt.UTC().Format(time.RFC3339)))
+	return nil
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestAccessReaperReap(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-accessReaper"
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		expiresAt string
+		removed   bool
+	}{
+		"ShouldRemoveExpiredAccess": {
+			expiresAt: now.Add(-time.Minute).Format(time.RFC3339),
+			removed:   true,
+		},
+		"ShouldRemoveAccessExpiringNow": {
+			expiresAt: now.Format(time.RFC3339),
+			removed:   true,
+		},
+		"ShouldKeepAccessExpiringLater": {
+			expiresAt: now.Add(time.Minute).Format(time.RFC3339),
+		},
+		"ShouldKeepAccessWithInvalidExpiration": {
+			expiresAt: "tomorrow",
+		},
+		"ShouldKeepAccessWithoutExpiration": {},
+	}
+
+	setup()
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	for name, test := range cases {
+		if test.expiresAt == "" {
+			mocks.CreateTestRoleBinding(fakeClient, name, namespaceName, testutils.ViewerKey)
+		} else {
+			mocks.CreateTestExpiringRoleBinding(fakeClient, name, namespaceName, testutils.ViewerKey, test.expiresAt)
+		}
+	}
+
+	reaper, err := newAccessReaperWithClock(fakeClient, time.Minute, testingclock.NewFakeClock(now), logger, DefaultRoleCatalog())
+	assert.NoError(t, err)
+	assert.NoError(t, reaper.Reap(context.TODO()))
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(context.TODO(), name, metav1.GetOptions{})
+			assert.Equal(t, test.removed, errors.IsNotFound(err))
+		})
+	}
+}
+
+func TestAccessReaperRun(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-accessReaperRun"
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	expiredName := testutils.TestName + "-expired"
+	expiringName := testutils.TestName + "-expiring"
+
+	setup()
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	mocks.CreateTestExpiringRoleBinding(fakeClient, expiredName, namespaceName, testutils.ViewerKey, now.Format(time.RFC3339))
+	mocks.CreateTestExpiringRoleBinding(fakeClient, expiringName, namespaceName, testutils.ViewerKey, now.Add(90*time.Second).Format(time.RFC3339))
+
+	fakeClock := testingclock.NewFakeClock(now)
+	reaper, err := newAccessReaperWithClock(fakeClient, time.Minute, fakeClock, logger, DefaultRoleCatalog())
+	assert.NoError(t, err)
+	reaper.leaseNamespace = namespaceName
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	isRemoved := func(name string) func() bool {
+		return func() bool {
+			_, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(context.TODO(), name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}
+	}
+	assert.Eventually(t, isRemoved(expiredName), 5*time.Second, 10*time.Millisecond)
+	assert.False(t, isRemoved(expiringName)())
+
+	lease, err := fakeClient.CoordinationV1().Leases(namespaceName).Get(context.TODO(), accessReaperLeaseName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, reaper.identity, *lease.Spec.HolderIdentity)
+
+	// The expiring access is removed by the second run after it expires.
+	assert.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+	fakeClock.Step(time.Minute)
+	assert.Never(t, isRemoved(expiringName), 100*time.Millisecond, 10*time.Millisecond)
+	fakeClock.Step(time.Minute)
+	assert.Eventually(t, isRemoved(expiringName), 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -20,11 +20,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
+	enabledState  = "enabled"
 	disabledState = "disabled"
 	noRevision    = "No revision available"
 	listLimit     = 10
@@ -37,6 +39,8 @@ const (
 	ErrCouldNotGetDNS                = "Could not get dns related to capp %q in namespace %q"
 	ErrCouldNotUpdateCapp            = "Could not get capp %q in namespace %q"
 	ErrCouldNotDeleteCapp            = "Could not delete capp %q in namespace %q"
+	ErrCouldNotRollbackCapp          = "Could not roll back capp %q in namespace %q to revision %q"
+	ErrCappNotPlaced                 = "Capp %q in namespace %q is not placed on a cluster yet, so it has no revisions"
 	ErrParsingLabelSelector          = "Could not parse labelSelector"
 	ErrCouldNotGetPlacements         = "Could not get Placements with %q=%q and %q=%q"
 	ErrNoPlacementsFound             = "No matching Placements found"
@@ -44,7 +48,8 @@ const (
 )
 
 type CappController interface {
-	// CreateCapp creates a new Capp in the specified namespace.
+	// CreateCapp creates a new Capp in the specified namespace. If the query is a dry run, the Capp is
+	// validated and returned without being created.
 	CreateCapp(namespace string, capp types.CreateCapp, cappQuery types.CreateCappQuery) (types.Capp, error)
 
 	// GetCapps gets all Capps from a specific namespace.
@@ -53,14 +58,26 @@ type CappController interface {
 	// GetCapp gets a specific Capp from the specified namespace.
 	GetCapp(namespace, name string) (types.Capp, error)
 
-	// UpdateCapp updates a specific Capp in the specified namespace.
-	UpdateCapp(namespace, name string, capp types.UpdateCapp) (types.Capp, error)
+	// UpdateCapp updates a specific Capp in the specified namespace. If resourceVersion is set, the Capp
+	// is only updated if it has that resource version. If dryRun is set, the updated Capp is validated
+	// and returned without being stored.
+	UpdateCapp(namespace, name string, capp types.UpdateCapp, resourceVersion string, dryRun bool) (types.Capp, error)
+
+	// PatchCapp applies a JSON merge patch or a JSON patch to a specific Capp in the specified namespace.
+	// If resourceVersion is set, the Capp is only patched if it has that resource version. If dryRun is set,
+	// the patched Capp is validated and returned without being stored.
+	PatchCapp(namespace, name string, patchType k8stypes.PatchType, patch []byte, resourceVersion string, dryRun bool) (types.Capp, error)
+
+	// RollbackCapp restores the spec, labels and annotations of a specific Capp in the specified namespace
+	// from one of its CappRevisions, which is read from the managed cluster the Capp is placed on.
+	RollbackCapp(namespace, name, revisionName string, rollback types.CappRollback) (types.CappRollbackResponse, error)
 
 	// DeleteCapp deletes a specific Capp in the specified namespace.
 	DeleteCapp(namespace, name string) (types.MessageResponse, error)
 
-	// EditCappState edits the state of a specific Capp in the specified namespace.
-	EditCappState(namespace string, cappName string, state string) (types.CappStateResponse, error)
+	// EditCappState edits the state of a specific Capp in the specified namespace. If resourceVersion is set,
+	// the state is only edited if the Capp has that resource version.
+	EditCappState(namespace string, cappName string, state string, resourceVersion string) (types.CappStateResponse, error)
 
 	// GetCappState gets the state of a specific Capp from the specified namespace.
 	GetCappState(namespace, name string) (types.GetCappStateResponse, error)
@@ -112,7 +129,13 @@ func (c *cappController) CreateCapp(namespace string, capp types.CreateCapp, cap
 	}
 
 	newCapp := createCappFromType(namespace, placement, capp)
-	if err := c.client.Create(c.ctx, &newCapp); err != nil {
+	if cappQuery.DryRun {
+		if err := c.checkReferencedSecrets(newCapp); err != nil {
+			return types.Capp{}, err
+		}
+	}
+
+	if err := c.client.Create(c.ctx, &newCapp, &client.CreateOptions{DryRun: dryRunValue(cappQuery.DryRun)}); err != nil {
 		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotCreateCapp, capp.Metadata.Name, namespace), err.Error()))
 		return types.Capp{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateCapp, capp.Metadata.Name, namespace), err)
 	}
@@ -178,8 +201,9 @@ func preparePlacementListOptions(environment, region string) *client.ListOptions
 func createCappFromV1Capp(capp cappv1alpha1.Capp) types.Capp {
 	return types.Capp{
 		Metadata: types.Metadata{
-			Name:      capp.Name,
-			Namespace: capp.Namespace,
+			Name:            capp.Name,
+			Namespace:       capp.Namespace,
+			ResourceVersion: capp.ResourceVersion,
 		},
 		Annotations: utils.ConvertMapToKeyValue(capp.Annotations),
 		Labels:      utils.ConvertMapToKeyValue(capp.Labels),
@@ -273,6 +297,7 @@ func (c *cappController) GetCappState(namespace, name string) (types.GetCappStat
 			State:               capp.Status.StateStatus.State,
 		}
 	}
+	cappState.ResourceVersion = capp.ResourceVersion
 
 	return cappState, nil
 }
@@ -339,7 +364,7 @@ func prepareDNSListOptions(namespace, name string) *client.ListOptions {
 	return listOptions
 }
 
-func (c *cappController) UpdateCapp(namespace, name string, newCapp types.UpdateCapp) (types.Capp, error) {
+func (c *cappController) UpdateCapp(namespace, name string, newCapp types.UpdateCapp, resourceVersion string, dryRun bool) (types.Capp, error) {
 	c.logger.Debug(fmt.Sprintf("Trying to update capp %q in namespace %q", name, namespace))
 
 	capp := &cappv1alpha1.Capp{}
@@ -349,19 +374,77 @@ func (c *cappController) UpdateCapp(namespace, name string, newCapp types.Update
 		return types.Capp{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetCapp, name, namespace), err)
 	}
 
+	if err := checkResourceVersion(name, capp.ResourceVersion, resourceVersion); err != nil {
+		return types.Capp{}, err
+	}
+
 	capp.Annotations = utils.ConvertKeyValueToMap(newCapp.Annotations)
 	capp.Labels = utils.ConvertKeyValueToMap(newCapp.Labels)
 	capp.Spec = newCapp.Spec
 
-	if err := c.client.Update(c.ctx, capp); err != nil {
+	if dryRun {
+		if err := c.checkReferencedSecrets(*capp); err != nil {
+			return types.Capp{}, err
+		}
+	}
+
+	if err := c.client.Update(c.ctx, capp, &client.UpdateOptions{DryRun: dryRunValue(dryRun)}); err != nil {
 		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotUpdateCapp, name, namespace), err.Error()))
+		if conflictErr := checkUpdateConflict(name, resourceVersion, err); conflictErr != nil {
+			return types.Capp{}, conflictErr
+		}
 		return types.Capp{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotUpdateCapp, name, namespace), err)
 	}
 
 	return convertCappToType(*capp), nil
 }
 
-func (c *cappController) EditCappState(namespace string, cappName string, state string) (types.CappStateResponse, error) {
+// RollbackCapp restores the spec, labels and annotations of the Capp from the template of the CappRevision.
+// The state of the Capp is kept, and so is its Site unless the rollback sets it.
+func (c *cappController) RollbackCapp(namespace, name, revisionName string, rollback types.CappRollback) (types.CappRollbackResponse, error) {
+	c.logger.Debug(fmt.Sprintf("Trying to roll back capp %q in namespace %q to revision %q", name, namespace, revisionName))
+
+	capp := &cappv1alpha1.Capp{}
+	err := c.client.Get(c.ctx, client.ObjectKey{Namespace: namespace, Name: name}, capp)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetCapp, name, namespace), err.Error()))
+		return types.CappRollbackResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetCapp, name, namespace), err)
+	}
+
+	// The CappRevisions are kept in the managed cluster the Capp is placed on, while the Capp is updated in the hub.
+	revisionContext, err := managedClusterContext(c.ctx, capp)
+	if err != nil {
+		return types.CappRollbackResponse{}, err
+	}
+	revision, err := NewCappRevisionController(c.client, revisionContext, c.logger).GetCappRevisionOfCapp(namespace, name, revisionName)
+	if err != nil {
+		return types.CappRollbackResponse{}, err
+	}
+
+	template := revision.Spec.CappTemplate
+	site := capp.Spec.Site
+	if rollback.RestoreSite {
+		site = template.Spec.Site
+	} else if !isSiteUnset(rollback.Site) {
+		site = rollback.Site
+	}
+
+	state := capp.Spec.State
+	capp.Spec = *template.Spec.DeepCopy()
+	capp.Spec.Site = site
+	capp.Spec.State = state
+	capp.Labels = template.Labels
+	capp.Annotations = template.Annotations
+
+	if err := c.client.Update(c.ctx, capp); err != nil {
+		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotRollbackCapp, name, namespace, revisionName), err.Error()))
+		return types.CappRollbackResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotRollbackCapp, name, namespace, revisionName), err)
+	}
+
+	return types.CappRollbackResponse{Capp: convertCappToType(*capp), Revision: revision}, nil
+}
+
+func (c *cappController) EditCappState(namespace string, cappName string, state string, resourceVersion string) (types.CappStateResponse, error) {
 	c.logger.Debug(fmt.Sprintf("Trying to update capp %q in namespace %q", cappName, namespace))
 
 	capp := &cappv1alpha1.Capp{}
@@ -371,13 +454,20 @@ func (c *cappController) EditCappState(namespace string, cappName string, state
 		return types.CappStateResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetCapp, cappName, namespace), err)
 	}
 
+	if err := checkResourceVersion(cappName, capp.ResourceVersion, resourceVersion); err != nil {
+		return types.CappStateResponse{}, err
+	}
+
 	capp.Spec.State = state
 	if err := c.client.Update(c.ctx, capp); err != nil {
 		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotUpdateCapp, cappName, namespace), err.Error()))
+		if conflictErr := checkUpdateConflict(cappName, resourceVersion, err); conflictErr != nil {
+			return types.CappStateResponse{}, conflictErr
+		}
 		return types.CappStateResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotUpdateCapp, cappName, namespace), err)
 	}
 
-	return types.CappStateResponse{Name: capp.Name, State: capp.Spec.State}, nil
+	return types.CappStateResponse{Name: capp.Name, State: capp.Spec.State, ResourceVersion: capp.ResourceVersion}, nil
 }
 
 func (c *cappController) DeleteCapp(namespace, name string) (types.MessageResponse, error) {
@@ -445,8 +535,9 @@ func createCappFromType(namespace, placement string, capp types.CreateCapp) capp
 func convertCappToType(capp cappv1alpha1.Capp) types.Capp {
 	return types.Capp{
 		Metadata: types.Metadata{
-			Name:      capp.Name,
-			Namespace: capp.Namespace,
+			Name:            capp.Name,
+			Namespace:       capp.Namespace,
+			ResourceVersion: capp.ResourceVersion,
 		},
 		Annotations: utils.ConvertMapToKeyValue(capp.Annotations),
 		Labels:      utils.ConvertMapToKeyValue(capp.Labels),
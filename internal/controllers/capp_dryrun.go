@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ErrMissingReferencedSecrets = "Capp %q references secrets which do not exist in namespace %q: %s"
+)
+
+// dryRunValue returns the dry run option of requests to the cluster, which are validated without being persisted
+// if dryRun is set.
+func dryRunValue(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+
+	return nil
+}
+
+// checkReferencedSecrets makes sure the Secrets which the Capp requires exist in its namespace. It is only checked by
+// dry runs, since a Capp may be created before its Secrets, which it waits for.
+func (c *cappController) checkReferencedSecrets(capp cappv1alpha1.Capp) error {
+	var missing []string
+	for _, name := range getReferencedSecrets(capp) {
+		err := c.client.Get(c.ctx, client.ObjectKey{Namespace: capp.Namespace, Name: name}, &corev1.Secret{})
+		if errors.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetSecret, name), err.Error()))
+			return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetSecret, name), err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return customerrors.NewValidationError(fmt.Sprintf(ErrMissingReferencedSecrets, capp.Name, capp.Namespace, strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
+
+// getReferencedSecrets returns the sorted names of the Secrets which the Capp requires: those of its
+// environment variables, volumes, image pull secrets and log shipping password. Optional references are left out.
+func getReferencedSecrets(capp cappv1alpha1.Capp) []string {
+	var names []string
+	podSpec := capp.Spec.ConfigurationSpec.Template.Spec.PodSpec
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+		for _, env := range container.Env {
+			if ref := env.ValueFrom; ref != nil && ref.SecretKeyRef != nil && !isOptional(ref.SecretKeyRef.Optional) {
+				names = append(names, ref.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.SecretRef; ref != nil && !isOptional(ref.Optional) {
+				names = append(names, ref.Name)
+			}
+		}
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if secret := volume.Secret; secret != nil && !isOptional(secret.Optional) {
+			names = append(names, secret.SecretName)
+		}
+	}
+
+	for _, imagePullSecret := range podSpec.ImagePullSecrets {
+		names = append(names, imagePullSecret.Name)
+	}
+
+	if capp.Spec.LogSpec.PasswordSecret != "" {
+		names = append(names, capp.Spec.LogSpec.PasswordSecret)
+	}
+
+	slices.Sort(names)
+	return slices.DeleteFunc(slices.Compact(names), func(name string) bool { return name == "" })
+}
+
+// isOptional returns true if an optional reference is set to be optional.
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// prepareCappSpecWithSecretEnv returns a Capp spec whose container takes an environment variable from the given Secret.
+func prepareCappSpecWithSecretEnv(secret string) cappv1alpha1.CappSpec {
+	spec := mocks.PrepareCappSpec(testutils.SiteName)
+	spec.ConfigurationSpec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: secretEnvName, ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secret}, Key: "password"},
+	}}}
+
+	return spec
+}
+
+func TestGetReferencedSecrets(t *testing.T) {
+	secretRef := func(name string, optional *bool) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "password", Optional: optional}
+	}
+
+	cases := map[string]struct {
+		podSpec        corev1.PodSpec
+		passwordSecret string
+		want           []string
+	}{
+		"ShouldFindNoSecrets": {
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{{Name: testutils.ContainerName}}},
+		},
+		"ShouldFindSecretsOfAllContainers": {
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", EnvFrom: []corev1.EnvFromSource{{
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "init"}},
+				}}}},
+				Containers: []corev1.Container{{Name: testutils.ContainerName, Env: []corev1.EnvVar{
+					{Name: secretEnvName, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: secretRef(secretName, nil)}},
+					{Name: "MODE", Value: "debug"},
+				}}},
+			},
+			want: []string{secretName, "init"},
+		},
+		"ShouldFindSecretsOfVolumesAndImagePullSecrets": {
+			podSpec: corev1.PodSpec{
+				Containers:       []corev1.Container{{Name: testutils.ContainerName}},
+				Volumes:          []corev1.Volume{{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+			},
+			want: []string{"certs", "registry"},
+		},
+		"ShouldFindLogPasswordSecret": {
+			podSpec:        corev1.PodSpec{Containers: []corev1.Container{{Name: testutils.ContainerName}}},
+			passwordSecret: "elastic",
+			want:           []string{"elastic"},
+		},
+		"ShouldSkipOptionalSecrets": {
+			podSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: testutils.ContainerName, Env: []corev1.EnvVar{
+					{Name: secretEnvName, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: secretRef(secretName, ptr.To(true))}},
+				}}},
+				Volumes: []corev1.Volume{{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs", Optional: ptr.To(true)}}}},
+			},
+		},
+		"ShouldFindEachSecretOnce": {
+			podSpec: corev1.PodSpec{Containers: []corev1.Container{{Name: testutils.ContainerName, Env: []corev1.EnvVar{
+				{Name: secretEnvName, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: secretRef(secretName, nil)}},
+				{Name: "DB_USER", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: secretRef(secretName, ptr.To(false))}},
+			}}}},
+			want: []string{secretName},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			capp := cappv1alpha1.Capp{}
+			capp.Spec.ConfigurationSpec.Template.Spec.PodSpec = test.podSpec
+			capp.Spec.LogSpec.PasswordSecret = test.passwordSecret
+			assert.Equal(t, test.want, getReferencedSecrets(capp))
+		})
+	}
+}
+
+func TestCappDryRun(t *testing.T) {
+	namespaceName := testutils.CappNamespace + "-dryRun"
+	missingSecretName := secretName + testutils.NonExistentSuffix
+
+	updateCapp := func(spec cappv1alpha1.CappSpec) types.UpdateCapp {
+		update := mocks.PrepareUpdateCappType(testutils.SiteName, nil, nil)
+		update.Spec = spec
+		return update
+	}
+
+	cases := map[string]struct {
+		run         func(controller CappController) (types.Capp, error)
+		name        string
+		errorStatus metav1.StatusReason
+	}{
+		"ShouldSucceedDryRunCreatingCapp": {
+			run: func(controller CappController) (types.Capp, error) {
+				capp := mocks.PrepareCreateCappType(testutils.CappName+"-2", testutils.SiteName, nil, nil)
+				capp.Spec = prepareCappSpecWithSecretEnv(secretName)
+				return controller.CreateCapp(namespaceName, capp, types.CreateCappQuery{DryRunQuery: types.DryRunQuery{DryRun: true}})
+			},
+			name:        testutils.CappName + "-2",
+			errorStatus: metav1.StatusSuccess,
+		},
+		"ShouldFailDryRunCreatingCappWithMissingSecret": {
+			run: func(controller CappController) (types.Capp, error) {
+				capp := mocks.PrepareCreateCappType(testutils.CappName+"-2", testutils.SiteName, nil, nil)
+				capp.Spec = prepareCappSpecWithSecretEnv(missingSecretName)
+				return controller.CreateCapp(namespaceName, capp, types.CreateCappQuery{DryRunQuery: types.DryRunQuery{DryRun: true}})
+			},
+			name:        testutils.CappName + "-2",
+			errorStatus: metav1.StatusReasonBadRequest,
+		},
+		"ShouldSucceedDryRunUpdatingCapp": {
+			run: func(controller CappController) (types.Capp, error) {
+				return controller.UpdateCapp(namespaceName, testutils.CappName, updateCapp(prepareCappSpecWithSecretEnv(secretName)), "", true)
+			},
+			name:        testutils.CappName,
+			errorStatus: metav1.StatusSuccess,
+		},
+		"ShouldFailDryRunUpdatingCappWithMissingSecret": {
+			run: func(controller CappController) (types.Capp, error) {
+				return controller.UpdateCapp(namespaceName, testutils.CappName, updateCapp(prepareCappSpecWithSecretEnv(missingSecretName)), "", true)
+			},
+			name:        testutils.CappName,
+			errorStatus: metav1.StatusReasonBadRequest,
+		},
+		"ShouldSucceedDryRunPatchingCapp": {
+			run: func(controller CappController) (types.Capp, error) {
+				patch := []byte(`[{"op": "add", "path": "/spec/configurationSpec/template/spec/containers/0/env", "value": [{"name": "` +
+					secretEnvName + `", "valueFrom": {"secretKeyRef": {"name": "` + secretName + `", "key": "password"}}}]}]`)
+				return controller.PatchCapp(namespaceName, testutils.CappName, k8stypes.JSONPatchType, patch, "", true)
+			},
+			name:        testutils.CappName,
+			errorStatus: metav1.StatusSuccess,
+		},
+		"ShouldFailDryRunPatchingCappWithMissingSecret": {
+			run: func(controller CappController) (types.Capp, error) {
+				patch := []byte(`{"spec": {"logSpec": {"passwordSecret": "` + missingSecretName + `"}}}`)
+				return controller.PatchCapp(namespaceName, testutils.CappName, k8stypes.MergePatchType, patch, "", true)
+			},
+			name:        testutils.CappName,
+			errorStatus: metav1.StatusReasonBadRequest,
+		},
+	}
+
+	setup()
+	cappController := NewCappController(dynClient, mocks.GinContext(), logger)
+	createTestNamespace(namespaceName, map[string]string{})
+	mocks.CreateTestCapp(dynClient, testutils.CappName, namespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	secret := mocks.PrepareSecret(secretName, namespaceName, testutils.SecretDataKey, testutils.SecretDataValue)
+	assert.NoError(t, dynClient.Create(mocks.GinContext(), &secret))
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			response, err := test.run(cappController)
+			if test.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.errorStatus, reason)
+				assert.Equal(t, types.Capp{}, response)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.name, response.Metadata.Name)
+				assert.Equal(t, prepareCappSpecWithSecretEnv(secretName).ConfigurationSpec.Template.Spec.Containers[0].Env,
+					response.Spec.ConfigurationSpec.Template.Spec.Containers[0].Env)
+			}
+
+			capp := cappv1alpha1.Capp{}
+			err = dynClient.Get(mocks.GinContext(), client.ObjectKey{Namespace: namespaceName, Name: test.name}, &capp)
+			if test.name != testutils.CappName {
+				assert.True(t, errors.IsNotFound(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, mocks.PrepareCappSpec(testutils.SiteName), capp.Spec)
+		})
+	}
+}
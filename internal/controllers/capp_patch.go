@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ErrCouldNotPatchCapp    = "Could not patch capp %q in namespace %q"
+	ErrInvalidCappPatch     = "Invalid patch of capp %q in namespace %q: %v"
+	ErrUnsupportedPatchType = "Unsupported patch type %q, must be one of: %s, %s"
+)
+
+// PatchCapp applies the patch to the labels, annotations and spec of the Capp, validates the result and updates the Capp.
+// JSON merge patches and JSON patches are applied to a types.PatchCapp document.
+func (c *cappController) PatchCapp(namespace, name string, patchType k8stypes.PatchType, patch []byte, resourceVersion string, dryRun bool) (types.Capp, error) {
+	c.logger.Debug(fmt.Sprintf("Trying to patch capp %q in namespace %q", name, namespace))
+
+	capp := &cappv1alpha1.Capp{}
+	err := c.client.Get(c.ctx, client.ObjectKey{Namespace: namespace, Name: name}, capp)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetCapp, name, namespace), err.Error()))
+		return types.Capp{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetCapp, name, namespace), err)
+	}
+
+	if err := checkResourceVersion(name, capp.ResourceVersion, resourceVersion); err != nil {
+		return types.Capp{}, err
+	}
+
+	patched, err := applyCappPatch(*capp, patchType, patch)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf(ErrInvalidCappPatch, name, namespace, err))
+		return types.Capp{}, customerrors.NewValidationError(fmt.Sprintf(ErrInvalidCappPatch, name, namespace, err))
+	}
+
+	capp.Annotations = patched.Annotations
+	capp.Labels = patched.Labels
+	capp.Spec = patched.Spec
+
+	if dryRun {
+		if err := c.checkReferencedSecrets(*capp); err != nil {
+			return types.Capp{}, err
+		}
+	}
+
+	if err := c.client.Update(c.ctx, capp, &client.UpdateOptions{DryRun: dryRunValue(dryRun)}); err != nil {
+		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotPatchCapp, name, namespace), err.Error()))
+		if conflictErr := checkUpdateConflict(name, resourceVersion, err); conflictErr != nil {
+			return types.Capp{}, conflictErr
+		}
+		return types.Capp{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotPatchCapp, name, namespace), err)
+	}
+
+	return convertCappToType(*capp), nil
+}
+
+// applyCappPatch returns the labels, annotations and spec of the Capp with the patch applied to them.
+// Fields which are not part of a types.PatchCapp, and invalid labels, annotations and states, are rejected.
+func applyCappPatch(capp cappv1alpha1.Capp, patchType k8stypes.PatchType, patch []byte) (types.PatchCapp, error) {
+	document, err := json.Marshal(types.PatchCapp{
+		Annotations: nonNilMap(capp.Annotations),
+		Labels:      nonNilMap(capp.Labels),
+		Spec:        capp.Spec,
+	})
+	if err != nil {
+		return types.PatchCapp{}, err
+	}
+
+	var patchedDocument []byte
+	switch patchType {
+	case k8stypes.MergePatchType:
+		patchedDocument, err = jsonpatch.MergePatch(document, patch)
+	case k8stypes.JSONPatchType:
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(patch); err == nil {
+			patchedDocument, err = operations.Apply(document)
+		}
+	default:
+		err = fmt.Errorf(ErrUnsupportedPatchType, patchType, k8stypes.MergePatchType, k8stypes.JSONPatchType)
+	}
+	if err != nil {
+		return types.PatchCapp{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patchedDocument))
+	decoder.DisallowUnknownFields()
+
+	var patched types.PatchCapp
+	if err := decoder.Decode(&patched); err != nil {
+		return types.PatchCapp{}, err
+	}
+
+	return patched, validateCappPatch(patched)
+}
+
+// validateCappPatch validates the labels, annotations and state of a patched Capp. The rest of its spec
+// is validated by the cluster.
+func validateCappPatch(patched types.PatchCapp) error {
+	errs := metav1validation.ValidateLabels(patched.Labels, field.NewPath("labels"))
+	errs = append(errs, apivalidation.ValidateAnnotations(patched.Annotations, field.NewPath("annotations"))...)
+
+	if state := patched.Spec.State; state != "" && state != enabledState && state != disabledState {
+		errs = append(errs, field.NotSupported(field.NewPath("spec", "state"), state, []string{enabledState, disabledState}))
+	}
+
+	return errs.ToAggregate()
+}
+
+// nonNilMap returns the map, or an empty map if it is nil, so that JSON patches can add keys to it.
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+
+	return m
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestPatchCapp(t *testing.T) {
+	namespaceName := testutils.CappNamespace + "-patch"
+	existingLabel := types.KeyValue{Key: testutils.LabelKey + "-1", Value: testutils.LabelValue + "-1"}
+
+	type requestParams struct {
+		name            string
+		patchType       k8stypes.PatchType
+		patch           string
+		resourceVersion string
+	}
+
+	type want struct {
+		labels      []types.KeyValue
+		image       string
+		state       string
+		errorStatus metav1.StatusReason
+	}
+
+	cases := map[string]struct {
+		requestParams requestParams
+		want          want
+	}{
+		"ShouldSucceedMergePatchingLabel": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-1",
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"labels": {"` + testutils.LabelKey + `-2": "` + testutils.LabelValue + `-2"}}`,
+			},
+			want: want{
+				labels:      []types.KeyValue{existingLabel, {Key: testutils.LabelKey + "-2", Value: testutils.LabelValue + "-2"}},
+				image:       testutils.CappImage,
+				state:       enabledState,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedMergePatchingRemovedLabel": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-2",
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"labels": {"` + existingLabel.Key + `": null}}`,
+			},
+			want: want{
+				labels:      []types.KeyValue{},
+				image:       testutils.CappImage,
+				state:       enabledState,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedJSONPatchingImageAndState": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-3",
+				patchType: k8stypes.JSONPatchType,
+				patch: `[{"op": "replace", "path": "/spec/configurationSpec/template/spec/containers/0/image", "value": "` + testutils.PreviousCappImage + `"},
+					{"op": "replace", "path": "/spec/state", "value": "` + disabledState + `"}]`,
+			},
+			want: want{
+				labels:      []types.KeyValue{existingLabel},
+				image:       testutils.PreviousCappImage,
+				state:       disabledState,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailJSONPatchingWithFailedTest": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.JSONPatchType,
+				patch:     `[{"op": "test", "path": "/spec/state", "value": "` + disabledState + `"}]`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingUnknownField": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"metadata": {"name": "` + testutils.CappName + `"}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingInvalidLabel": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"labels": {"invalid key": "` + testutils.LabelValue + `"}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingInvalidState": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"spec": {"state": "paused"}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingWithMalformedPatch": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.JSONPatchType,
+				patch:     `{"labels": {}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingWithUnsupportedPatchType": {
+			requestParams: requestParams{
+				name:      testutils.CappName + "-4",
+				patchType: k8stypes.StrategicMergePatchType,
+				patch:     `{"labels": {}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonBadRequest},
+		},
+		"ShouldFailPatchingCappChangedWhilePatchingWithResourceVersion": {
+			requestParams: requestParams{
+				name:            testutils.CappName + "-conflict",
+				patchType:       k8stypes.MergePatchType,
+				patch:           `{"labels": {}}`,
+				resourceVersion: testutils.CreatedResourceVersion,
+			},
+			want: want{errorStatus: customerrors.StatusReasonPreconditionFailed},
+		},
+		"ShouldFailPatchingNonExistingCapp": {
+			requestParams: requestParams{
+				name:      testutils.CappName + testutils.NonExistentSuffix,
+				patchType: k8stypes.MergePatchType,
+				patch:     `{"labels": {}}`,
+			},
+			want: want{errorStatus: metav1.StatusReasonNotFound},
+		},
+	}
+
+	setup()
+	cappController := NewCappController(conflictingClient(testutils.CappName+"-conflict"), mocks.GinContext(), logger)
+	createTestNamespace(namespaceName, map[string]string{})
+	for i := 1; i <= 4; i++ {
+		mocks.CreateTestCapp(dynClient, testutils.CappName+"-"+strconv.Itoa(i), namespaceName, testutils.Domain, testutils.SiteName,
+			map[string]string{existingLabel.Key: existingLabel.Value}, nil)
+	}
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-conflict", namespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			response, err := cappController.PatchCapp(namespaceName, test.requestParams.name, test.requestParams.patchType, []byte(test.requestParams.patch), test.requestParams.resourceVersion, false)
+			if test.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.want.errorStatus, reason)
+				assert.Equal(t, types.Capp{}, response)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, test.want.labels, response.Labels)
+			assert.Equal(t, test.want.image, response.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, test.want.state, response.Spec.State)
+		})
+	}
+}
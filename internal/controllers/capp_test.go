@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
@@ -8,12 +9,16 @@ import (
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/pagination"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	multicluster "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/transport"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func TestGetCapp(t *testing.T) {
@@ -40,7 +45,7 @@ func TestGetCapp(t *testing.T) {
 			},
 			want: want{
 				capp: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-1", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-1", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.SiteName),
 					Status:   mocks.PrepareCappStatus(testutils.CappName+"-1", namespaceName, testutils.Domain),
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-1", Value: testutils.LabelValue + "-1"}},
@@ -114,6 +119,7 @@ func TestGetCappState(t *testing.T) {
 					LastCreatedRevision: fmt.Sprintf("%s-%s-%s", testutils.CappName, testutils.EnabledState, "00001"),
 					LastReadyRevision:   fmt.Sprintf("%s-%s-%s", testutils.CappName, testutils.EnabledState, "00001"),
 					State:               testutils.EnabledState,
+					ResourceVersion:     testutils.CreatedResourceVersion,
 				},
 				errorStatus: metav1.StatusSuccess,
 			},
@@ -128,6 +134,7 @@ func TestGetCappState(t *testing.T) {
 					LastCreatedRevision: testutils.NoRevision,
 					LastReadyRevision:   testutils.NoRevision,
 					State:               testutils.DisabledState,
+					ResourceVersion:     testutils.CreatedResourceVersion,
 				},
 				errorStatus: metav1.StatusSuccess,
 			},
@@ -453,7 +460,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-2", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-2", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.SiteName),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-2", Value: testutils.LabelValue + "-2"}},
@@ -469,7 +476,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-3", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-3", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.PlacementName + "-1"),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-3", Value: testutils.LabelValue + "-3"}},
@@ -485,7 +492,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-4", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-4", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.PlacementName + "-2"),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-4", Value: testutils.LabelValue + "-4"}},
@@ -501,7 +508,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-5", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-5", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.PlacementName + "-3"),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-5", Value: testutils.LabelValue + "-5"}},
@@ -517,7 +524,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-6", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-6", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.PlacementName + "-4"),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-6", Value: testutils.LabelValue + "-6"}},
@@ -533,7 +540,7 @@ func TestCreateCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-7", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-7", namespaceName, testutils.CreatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.SiteName),
 					Status:   cappv1alpha1.CappStatus{},
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-7", Value: testutils.LabelValue + "-7"}},
@@ -602,9 +609,10 @@ func TestCreateCapp(t *testing.T) {
 func TestUpdateCapp(t *testing.T) {
 	namespaceName := testutils.CappNamespace + "-update"
 	type requestParams struct {
-		name      string
-		capp      types.UpdateCapp
-		namespace string
+		name            string
+		capp            types.UpdateCapp
+		namespace       string
+		resourceVersion string
 	}
 
 	type want struct {
@@ -617,13 +625,14 @@ func TestUpdateCapp(t *testing.T) {
 	}{
 		"ShouldSucceedUpdatingCapp": {
 			requestParams: requestParams{
-				namespace: namespaceName,
-				name:      testutils.CappName + "-1",
-				capp:      mocks.PrepareUpdateCappType(testutils.SiteName, []types.KeyValue{{Key: testutils.LabelKey + "-3", Value: testutils.LabelValue + "-3"}}, nil),
+				namespace:       namespaceName,
+				name:            testutils.CappName + "-1",
+				capp:            mocks.PrepareUpdateCappType(testutils.SiteName, []types.KeyValue{{Key: testutils.LabelKey + "-3", Value: testutils.LabelValue + "-3"}}, nil),
+				resourceVersion: testutils.CreatedResourceVersion,
 			},
 			want: want{
 				response: types.Capp{
-					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-1", namespaceName),
+					Metadata: mocks.PrepareCappMetadata(testutils.CappName+"-1", namespaceName, testutils.UpdatedResourceVersion),
 					Spec:     mocks.PrepareCappSpec(testutils.SiteName),
 					Status:   mocks.PrepareCappStatus(testutils.CappName+"-1", namespaceName, testutils.Domain),
 					Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-3", Value: testutils.LabelValue + "-3"}},
@@ -631,6 +640,41 @@ func TestUpdateCapp(t *testing.T) {
 				errorStatus: metav1.StatusSuccess,
 			},
 		},
+		"ShouldFailUpdatingCappWithStaleResourceVersion": {
+			requestParams: requestParams{
+				namespace:       namespaceName,
+				name:            testutils.CappName + "-1",
+				capp:            mocks.PrepareUpdateCappType(testutils.SiteName, nil, nil),
+				resourceVersion: testutils.StaleResourceVersion,
+			},
+			want: want{
+				response:    types.Capp{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
+		"ShouldFailUpdatingCappChangedWhileUpdatingWithResourceVersion": {
+			requestParams: requestParams{
+				namespace:       namespaceName,
+				name:            testutils.CappName + "-conflict",
+				capp:            mocks.PrepareUpdateCappType(testutils.SiteName, nil, nil),
+				resourceVersion: testutils.CreatedResourceVersion,
+			},
+			want: want{
+				response:    types.Capp{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
+		"ShouldFailUpdatingCappChangedWhileUpdating": {
+			requestParams: requestParams{
+				namespace: namespaceName,
+				name:      testutils.CappName + "-conflict",
+				capp:      mocks.PrepareUpdateCappType(testutils.SiteName, nil, nil),
+			},
+			want: want{
+				response:    types.Capp{},
+				errorStatus: metav1.StatusReasonConflict,
+			},
+		},
 		"ShouldFaildUpdatingNonExistingCapp": {
 			requestParams: requestParams{
 				namespace: namespaceName,
@@ -644,13 +688,14 @@ func TestUpdateCapp(t *testing.T) {
 		},
 	}
 	setup()
-	cappController := NewCappController(dynClient, mocks.GinContext(), logger)
+	cappController := NewCappController(conflictingClient(testutils.CappName+"-conflict"), mocks.GinContext(), logger)
 	createTestNamespace(namespaceName, map[string]string{})
 	mocks.CreateTestCapp(dynClient, testutils.CappName+"-1", namespaceName, testutils.Domain, testutils.SiteName, map[string]string{testutils.LabelKey + "-1": testutils.LabelValue + "-1"}, map[string]string{})
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-conflict", namespaceName, testutils.Domain, testutils.SiteName, map[string]string{}, map[string]string{})
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			response, err := cappController.UpdateCapp(test.requestParams.namespace, test.requestParams.name, test.requestParams.capp)
+			response, err := cappController.UpdateCapp(test.requestParams.namespace, test.requestParams.name, test.requestParams.capp, test.requestParams.resourceVersion, false)
 			if test.want.errorStatus != metav1.StatusSuccess {
 				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
 
@@ -666,9 +711,10 @@ func TestUpdateCapp(t *testing.T) {
 func TestEditCapp(t *testing.T) {
 	namespaceName := testutils.CappNamespace + "-update"
 	type requestParams struct {
-		name      string
-		state     string
-		namespace string
+		name            string
+		state           string
+		namespace       string
+		resourceVersion string
 	}
 
 	type want struct {
@@ -687,12 +733,37 @@ func TestEditCapp(t *testing.T) {
 			},
 			want: want{
 				response: types.CappStateResponse{
-					State: testutils.DisabledState,
-					Name:  testutils.CappName + "-1",
+					State:           testutils.DisabledState,
+					Name:            testutils.CappName + "-1",
+					ResourceVersion: testutils.UpdatedResourceVersion,
 				},
 				errorStatus: metav1.StatusSuccess,
 			},
 		},
+		"ShouldFailEditingCappStateWithStaleResourceVersion": {
+			requestParams: requestParams{
+				namespace:       namespaceName,
+				name:            testutils.CappName + "-1",
+				state:           testutils.DisabledState,
+				resourceVersion: testutils.StaleResourceVersion,
+			},
+			want: want{
+				response:    types.CappStateResponse{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
+		"ShouldFailEditingStateOfCappChangedWhileUpdatingWithResourceVersion": {
+			requestParams: requestParams{
+				namespace:       namespaceName,
+				name:            testutils.CappName + "-conflict",
+				state:           testutils.DisabledState,
+				resourceVersion: testutils.CreatedResourceVersion,
+			},
+			want: want{
+				response:    types.CappStateResponse{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
 		"ShouldFailedEditingNonExistingCapp": {
 			requestParams: requestParams{
 				namespace: namespaceName,
@@ -706,13 +777,14 @@ func TestEditCapp(t *testing.T) {
 		},
 	}
 	setup()
-	cappController := NewCappController(dynClient, mocks.GinContext(), logger)
+	cappController := NewCappController(conflictingClient(testutils.CappName+"-conflict"), mocks.GinContext(), logger)
 	createTestNamespace(namespaceName, map[string]string{})
 	mocks.CreateTestCapp(dynClient, testutils.CappName+"-1", namespaceName, testutils.Domain, testutils.SiteName, map[string]string{testutils.LabelKey + "-1": testutils.LabelValue + "-1"}, map[string]string{})
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-conflict", namespaceName, testutils.Domain, testutils.SiteName, map[string]string{}, map[string]string{})
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			response, err := cappController.EditCappState(test.requestParams.namespace, test.requestParams.name, test.requestParams.state)
+			response, err := cappController.EditCappState(test.requestParams.namespace, test.requestParams.name, test.requestParams.state, test.requestParams.resourceVersion)
 			if test.want.errorStatus != metav1.StatusSuccess {
 				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
 
@@ -781,3 +853,122 @@ func TestDeleteCapp(t *testing.T) {
 		})
 	}
 }
+
+func TestRollbackCapp(t *testing.T) {
+	namespaceName := testutils.CappNamespace + "-rollback"
+	cappName := testutils.CappName + "-1"
+	revisionName := testutils.CappRevisionName + "-1"
+	revisionLabels := map[string]string{testutils.LabelKey + "-1": testutils.LabelValue + "-1"}
+	revisionAnnotations := map[string]string{testutils.LabelKey + "-annotation": testutils.LabelValue + "-annotation"}
+
+	type requestParams struct {
+		name         string
+		revisionName string
+		rollback     types.CappRollback
+	}
+
+	type want struct {
+		site        string
+		errorStatus metav1.StatusReason
+	}
+
+	cases := map[string]struct {
+		requestParams requestParams
+		want          want
+	}{
+		"ShouldSucceedRollingBackCappKeepingSite": {
+			requestParams: requestParams{name: cappName, revisionName: revisionName},
+			want:          want{site: testutils.SiteName, errorStatus: metav1.StatusSuccess},
+		},
+		"ShouldSucceedRollingBackCappWithSite": {
+			requestParams: requestParams{name: cappName, revisionName: revisionName, rollback: types.CappRollback{Site: testutils.SiteName + "-new"}},
+			want:          want{site: testutils.SiteName + "-new", errorStatus: metav1.StatusSuccess},
+		},
+		"ShouldSucceedRollingBackCappRestoringSite": {
+			requestParams: requestParams{name: cappName, revisionName: revisionName, rollback: types.CappRollback{RestoreSite: true}},
+			want:          want{site: testutils.SiteName + "-previous", errorStatus: metav1.StatusSuccess},
+		},
+		"ShouldFailRollingBackToRevisionOfAnotherCapp": {
+			requestParams: requestParams{name: cappName, revisionName: testutils.CappRevisionName + "-2"},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+		"ShouldFailRollingBackToNonExistingRevision": {
+			requestParams: requestParams{name: cappName, revisionName: testutils.CappRevisionName + testutils.NonExistentSuffix},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+		"ShouldFailRollingBackNonExistingCapp": {
+			requestParams: requestParams{name: testutils.CappName + testutils.NonExistentSuffix, revisionName: testutils.CappRevisionName + "-3"},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+		"ShouldFailRollingBackCappWhichIsNotPlaced": {
+			requestParams: requestParams{name: testutils.CappName + "-unplaced", revisionName: testutils.CappRevisionName + "-4"},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+	}
+
+	setup()
+	// The managed clusters which CappRevisions are read from, and Capps are updated in, are recorded.
+	var revisionClusters, updateClusters []string
+	interceptedClient := interceptor.NewClient(dynClient, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if cluster, ok := multicluster.GetMultiClusterContext(ctx); ok {
+				if _, isRevision := obj.(*cappv1alpha1.CappRevision); isRevision {
+					revisionClusters = append(revisionClusters, cluster)
+				}
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if cluster, ok := multicluster.GetMultiClusterContext(ctx); ok {
+				updateClusters = append(updateClusters, cluster)
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	})
+	cappController := NewCappController(interceptedClient, mocks.GinContext(), logger)
+	createTestNamespace(namespaceName, map[string]string{})
+	capp := mocks.PrepareCappWithState(cappName, namespaceName, testutils.DisabledState, testutils.SiteName, map[string]string{testutils.LabelKey + "-2": testutils.LabelValue + "-2"}, nil)
+	capp.Status.ApplicationLinks.Site = testutils.ManagedClusterName
+	assert.NoError(t, dynClient.Create(context.TODO(), &capp))
+	mocks.CreateTestCappRevisionOfCapp(dynClient, revisionName, namespaceName, cappName, testutils.SiteName+"-previous", testutils.PreviousCappImage, revisionLabels, revisionAnnotations)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-2", namespaceName, testutils.CappName+"-2", testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-3", namespaceName, testutils.CappName+testutils.NonExistentSuffix, testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	unplacedCapp := mocks.PrepareCappWithState(testutils.CappName+"-unplaced", namespaceName, testutils.DisabledState, testutils.SiteName, nil, nil)
+	assert.NoError(t, dynClient.Create(context.TODO(), &unplacedCapp))
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-4", namespaceName, testutils.CappName+"-unplaced", testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			// The Site of the Capp is reset, since it is kept by the rollback.
+			capp := cappv1alpha1.Capp{}
+			assert.NoError(t, dynClient.Get(context.TODO(), client.ObjectKey{Namespace: namespaceName, Name: cappName}, &capp))
+			capp.Spec.Site = testutils.SiteName
+			assert.NoError(t, dynClient.Update(context.TODO(), &capp))
+
+			revisionClusters, updateClusters = nil, nil
+			response, err := cappController.RollbackCapp(namespaceName, test.requestParams.name, test.requestParams.revisionName, test.requestParams.rollback)
+			if test.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.want.errorStatus, reason)
+				assert.Equal(t, types.CappRollbackResponse{}, response)
+				assert.Empty(t, updateClusters)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.requestParams.revisionName, response.Revision.Metadata.Name)
+			assert.Equal(t, utils.ConvertMapToKeyValue(revisionLabels), response.Capp.Labels)
+			assert.Equal(t, utils.ConvertMapToKeyValue(revisionAnnotations), response.Capp.Annotations)
+			assert.Equal(t, testutils.PreviousCappImage, response.Capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, test.want.site, response.Capp.Spec.Site)
+			assert.Equal(t, testutils.DisabledState, response.Capp.Spec.State)
+
+			assert.NoError(t, dynClient.Get(context.TODO(), client.ObjectKey{Namespace: namespaceName, Name: cappName}, &capp))
+			assert.Equal(t, response.Capp.Spec, capp.Spec)
+
+			// The CappRevision is read from the managed cluster of the Capp, while the Capp is updated in the hub.
+			assert.Equal(t, []string{testutils.ManagedClusterName}, revisionClusters)
+			assert.Empty(t, updateClusters)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/dana-team/platform-backend/internal/types"
+	multicluster "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/transport"
 	"go.uber.org/zap"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -18,6 +19,7 @@ import (
 const (
 	ErrCouldNotListCappRevisions = "Could not list capp revisions"
 	ErrCouldNotGetCappRevision   = "Could not get capp revision %q in namespace %q"
+	ErrCappRevisionNotOfCapp     = "Capp revision %q in namespace %q is not a revision of capp %q"
 )
 
 type CappRevisionController interface {
@@ -26,6 +28,13 @@ type CappRevisionController interface {
 
 	// GetCappRevision gets a specific CappRevision from the specified namespace.
 	GetCappRevision(namespace, name string) (types.CappRevision, error)
+
+	// GetCappRevisionOfCapp gets a specific CappRevision of a Capp from the specified namespace.
+	GetCappRevisionOfCapp(namespace, cappName, name string) (types.CappRevision, error)
+
+	// GetCappRevisionDiff gets the changes from a specific CappRevision of a Capp to another of its CappRevisions,
+	// or to the live Capp in the hub.
+	GetCappRevisionDiff(namespace, cappName, name string, query types.CappRevisionDiffQuery) (types.CappRevisionDiff, error)
 }
 
 type cappRevisionController struct {
@@ -62,6 +71,20 @@ func (c *cappRevisionController) GetCappRevision(namespace string, name string)
 	return convertCappRevisionToType(cappRevision), nil
 }
 
+func (c *cappRevisionController) GetCappRevisionOfCapp(namespace, cappName, name string) (types.CappRevision, error) {
+	cappRevision, err := c.GetCappRevision(namespace, name)
+	if err != nil {
+		return types.CappRevision{}, err
+	}
+
+	if utils.ConvertKeyValueToMap(cappRevision.Labels)[utils.CappNameLabel] != cappName {
+		c.logger.Error(fmt.Sprintf(ErrCappRevisionNotOfCapp, name, namespace, cappName))
+		return types.CappRevision{}, customerrors.NewNotFoundError(fmt.Sprintf(ErrCappRevisionNotOfCapp, name, namespace, cappName))
+	}
+
+	return cappRevision, nil
+}
+
 func (c *cappRevisionController) GetCappRevisions(namespace string, limit, page int, cappName string) (types.CappRevisionList, error) {
 	cappQuery := ""
 	c.logger.Debug(fmt.Sprintf("Trying to fetch all capp revisions in namespace: %q", namespace))
@@ -115,12 +138,22 @@ func (p *CappRevisionPaginator) FetchList(listOptions metav1.ListOptions) (*type
 	return (*types.List[cappv1alpha1.CappRevision])(cappRevisionList), nil
 }
 
+// managedClusterContext returns a context targeting the managed cluster the Capp is placed on, which its CappRevisions are kept in.
+func managedClusterContext(ctx context.Context, capp *cappv1alpha1.Capp) (context.Context, error) {
+	if capp.Status.ApplicationLinks.Site == "" {
+		return nil, customerrors.NewNotFoundError(fmt.Sprintf(ErrCappNotPlaced, capp.Name, capp.Namespace))
+	}
+
+	return multicluster.WithMultiClusterContext(ctx, capp.Status.ApplicationLinks.Site), nil
+}
+
 // convertCappRevisionToType converts an API CappRevision to a Type CappRevision.
 func convertCappRevisionToType(cappRevision cappv1alpha1.CappRevision) types.CappRevision {
 	return types.CappRevision{
 		Metadata: types.Metadata{
-			Name:      cappRevision.Name,
-			Namespace: cappRevision.Namespace,
+			Name:            cappRevision.Name,
+			Namespace:       cappRevision.Namespace,
+			ResourceVersion: cappRevision.ResourceVersion,
 		},
 		Annotations: utils.ConvertMapToKeyValue(cappRevision.Annotations),
 		Labels:      utils.ConvertMapToKeyValue(cappRevision.Labels),
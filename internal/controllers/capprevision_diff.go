@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// LiveCapp is the value of the against query parameter which diffs a CappRevision against the live Capp.
+	LiveCapp          = "live"
+	UnifiedDiffFormat = "unified"
+	diffContextLines  = 3
+)
+
+const (
+	ErrCouldNotDiffCappRevision = "Could not diff capp revision %q in namespace %q against %q"
+)
+
+const (
+	secretKeyRefKey = "secretKeyRef"
+	nameKey         = "name"
+)
+
+// plainPathKey matches the keys which can be appended to a diff path with a dot, unlike keys such as label keys.
+var plainPathKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// GetCappRevisionDiff returns the field-level diff from the CappTemplate of a CappRevision of a Capp to the
+// CappTemplate of another of its CappRevisions, or to the live Capp. The Capp is read from the hub, while its
+// CappRevisions are read from the managed cluster it is placed on.
+func (c *cappRevisionController) GetCappRevisionDiff(namespace, cappName, name string, query types.CappRevisionDiffQuery) (types.CappRevisionDiff, error) {
+	c.logger.Debug(fmt.Sprintf("Trying to diff capp revision %q in namespace %q against %q", name, namespace, query.Against))
+
+	capp := cappv1alpha1.Capp{}
+	if err := c.client.Get(c.ctx, client.ObjectKey{Namespace: namespace, Name: cappName}, &capp); err != nil {
+		c.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetCapp, cappName, namespace), err.Error()))
+		return types.CappRevisionDiff{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetCapp, cappName, namespace), err)
+	}
+
+	revisionContext, err := managedClusterContext(c.ctx, &capp)
+	if err != nil {
+		return types.CappRevisionDiff{}, err
+	}
+	revisionController := NewCappRevisionController(c.client, revisionContext, c.logger)
+
+	revision, err := revisionController.GetCappRevisionOfCapp(namespace, cappName, name)
+	if err != nil {
+		return types.CappRevisionDiff{}, err
+	}
+
+	against := query.Against
+	if against == "" {
+		against = LiveCapp
+	}
+
+	template := cappv1alpha1.CappTemplate{Spec: capp.Spec, Labels: capp.Labels, Annotations: capp.Annotations}
+	if against != LiveCapp {
+		otherRevision, err := revisionController.GetCappRevisionOfCapp(namespace, cappName, against)
+		if err != nil {
+			return types.CappRevisionDiff{}, err
+		}
+		template = otherRevision.Spec.CappTemplate
+	}
+
+	diff, err := diffCappTemplates(revision.Spec.CappTemplate, template, name, against, query.Format == UnifiedDiffFormat)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotDiffCappRevision, name, namespace, against), err.Error()))
+		return types.CappRevisionDiff{}, customerrors.NewInternalServerError(fmt.Sprintf(ErrCouldNotDiffCappRevision, name, namespace, against))
+	}
+
+	return diff, nil
+}
+
+// diffCappTemplates returns the changes from one CappTemplate to another, keyed by their JSON paths,
+// and their unified diff if it is requested.
+func diffCappTemplates(from, to cappv1alpha1.CappTemplate, fromName, toName string, unified bool) (types.CappRevisionDiff, error) {
+	fromValue, err := toDiffValue(from)
+	if err != nil {
+		return types.CappRevisionDiff{}, err
+	}
+
+	toValue, err := toDiffValue(to)
+	if err != nil {
+		return types.CappRevisionDiff{}, err
+	}
+
+	diff := types.CappRevisionDiff{From: fromName, To: toName, Changes: []types.FieldDiff{}}
+	diffValues("", fromValue, toValue, &diff.Changes)
+
+	if unified {
+		diff.Unified, err = unifiedDiff(fromValue, toValue, fromName, toName)
+	}
+
+	return diff, err
+}
+
+// toDiffValue returns the JSON representation of the CappTemplate, in which the Secrets referenced
+// by environment variables are shown by name only.
+func toDiffValue(template cappv1alpha1.CappTemplate) (interface{}, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	redactSecretKeyRefs(value)
+	return value, nil
+}
+
+// redactSecretKeyRefs removes everything but the name of the Secret from the Secret key references in the value.
+func redactSecretKeyRefs(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if secretKeyRef, ok := field.(map[string]interface{}); ok && key == secretKeyRefKey {
+				value[key] = map[string]interface{}{nameKey: secretKeyRef[nameKey]}
+				continue
+			}
+			redactSecretKeyRefs(field)
+		}
+	case []interface{}:
+		for _, element := range value {
+			redactSecretKeyRefs(element)
+		}
+	}
+}
+
+// diffValues appends the changes from one JSON value to another to changes. Objects are compared by field and lists
+// of named objects, such as containers and environment variables, by name; other values are compared as a whole.
+func diffValues(path string, from, to interface{}, changes *[]types.FieldDiff) {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if fromIsObject && toIsObject {
+		for _, key := range unionKeys(fromObject, toObject) {
+			diffValues(joinPath(path, key), fromObject[key], toObject[key], changes)
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		diffLists(path, fromList, toList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, types.FieldDiff{Path: path, OldValue: from, NewValue: to})
+	}
+}
+
+// diffLists appends the changes from one JSON list to another to changes, matching the elements by name
+// if all of them are named, and by index otherwise.
+func diffLists(path string, from, to []interface{}, changes *[]types.FieldDiff) {
+	fromNamed, fromNames := namedElements(from)
+	toNamed, toNames := namedElements(to)
+	if fromNames != nil && toNames != nil {
+		names := fromNames
+		for _, name := range toNames {
+			if _, ok := fromNamed[name]; !ok {
+				names = append(names, name)
+			}
+		}
+
+		for _, name := range names {
+			diffValues(fmt.Sprintf("%s[%s=%s]", path, nameKey, name), fromNamed[name], toNamed[name], changes)
+		}
+		return
+	}
+
+	for i := 0; i < max(len(from), len(to)); i++ {
+		var fromElement, toElement interface{}
+		if i < len(from) {
+			fromElement = from[i]
+		}
+		if i < len(to) {
+			toElement = to[i]
+		}
+		diffValues(fmt.Sprintf("%s[%d]", path, i), fromElement, toElement, changes)
+	}
+}
+
+// namedElements returns the elements of the list by their names, along with the names in order, or nil
+// if not all the elements are objects with a unique name.
+func namedElements(list []interface{}) (map[string]interface{}, []string) {
+	named := make(map[string]interface{}, len(list))
+	names := make([]string, 0, len(list))
+	for _, element := range list {
+		object, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+
+		name, ok := object[nameKey].(string)
+		if _, exists := named[name]; !ok || name == "" || exists {
+			return nil, nil
+		}
+
+		named[name] = element
+		names = append(names, name)
+	}
+
+	return named, names
+}
+
+// unionKeys returns the sorted keys of both objects.
+func unionKeys(from, to map[string]interface{}) []string {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+// joinPath appends the key of an object field to a diff path, quoting keys such as label keys.
+func joinPath(path, key string) string {
+	if !plainPathKey.MatchString(key) {
+		return fmt.Sprintf("%s[%s]", path, strconv.Quote(key))
+	}
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// unifiedDiff returns the unified diff of the YAML representations of the values.
+func unifiedDiff(from, to interface{}, fromName, toName string) (string, error) {
+	fromYAML, err := yaml.Marshal(from)
+	if err != nil {
+		return "", err
+	}
+
+	toYAML, err := yaml.Marshal(to)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromYAML)),
+		B:        difflib.SplitLines(string(toYAML)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  diffContextLines,
+	})
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	multicluster "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/transport"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const (
+	imagePath     = "cappSpec.configurationSpec.template.spec.containers[name=" + testutils.ContainerName + "].image"
+	envPath       = "cappSpec.configurationSpec.template.spec.containers[name=" + testutils.ContainerName + "].env"
+	secretEnvName = "DB_PASSWORD"
+	secretName    = "db"
+)
+
+// prepareCappTemplate returns a CappTemplate whose container has the given image and environment variables.
+func prepareCappTemplate(image string, env []corev1.EnvVar, labels map[string]string) cappv1alpha1.CappTemplate {
+	spec := mocks.PrepareCappSpec(testutils.SiteName)
+	spec.ConfigurationSpec.Template.Spec.Containers[0].Image = image
+	spec.ConfigurationSpec.Template.Spec.Containers[0].Env = env
+
+	return cappv1alpha1.CappTemplate{Spec: spec, Labels: labels}
+}
+
+func TestDiffCappTemplates(t *testing.T) {
+	secretEnv := corev1.EnvVar{Name: secretEnvName, ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "password"},
+	}}
+	plainEnv := corev1.EnvVar{Name: "MODE", Value: "debug"}
+
+	cases := map[string]struct {
+		from cappv1alpha1.CappTemplate
+		to   cappv1alpha1.CappTemplate
+		want []types.FieldDiff
+	}{
+		"ShouldFindNoChanges": {
+			from: prepareCappTemplate(testutils.CappImage, nil, nil),
+			to:   prepareCappTemplate(testutils.CappImage, nil, nil),
+			want: []types.FieldDiff{},
+		},
+		"ShouldFindChangedImage": {
+			from: prepareCappTemplate(testutils.PreviousCappImage, nil, nil),
+			to:   prepareCappTemplate(testutils.CappImage, nil, nil),
+			want: []types.FieldDiff{{Path: imagePath, OldValue: testutils.PreviousCappImage, NewValue: testutils.CappImage}},
+		},
+		"ShouldMatchEnvironmentVariablesByName": {
+			from: prepareCappTemplate(testutils.CappImage, []corev1.EnvVar{plainEnv}, nil),
+			to:   prepareCappTemplate(testutils.CappImage, []corev1.EnvVar{{Name: "LEVEL", Value: "1"}, {Name: "MODE", Value: "release"}}, nil),
+			want: []types.FieldDiff{
+				{Path: envPath + "[name=MODE].value", OldValue: "debug", NewValue: "release"},
+				{Path: envPath + "[name=LEVEL]", NewValue: map[string]interface{}{"name": "LEVEL", "value": "1"}},
+			},
+		},
+		"ShouldShowSecretReferencesByName": {
+			from: prepareCappTemplate(testutils.CappImage, nil, nil),
+			to:   prepareCappTemplate(testutils.CappImage, []corev1.EnvVar{secretEnv}, nil),
+			want: []types.FieldDiff{{Path: envPath, NewValue: []interface{}{map[string]interface{}{
+				"name":      secretEnvName,
+				"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": secretName}},
+			}}}},
+		},
+		"ShouldFindRemovedLabels": {
+			from: prepareCappTemplate(testutils.CappImage, nil, map[string]string{testutils.LabelCappName: testutils.CappName}),
+			to:   prepareCappTemplate(testutils.CappImage, nil, nil),
+			want: []types.FieldDiff{{Path: "labels", OldValue: map[string]interface{}{testutils.LabelCappName: testutils.CappName}}},
+		},
+		"ShouldFindChangedLabel": {
+			from: prepareCappTemplate(testutils.CappImage, nil, map[string]string{testutils.LabelCappName: testutils.CappName}),
+			to:   prepareCappTemplate(testutils.CappImage, nil, map[string]string{testutils.LabelCappName: testutils.CappName + "-2"}),
+			want: []types.FieldDiff{{Path: `labels["` + testutils.LabelCappName + `"]`, OldValue: testutils.CappName, NewValue: testutils.CappName + "-2"}},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff, err := diffCappTemplates(test.from, test.to, testutils.CappRevisionName+"-1", LiveCapp, false)
+			assert.NoError(t, err)
+			assert.Equal(t, types.CappRevisionDiff{From: testutils.CappRevisionName + "-1", To: LiveCapp, Changes: test.want}, diff)
+		})
+	}
+}
+
+func TestDiffCappTemplatesUnified(t *testing.T) {
+	diff, err := diffCappTemplates(prepareCappTemplate(testutils.PreviousCappImage, nil, nil), prepareCappTemplate(testutils.CappImage, nil, nil),
+		testutils.CappRevisionName+"-1", LiveCapp, true)
+	assert.NoError(t, err)
+	assert.Contains(t, diff.Unified, "--- "+testutils.CappRevisionName+"-1\n+++ "+LiveCapp+"\n")
+	assert.Contains(t, diff.Unified, "-        - image: "+testutils.PreviousCappImage+"\n")
+	assert.Contains(t, diff.Unified, "+        - image: "+testutils.CappImage+"\n")
+}
+
+func TestGetCappRevisionDiff(t *testing.T) {
+	namespaceName := testutils.CappRevisionNamespace + "-diff"
+
+	type requestParams struct {
+		cappName string
+		name     string
+		query    types.CappRevisionDiffQuery
+	}
+
+	type want struct {
+		to          string
+		changes     []types.FieldDiff
+		errorStatus metav1.StatusReason
+	}
+
+	cases := map[string]struct {
+		requestParams requestParams
+		want          want
+	}{
+		"ShouldSucceedDiffingAgainstLiveCapp": {
+			requestParams: requestParams{cappName: testutils.CappName, name: testutils.CappRevisionName + "-1"},
+			want: want{
+				to:          LiveCapp,
+				changes:     []types.FieldDiff{{Path: imagePath, OldValue: testutils.PreviousCappImage, NewValue: testutils.CappImage}},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedDiffingAgainstCappRevision": {
+			requestParams: requestParams{cappName: testutils.CappName, name: testutils.CappRevisionName + "-1", query: types.CappRevisionDiffQuery{Against: testutils.CappRevisionName + "-2"}},
+			want: want{
+				to:          testutils.CappRevisionName + "-2",
+				changes:     []types.FieldDiff{},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailDiffingAgainstRevisionOfAnotherCapp": {
+			requestParams: requestParams{cappName: testutils.CappName, name: testutils.CappRevisionName + "-1", query: types.CappRevisionDiffQuery{Against: testutils.CappRevisionName + "-3"}},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+		"ShouldFailDiffingNonExistingCappRevision": {
+			requestParams: requestParams{cappName: testutils.CappName, name: testutils.CappRevisionName + testutils.NonExistentSuffix},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+		"ShouldFailDiffingCappWhichIsNotPlaced": {
+			requestParams: requestParams{cappName: testutils.CappName + "-unplaced", name: testutils.CappRevisionName + "-4"},
+			want:          want{errorStatus: metav1.StatusReasonNotFound},
+		},
+	}
+
+	setup()
+	// The managed clusters which Capps and CappRevisions are read from are recorded.
+	var cappClusters, revisionClusters []string
+	interceptedClient := interceptor.NewClient(dynClient, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			cluster, _ := multicluster.GetMultiClusterContext(ctx)
+			if _, isRevision := obj.(*cappv1alpha1.CappRevision); isRevision {
+				revisionClusters = append(revisionClusters, cluster)
+			} else {
+				cappClusters = append(cappClusters, cluster)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	})
+	cappRevisionController := NewCappRevisionController(interceptedClient, mocks.GinContext(), logger)
+	createTestNamespace(namespaceName, map[string]string{})
+	capp := mocks.PrepareCapp(testutils.CappName, namespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	capp.Status.ApplicationLinks.Site = testutils.ManagedClusterName
+	assert.NoError(t, dynClient.Create(context.TODO(), &capp))
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-unplaced", namespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-4", namespaceName, testutils.CappName+"-unplaced", testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-1", namespaceName, testutils.CappName, testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-2", namespaceName, testutils.CappName, testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, testutils.CappRevisionName+"-3", namespaceName, testutils.CappName+"-2", testutils.SiteName, testutils.CappImage, nil, nil)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			cappClusters, revisionClusters = nil, nil
+			response, err := cappRevisionController.GetCappRevisionDiff(namespaceName, test.requestParams.cappName, test.requestParams.name, test.requestParams.query)
+			if test.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.want.errorStatus, reason)
+				assert.Equal(t, types.CappRevisionDiff{}, response)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, types.CappRevisionDiff{From: test.requestParams.name, To: test.want.to, Changes: test.want.changes}, response)
+			assert.Equal(t, []string{""}, cappClusters)
+			assert.NotEmpty(t, revisionClusters)
+			for _, cluster := range revisionClusters {
+				assert.Equal(t, testutils.ManagedClusterName, cluster)
+			}
+		})
+	}
+}
@@ -47,7 +47,7 @@ func TestGetCappRevision(t *testing.T) {
 			},
 			want: want{
 				cappRevision: types.CappRevision{
-					Metadata: types.Metadata{Name: testutils.CappRevisionName + "-1", Namespace: namespaceName},
+					Metadata: types.Metadata{Name: testutils.CappRevisionName + "-1", Namespace: namespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					Labels:   labels,
 					Spec: cappv1alpha1.CappRevisionSpec{
 						RevisionNumber: 1,
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/utils"
+	gatewayconfig "github.com/oam-dev/cluster-gateway/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	envKubeAPIServerCA     = "KUBE_API_SERVER_CA"
+	envKubeAPIServerCAFile = "KUBE_API_SERVER_CA_FILE"
+)
+
+const (
+	// rootCAConfigMapName is the ConfigMap published in every namespace which holds the CA bundle of the API server.
+	rootCAConfigMapName = "kube-root-ca.crt"
+	rootCAConfigMapKey  = "ca.crt"
+	hubClusterName      = "hub"
+)
+
+const (
+	ErrKubeAPIServerNotSet   = "The API server address is not configured"
+	ErrCouldNotGetRootCA     = "Could not get the CA bundle of the API server in namespace %q"
+	ErrCouldNotReadCAFile    = "Could not read the CA bundle of the API server from %q: %v"
+	ErrInvalidClusterName    = "Invalid cluster name %q: %s"
+	ErrCouldNotGetKubeconfig = "Could not create kubeconfig for serviceaccount %q in namespace %q"
+)
+
+// kubeconfigServer holds the address of the API server and how kubeconfigs verify its certificate.
+type kubeconfigServer struct {
+	address  string
+	caData   []byte
+	insecure bool
+}
+
+// getKubeconfigServer returns the address of the API server along with its CA bundle, unless certificate
+// verification is explicitly skipped. The CA bundle is the one configured for the API server address, or the one of the
+// root CA ConfigMap of the namespace if none is configured. The latter holds the CA of the in-cluster service,
+// which may not be the CA of the address.
+func (t *tokenController) getKubeconfigServer(namespace string) (kubeconfigServer, error) {
+	address := os.Getenv(utils.EnvKubeAPIServer)
+	if address == "" {
+		return kubeconfigServer{}, customerrors.NewInternalServerError(ErrKubeAPIServerNotSet)
+	}
+
+	insecure, err := utils.GetEnvBool(utils.EnvInsecureSkipVerify, false)
+	if err != nil {
+		return kubeconfigServer{}, customerrors.NewInternalServerError(err.Error())
+	}
+	if insecure {
+		return kubeconfigServer{address: address, insecure: true}, nil
+	}
+
+	caData, err := getConfiguredCA()
+	if err != nil {
+		return kubeconfigServer{}, customerrors.NewInternalServerError(err.Error())
+	}
+	if len(caData) > 0 {
+		return kubeconfigServer{address: address, caData: caData}, nil
+	}
+
+	configMap, err := t.client.CoreV1().ConfigMaps(namespace).Get(t.ctx, rootCAConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return kubeconfigServer{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetRootCA, namespace), err)
+	}
+
+	return kubeconfigServer{address: address, caData: []byte(configMap.Data[rootCAConfigMapKey])}, nil
+}
+
+// getConfiguredCA returns the CA bundle of the API server address set by the KUBE_API_SERVER_CA environment
+// variable, or read from the KUBE_API_SERVER_CA_FILE file, or nil if neither is set.
+func getConfiguredCA() ([]byte, error) {
+	if caData := os.Getenv(envKubeAPIServerCA); caData != "" {
+		return []byte(caData), nil
+	}
+
+	path := os.Getenv(envKubeAPIServerCAFile)
+	if path == "" {
+		return nil, nil
+	}
+
+	caData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(ErrCouldNotReadCAFile, path, err)
+	}
+
+	return caData, nil
+}
+
+// validateClusterNames makes sure the names of the managed clusters are valid, so that they can be used in a proxy path.
+func validateClusterNames(clusters []string) error {
+	for _, cluster := range clusters {
+		if errs := validation.IsDNS1123Subdomain(cluster); len(errs) > 0 {
+			return customerrors.NewValidationError(fmt.Sprintf(ErrInvalidClusterName, cluster, strings.Join(errs, ", ")))
+		}
+	}
+
+	return nil
+}
+
+// prepareKubeconfig returns a kubeconfig which authenticates as the serviceaccount with the token. Its current context
+// targets the namespace on the hub cluster, and another context targets the namespace on each of the managed clusters,
+// through the proxy of the cluster-gateway.
+func prepareKubeconfig(server kubeconfigServer, serviceAccountName, namespace, token string, clusters []string) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	userName := fmt.Sprintf("%s/%s", namespace, serviceAccountName)
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: token}
+
+	addContext := func(contextName, clusterName, address string) {
+		config.Clusters[clusterName] = &clientcmdapi.Cluster{
+			Server:                   address,
+			CertificateAuthorityData: server.caData,
+			InsecureSkipTLSVerify:    server.insecure,
+		}
+		config.Contexts[contextName] = &clientcmdapi.Context{
+			Cluster:   clusterName,
+			AuthInfo:  userName,
+			Namespace: namespace,
+		}
+	}
+
+	config.CurrentContext = userName
+	addContext(userName, hubClusterName, server.address)
+	for _, cluster := range clusters {
+		addContext(fmt.Sprintf("%s/%s", userName, cluster), fmt.Sprintf("%s/%s", hubClusterName, cluster), clusterGatewayProxyURL(server.address, cluster))
+	}
+
+	return config
+}
+
+// clusterGatewayProxyURL returns the address through which the cluster-gateway of the hub proxies requests to the managed cluster.
+func clusterGatewayProxyURL(address, cluster string) string {
+	return strings.Join([]string{
+		strings.TrimSuffix(address, "/"),
+		"apis",
+		gatewayconfig.MetaApiGroupName,
+		gatewayconfig.MetaApiVersionName,
+		gatewayconfig.MetaApiResourceName,
+		cluster,
+		"proxy",
+	}, "/")
+}
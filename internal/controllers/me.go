@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/dana-team/platform-backend/internal/utils/pagination"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ErrCouldNotGetUserNamespaces   = "Could not get namespaces of user %q"
+	ErrCouldNotGetUserRole         = "Could not get role of user %q in namespace %q"
+	ErrCouldNotReviewSubjectsRules = "Could not review rules of user %q in namespace %q"
+)
+
+const (
+	cappsResource        = "capps"
+	roleBindingsResource = "rolebindings"
+	verbAll              = "*"
+	resourceAll          = "*"
+	apiGroupAll          = "*"
+)
+
+type MeController interface {
+	// GetMe returns the username and groups of the user, along with the namespaces
+	// the user can access and the platform role of the user in each of them. Namespaces in which the user
+	// has no role, or whose role cannot be determined, are left out.
+	GetMe(username string, groups []string, limit, page int) (types.Me, error)
+}
+
+type meController struct {
+	client      kubernetes.Interface
+	ctx         context.Context
+	logger      *zap.Logger
+	roleCatalog *RoleCatalog
+}
+
+func NewMeController(client kubernetes.Interface, context context.Context, logger *zap.Logger, roleCatalog *RoleCatalog) MeController {
+	return &meController{
+		logger:      logger,
+		client:      client,
+		ctx:         context,
+		roleCatalog: roleCatalog,
+	}
+}
+
+func (m *meController) GetMe(username string, groups []string, limit, page int) (types.Me, error) {
+	me := types.Me{Username: username, Groups: groups, Namespaces: []types.NamespaceRole{}}
+	m.logger.Debug(fmt.Sprintf("Trying to fetch namespaces of user %q", username))
+
+	namespacePaginator := &NamespacePaginator{
+		GenericPaginator: pagination.CreatePaginator(m.ctx, m.logger),
+		client:           m.client,
+	}
+
+	namespaces, err := pagination.FetchPage[corev1.Namespace](limit, page, namespacePaginator)
+	if err != nil {
+		m.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetUserNamespaces, username), err))
+		return types.Me{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetUserNamespaces, username), err)
+	}
+
+	for _, namespace := range namespaces {
+		// A namespace whose role cannot be determined, because the user may not review its access in it or it was
+		// deleted since it was listed, is left out of the page rather than failing it.
+		role, err := m.getPlatformRole(namespace.Name, username, groups)
+		if k8serrors.IsForbidden(err) || k8serrors.IsNotFound(err) {
+			m.logger.Debug(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetUserRole, username, namespace.Name), err))
+			continue
+		} else if err != nil {
+			m.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetUserRole, username, namespace.Name), err))
+			return types.Me{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetUserRole, username, namespace.Name), err)
+		}
+		if role == "" {
+			continue
+		}
+		me.Namespaces = append(me.Namespaces, types.NamespaceRole{Name: namespace.Name, Role: role})
+	}
+	me.Count = len(me.Namespaces)
+
+	m.logger.Debug(fmt.Sprintf("Fetched namespaces of user %q successfully", username))
+	return me, nil
+}
+
+// getPlatformRole returns the most privileged platform role bound to the user, or to one of its groups,
+// in the namespace. When the user may not list RoleBindings in the namespace, the role is derived from
+// the rules the user is allowed to perform and the rules of the roles of the catalog instead.
+func (m *meController) getPlatformRole(namespace, username string, groups []string) (string, error) {
+	roleBindings, err := m.client.RbacV1().RoleBindings(namespace).List(m.ctx, metav1.ListOptions{LabelSelector: utils.ManagedLabelSelector})
+	if k8serrors.IsForbidden(err) {
+		return m.getPlatformRoleFromRules(namespace, username)
+	} else if err != nil {
+		return "", err
+	}
+
+	role := ""
+	for _, roleBinding := range roleBindings.Items {
+		if isBoundToUser(roleBinding.Subjects, username, groups) {
+			role = m.roleCatalog.MostPrivileged(role, m.roleCatalog.convertToPlatformRole(roleBinding.RoleRef.Name))
+		}
+	}
+
+	return role, nil
+}
+
+// getPlatformRoleFromRules derives the platform role of the user from a SelfSubjectRulesReview in the namespace.
+// The role is the most privileged role of the catalog whose rules the user is all allowed to perform. Roles without
+// rules on resources are skipped, so that no ClusterRole, which the user may not read either, has to be read.
+func (m *meController) getPlatformRoleFromRules(namespace, username string) (string, error) {
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+
+	result, err := m.client.AuthorizationV1().SelfSubjectRulesReviews().Create(m.ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		m.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotReviewSubjectsRules, username, namespace), err))
+		return "", err
+	}
+
+	roles := m.roleCatalog.Roles()
+	for i := len(roles) - 1; i >= 0; i-- {
+		if areRulesAllowed(result.Status.ResourceRules, roles[i].Rules) {
+			return roles[i].Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// isBoundToUser returns true if one of the subjects is the user or one of its groups.
+func isBoundToUser(subjects []rbacv1.Subject, username string, groups []string) bool {
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.UserKind && subject.Name == username {
+			return true
+		}
+		if subject.Kind == rbacv1.GroupKind && slices.Contains(groups, subject.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// areRulesAllowed returns true if the allowed rules allow every verb on every resource of the policy rules,
+// which must include at least one rule on resources.
+func areRulesAllowed(allowed []authorizationv1.ResourceRule, policyRules []rbacv1.PolicyRule) bool {
+	hasResourceRule := false
+	for _, policyRule := range policyRules {
+		for _, apiGroup := range policyRule.APIGroups {
+			for _, resource := range policyRule.Resources {
+				for _, verb := range policyRule.Verbs {
+					if !isRuleAllowed(allowed, apiGroup, resource, verb) {
+						return false
+					}
+					hasResourceRule = true
+				}
+			}
+		}
+	}
+
+	return hasResourceRule
+}
+
+// isRuleAllowed returns true if one of the rules allows the verb on the resource.
+func isRuleAllowed(rules []authorizationv1.ResourceRule, apiGroup, resource, verb string) bool {
+	for _, rule := range rules {
+		if (slices.Contains(rule.APIGroups, apiGroup) || slices.Contains(rule.APIGroups, apiGroupAll)) &&
+			(slices.Contains(rule.Resources, resource) || slices.Contains(rule.Resources, resourceAll)) &&
+			(slices.Contains(rule.Verbs, verb) || slices.Contains(rule.Verbs, verbAll)) {
+			return true
+		}
+	}
+
+	return false
+}
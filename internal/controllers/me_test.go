@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetMe(t *testing.T) {
+	meNamespace := testutils.TestNamespace + "-me"
+	userName := testutils.TestName + "-user"
+	otherUserName := testutils.TestName + "-other-user"
+
+	type requestParams struct {
+		username string
+		groups   []string
+	}
+
+	type want struct {
+		response    types.Me
+		errorStatus metav1.StatusReason
+	}
+
+	cases := map[string]struct {
+		requestParams requestParams
+		failing       bool
+		want          want
+	}{
+		"ShouldSucceedGettingRolesOfUser": {
+			requestParams: requestParams{
+				username: userName,
+				groups:   []string{testutils.GroupName},
+			},
+			want: want{
+				response: types.Me{
+					Username: userName,
+					Groups:   []string{testutils.GroupName},
+					Namespaces: []types.NamespaceRole{
+						{Name: meNamespace + "-admin", Role: AdminPlatformRole},
+						{Name: meNamespace + "-group", Role: ContributorPlatformRole},
+						{Name: meNamespace + "-rules", Role: ContributorPlatformRole},
+						{Name: meNamespace + "-viewer", Role: ViewerPlatformRole},
+					},
+					ListMetadata: types.ListMetadata{Count: 4},
+				},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedGettingRolesOfUserWithoutGroups": {
+			requestParams: requestParams{
+				username: otherUserName,
+			},
+			want: want{
+				response: types.Me{
+					Username: otherUserName,
+					Namespaces: []types.NamespaceRole{
+						{Name: meNamespace + "-group", Role: ViewerPlatformRole},
+						{Name: meNamespace + "-rules", Role: ContributorPlatformRole},
+					},
+					ListMetadata: types.ListMetadata{Count: 2},
+				},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailWhenRoleBindingsCannotBeListed": {
+			requestParams: requestParams{
+				username: userName,
+			},
+			failing: true,
+			want: want{
+				errorStatus: metav1.StatusReasonInternalError,
+			},
+		},
+	}
+
+	setup()
+	for _, suffix := range []string{"-admin", "-deleted", "-failing", "-group", "-none", "-rules", "-viewer"} {
+		mocks.CreateTestNamespace(fakeClient, meNamespace+suffix)
+	}
+	mocks.CreateTestRoleBinding(fakeClient, userName, meNamespace+"-admin", testutils.AdminKey)
+	mocks.CreateTestRoleBinding(fakeClient, userName, meNamespace+"-group", testutils.ViewerKey)
+	mocks.CreateTestRoleBinding(fakeClient, otherUserName, meNamespace+"-group", testutils.ViewerKey)
+	mocks.CreateTestGroupRoleBinding(fakeClient, testutils.GroupName, meNamespace+"-group", testutils.GroupName, testutils.ContributorKey)
+	mocks.CreateTestRoleBinding(fakeClient, userName, meNamespace+"-viewer", testutils.ViewerKey)
+
+	failing := false
+	fakeClient.PrependReactor("list", testutils.RoleBindingsKey, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		switch action.GetNamespace() {
+		case meNamespace + "-rules":
+			return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: testutils.RoleBindingsKey}, "", nil)
+		case meNamespace + "-deleted":
+			return true, nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, meNamespace+"-deleted")
+		case meNamespace + "-failing":
+			if failing {
+				return true, nil, k8serrors.NewInternalError(errors.New("etcd is unavailable"))
+			}
+			return false, nil, nil
+		default:
+			return false, nil, nil
+		}
+	})
+	fakeClient.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+		review.Status.ResourceRules = []authorizationv1.ResourceRule{
+			{Verbs: []string{"get", "list", "update"}, APIGroups: []string{"rcs.dana.io"}, Resources: []string{"capps"}},
+		}
+		return true, review, nil
+	})
+	// The ClusterRoles of the role catalog are not read to derive roles from rules.
+	fakeClient.PrependReactor("get", "clusterroles", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "clusterroles"}, "", nil)
+	})
+
+	meController := NewMeController(fakeClient, mocks.GinContext(), logger, DefaultRoleCatalog())
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			failing = test.failing
+			response, err := meController.GetMe(test.requestParams.username, test.requestParams.groups, 10, 1)
+			if test.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.want.errorStatus, reason)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.response, response)
+		})
+	}
+}
+
+func TestGetPlatformRoleFromRules(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-rules"
+	userName := testutils.TestName + "-user"
+	maintainerRole := "maintainer"
+	maintainerRules := []rbacv1.PolicyRule{
+		{Verbs: []string{"get", "list", "update", "delete"}, APIGroups: []string{"rcs.dana.io"}, Resources: []string{"capps"}},
+	}
+
+	type want struct {
+		role string
+	}
+
+	cases := map[string]struct {
+		roles []types.Role
+		rules []authorizationv1.ResourceRule
+		want  want
+	}{
+		"ShouldDeriveMostPrivilegedRoleOfCatalog": {
+			roles: DefaultRoleCatalog().Roles(),
+			rules: []authorizationv1.ResourceRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+			},
+			want: want{role: AdminPlatformRole},
+		},
+		"ShouldDeriveRoleOfConfiguredCatalog": {
+			roles: []types.Role{
+				DefaultRoleCatalog().Roles()[0],
+				{Name: maintainerRole, ClusterRole: "capp-user-" + maintainerRole, Rules: maintainerRules},
+			},
+			rules: []authorizationv1.ResourceRule{
+				{Verbs: []string{"get", "list", "update", "delete"}, APIGroups: []string{"rcs.dana.io"}, Resources: []string{"capps"}},
+			},
+			want: want{role: maintainerRole},
+		},
+		"ShouldSkipRolesWithoutRules": {
+			roles: []types.Role{
+				DefaultRoleCatalog().Roles()[0],
+				{Name: maintainerRole, ClusterRole: "capp-user-" + maintainerRole},
+			},
+			rules: []authorizationv1.ResourceRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+			},
+			want: want{role: ViewerPlatformRole},
+		},
+		"ShouldReturnNoRoleWhenNoRoleIsAllowed": {
+			roles: DefaultRoleCatalog().Roles(),
+			rules: []authorizationv1.ResourceRule{
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"namespaces"}},
+			},
+			want: want{role: ""},
+		},
+	}
+
+	setup()
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			fakeClient.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+				review.Status.ResourceRules = test.rules
+				return true, review, nil
+			})
+
+			roleCatalog, err := NewRoleCatalog(test.roles)
+			assert.NoError(t, err)
+			meController := &meController{client: fakeClient, ctx: mocks.GinContext(), logger: logger, roleCatalog: roleCatalog}
+
+			role, err := meController.getPlatformRoleFromRules(namespaceName, userName)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.role, role)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ErrCouldNotCheckPermissions = "Could not check permissions in namespace %q"
+	ErrUnknownPermissionPreset  = "Unknown permission preset %q, supported presets are: %s"
+	ErrIncompletePermission     = "Permission check %d must set either a preset or a verb and a resource"
+)
+
+// maxConcurrentAccessReviews is the maximum number of SelfSubjectAccessReviews created at once for a single request.
+const maxConcurrentAccessReviews = 10
+
+// PermissionPresets maps the actions offered by the API to the permission each of them requires.
+var PermissionPresets = map[string]types.PermissionCheck{
+	"capps:create":           {Verb: "create", Group: cappv1alpha1.GroupVersion.Group, Resource: cappsResource},
+	"capps:update":           {Verb: "update", Group: cappv1alpha1.GroupVersion.Group, Resource: cappsResource},
+	"capps:delete":           {Verb: "delete", Group: cappv1alpha1.GroupVersion.Group, Resource: cappsResource},
+	"pods:exec":              {Verb: "create", Resource: "pods", Subresource: "exec"},
+	"pods:logs":              {Verb: "get", Resource: "pods", Subresource: "log"},
+	"secrets:create":         {Verb: "create", Resource: "secrets"},
+	"secrets:update":         {Verb: "update", Resource: "secrets"},
+	"secrets:delete":         {Verb: "delete", Resource: "secrets"},
+	"users:manage":           {Verb: "create", Group: rbacv1.GroupName, Resource: roleBindingsResource},
+	"serviceaccounts:create": {Verb: "create", Resource: "serviceaccounts"},
+}
+
+type PermissionController interface {
+	// CheckPermissions reviews whether the user may perform each of the checks in the namespace.
+	// The results are returned in the order of the checks.
+	CheckPermissions(namespace string, checks []types.PermissionCheck) (types.PermissionsCheckOutput, error)
+}
+
+type permissionController struct {
+	client kubernetes.Interface
+	ctx    context.Context
+	logger *zap.Logger
+}
+
+func NewPermissionController(client kubernetes.Interface, context context.Context, logger *zap.Logger) PermissionController {
+	return &permissionController{
+		logger: logger,
+		client: client,
+		ctx:    context,
+	}
+}
+
+func (p *permissionController) CheckPermissions(namespace string, checks []types.PermissionCheck) (types.PermissionsCheckOutput, error) {
+	p.logger.Debug(fmt.Sprintf("Trying to check %d permissions in namespace %q", len(checks), namespace))
+
+	resolvedChecks := make([]types.PermissionCheck, len(checks))
+	for i, check := range checks {
+		resolvedCheck, err := resolvePermissionCheck(i, check)
+		if err != nil {
+			return types.PermissionsCheckOutput{}, err
+		}
+		resolvedChecks[i] = resolvedCheck
+	}
+
+	results := make([]types.PermissionCheckResult, len(resolvedChecks))
+	group, ctx := errgroup.WithContext(p.ctx)
+	group.SetLimit(maxConcurrentAccessReviews)
+	for i, check := range resolvedChecks {
+		group.Go(func() error {
+			result, err := p.reviewAccess(ctx, namespace, check)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		p.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotCheckPermissions, namespace), err))
+		return types.PermissionsCheckOutput{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCheckPermissions, namespace), err)
+	}
+
+	p.logger.Debug(fmt.Sprintf("Checked permissions in namespace %q successfully", namespace))
+	return types.PermissionsCheckOutput{Results: results}, nil
+}
+
+// reviewAccess creates a SelfSubjectAccessReview for the check and converts its status to a result.
+func (p *permissionController) reviewAccess(ctx context.Context, namespace string, check types.PermissionCheck) (types.PermissionCheckResult, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        check.Verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+				Name:        check.Name,
+			},
+		},
+	}
+
+	result, err := p.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return types.PermissionCheckResult{}, err
+	}
+
+	reason := result.Status.Reason
+	if reason == "" {
+		reason = result.Status.EvaluationError
+	}
+
+	return types.PermissionCheckResult{
+		PermissionCheck: check,
+		Allowed:         result.Status.Allowed,
+		Reason:          reason,
+	}, nil
+}
+
+// resolvePermissionCheck fills the attributes of the preset of the check, if it is set,
+// and makes sure the check has a verb and a resource.
+func resolvePermissionCheck(index int, check types.PermissionCheck) (types.PermissionCheck, error) {
+	if check.Preset != "" {
+		preset, ok := PermissionPresets[check.Preset]
+		if !ok {
+			return types.PermissionCheck{}, customerrors.NewValidationError(fmt.Sprintf(ErrUnknownPermissionPreset, check.Preset, strings.Join(permissionPresetNames(), ", ")))
+		}
+		preset.Preset = check.Preset
+		preset.Name = check.Name
+		return preset, nil
+	}
+
+	if check.Verb == "" || check.Resource == "" {
+		return types.PermissionCheck{}, customerrors.NewValidationError(fmt.Sprintf(ErrIncompletePermission, index))
+	}
+
+	return check, nil
+}
+
+// permissionPresetNames returns the sorted names of the permission presets.
+func permissionPresetNames() []string {
+	names := make([]string, 0, len(PermissionPresets))
+	for name := range PermissionPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	type requestParams struct {
+		checks []types.PermissionCheck
+	}
+
+	type want struct {
+		response    types.PermissionsCheckOutput
+		errorStatus metav1.StatusReason
+	}
+
+	cases := map[string]struct {
+		requestParams requestParams
+		want          want
+	}{
+		"ShouldSucceedCheckingPresets": {
+			requestParams: requestParams{
+				checks: []types.PermissionCheck{
+					{Preset: "pods:exec"},
+					{Preset: "capps:update", Name: testutils.CappName},
+					{Preset: "capps:delete", Name: testutils.CappName},
+				},
+			},
+			want: want{
+				response: types.PermissionsCheckOutput{Results: []types.PermissionCheckResult{
+					{
+						PermissionCheck: types.PermissionCheck{Preset: "pods:exec", Verb: "create", Resource: "pods", Subresource: "exec"},
+						Allowed:         true,
+						Reason:          testutils.AccessAllowedReason,
+					},
+					{
+						PermissionCheck: types.PermissionCheck{Preset: "capps:update", Verb: "update", Group: "rcs.dana.io", Resource: "capps", Name: testutils.CappName},
+						Allowed:         true,
+						Reason:          testutils.AccessAllowedReason,
+					},
+					{
+						PermissionCheck: types.PermissionCheck{Preset: "capps:delete", Verb: "delete", Group: "rcs.dana.io", Resource: "capps", Name: testutils.CappName},
+						Allowed:         false,
+					},
+				}},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedCheckingCustomPermission": {
+			requestParams: requestParams{
+				checks: []types.PermissionCheck{
+					{Verb: "update", Resource: "secrets", Name: testutils.SecretName},
+				},
+			},
+			want: want{
+				response: types.PermissionsCheckOutput{Results: []types.PermissionCheckResult{
+					{
+						PermissionCheck: types.PermissionCheck{Verb: "update", Resource: "secrets", Name: testutils.SecretName},
+						Allowed:         false,
+					},
+				}},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailWithUnknownPreset": {
+			requestParams: requestParams{
+				checks: []types.PermissionCheck{{Preset: "capps:explode"}},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
+		"ShouldFailWithIncompleteCheck": {
+			requestParams: requestParams{
+				checks: []types.PermissionCheck{{Preset: "pods:exec"}, {Verb: "get"}},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
+		"ShouldFailWhenReviewFails": {
+			requestParams: requestParams{
+				checks: []types.PermissionCheck{{Preset: "pods:exec"}, {Verb: "get", Resource: testutils.FailingResource}},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonInternalError,
+			},
+		},
+	}
+
+	setup()
+	mocks.AddTestAccessReviewReactor(fakeClient, "create pods/exec", "update capps")
+
+	permissionController := NewPermissionController(fakeClient, mocks.GinContext(), logger)
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			response, err := permissionController.CheckPermissions(testutils.TestNamespace, test.requestParams.checks)
+			if test.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, test.want.errorStatus, reason)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.response, response)
+		})
+	}
+}
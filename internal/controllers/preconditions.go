@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	ErrResourceVersionMismatch = "The resource version of %q is %q, not %q: it was changed since it was read"
+	ErrResourceVersionConflict = "The resource version of %q is not %q: it was changed while it was updated"
+)
+
+// checkResourceVersion makes sure an object which is about to be updated has the resource version which the request
+// expects it to have, if the request expects any. The resource version of the object is the one it was read with, so
+// an update made in between is rejected by the cluster as a conflict, which checkUpdateConflict handles.
+func checkResourceVersion(name, resourceVersion, expectedResourceVersion string) error {
+	if expectedResourceVersion != "" && expectedResourceVersion != resourceVersion {
+		return customerrors.NewPreconditionFailedError(fmt.Sprintf(ErrResourceVersionMismatch, name, resourceVersion, expectedResourceVersion))
+	}
+
+	return nil
+}
+
+// checkUpdateConflict returns a PreconditionFailedError if the cluster rejected the update of an object as a conflict
+// while the request expected the object to have a resource version, since the object no longer has it.
+func checkUpdateConflict(name, expectedResourceVersion string, err error) error {
+	if expectedResourceVersion != "" && errors.IsConflict(err) {
+		return customerrors.NewPreconditionFailedError(fmt.Sprintf(ErrResourceVersionConflict, name, expectedResourceVersion))
+	}
+
+	return nil
+}
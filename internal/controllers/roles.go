@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	envRoleCatalog     = "ROLE_CATALOG"
+	envRoleCatalogFile = "ROLE_CATALOG_FILE"
+)
+
+const (
+	AdminClusterRole        = "capp-user-admin"
+	ContributorClusterRole  = "capp-user-contributor"
+	ViewerClusterRole       = "capp-user-viewer"
+	AdminPlatformRole       = "admin"
+	ContributorPlatformRole = "contributor"
+	ViewerPlatformRole      = "viewer"
+)
+
+const (
+	ErrUnknownRole = "Unknown role %q, must be one of: %s"
+)
+
+// defaultRoleCatalog is used unless a role catalog is configured.
+var defaultRoleCatalog = &RoleCatalog{roles: []types.Role{
+	{
+		Name: ViewerPlatformRole, Description: "Views the resources of the namespace", ClusterRole: ViewerClusterRole,
+		Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{cappv1alpha1.GroupVersion.Group}, Resources: []string{cappsResource}}},
+	},
+	{
+		Name: ContributorPlatformRole, Description: "Manages the Capps and secrets of the namespace", ClusterRole: ContributorClusterRole,
+		Rules: []rbacv1.PolicyRule{{Verbs: []string{"update"}, APIGroups: []string{cappv1alpha1.GroupVersion.Group}, Resources: []string{cappsResource}}},
+	},
+	{
+		Name: AdminPlatformRole, Description: "Manages the namespace, including its members", ClusterRole: AdminClusterRole,
+		Rules: []rbacv1.PolicyRule{{Verbs: []string{"create"}, APIGroups: []string{rbacv1.GroupName}, Resources: []string{roleBindingsResource}}},
+	},
+}}
+
+// RoleCatalog maps the platform roles which may be granted to members of namespaces to the ClusterRoles they bind.
+// The roles are ranked from the least to the most privileged.
+type RoleCatalog struct {
+	roles []types.Role
+}
+
+// NewRoleCatalogFromEnv returns the RoleCatalog set by the ROLE_CATALOG environment variable, or read from the
+// ROLE_CATALOG_FILE file, which hold a YAML or JSON catalog such as {"roles": [{"name": "viewer", "clusterRole":
+// "capp-user-viewer"}]}. The default catalog is returned if neither is set.
+func NewRoleCatalogFromEnv() (*RoleCatalog, error) {
+	data := []byte(os.Getenv(envRoleCatalog))
+	if path := os.Getenv(envRoleCatalogFile); len(data) == 0 && path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read role catalog file %q: %v", path, err)
+		}
+	}
+
+	if len(data) == 0 {
+		return defaultRoleCatalog, nil
+	}
+
+	var catalog types.RoleCatalog
+	if err := yaml.UnmarshalStrict(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse role catalog: %v", err)
+	}
+
+	return NewRoleCatalog(catalog.Roles)
+}
+
+// NewRoleCatalog returns a new RoleCatalog of the roles, which are ranked from the least to the most privileged.
+func NewRoleCatalog(roles []types.Role) (*RoleCatalog, error) {
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("role catalog must have at least one role")
+	}
+
+	names, clusterRoles := map[string]bool{}, map[string]bool{}
+	for _, role := range roles {
+		if role.Name == "" || role.ClusterRole == "" {
+			return nil, fmt.Errorf("role %q of the role catalog must have a name and a ClusterRole", role.Name)
+		}
+		if names[role.Name] || clusterRoles[role.ClusterRole] {
+			return nil, fmt.Errorf("role %q of the role catalog must have a unique name and ClusterRole", role.Name)
+		}
+		names[role.Name], clusterRoles[role.ClusterRole] = true, true
+	}
+
+	return &RoleCatalog{roles: slices.Clone(roles)}, nil
+}
+
+// DefaultRoleCatalog returns the role catalog which is used unless a role catalog is configured.
+func DefaultRoleCatalog() *RoleCatalog {
+	return defaultRoleCatalog
+}
+
+// Roles returns the roles of the catalog, from the least to the most privileged.
+func (r *RoleCatalog) Roles() []types.Role {
+	return slices.Clone(r.roles)
+}
+
+// IsRole returns true if the catalog has a platform role of the given name.
+func (r *RoleCatalog) IsRole(name string) bool {
+	return r.rank(name) >= 0
+}
+
+// ClusterRole returns the ClusterRole bound by the platform role.
+func (r *RoleCatalog) ClusterRole(name string) (string, error) {
+	if rank := r.rank(name); rank >= 0 {
+		return r.roles[rank].ClusterRole, nil
+	}
+
+	return "", fmt.Errorf(ErrUnknownRole, name, strings.Join(r.names(), ", "))
+}
+
+// PlatformRole returns the platform role which binds the ClusterRole, and false if the ClusterRole
+// is not in the catalog.
+func (r *RoleCatalog) PlatformRole(clusterRole string) (string, bool) {
+	for _, role := range r.roles {
+		if role.ClusterRole == clusterRole {
+			return role.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// MostPrivileged returns the more privileged of the two platform roles. Roles which are not in the catalog
+// are less privileged than any role in it.
+func (r *RoleCatalog) MostPrivileged(role, otherRole string) string {
+	if r.rank(otherRole) > r.rank(role) {
+		return otherRole
+	}
+
+	return role
+}
+
+// rank returns the index of the platform role in the catalog, or -1 if it is not in it.
+func (r *RoleCatalog) rank(name string) int {
+	return slices.IndexFunc(r.roles, func(role types.Role) bool { return role.Name == name })
+}
+
+// names returns the names of the platform roles of the catalog.
+func (r *RoleCatalog) names() []string {
+	names := make([]string, 0, len(r.roles))
+	for _, role := range r.roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
+// validateRole makes sure the platform role is in the role catalog.
+func (r *RoleCatalog) validateRole(role string) error {
+	if _, err := r.ClusterRole(role); err != nil {
+		return customerrors.NewValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// convertToK8sRoles converts user given roles to the role which exists in the cluster. The role must have been
+// validated by validateRole.
+func (r *RoleCatalog) convertToK8sRoles(requestRole string) string {
+	clusterRole, _ := r.ClusterRole(requestRole)
+	return clusterRole
+}
+
+// convertToPlatformRole converts from the cluster role to platform roles. ClusterRoles which are not in the catalog,
+// such as those of roles which were removed from it, are reported by their name.
+func (r *RoleCatalog) convertToPlatformRole(k8sRole string) string {
+	if role, ok := r.PlatformRole(k8sRole); ok {
+		return role
+	}
+
+	return k8sRole
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	maintainerPlatformRole = "maintainer"
+	maintainerClusterRole  = "capp-user-maintainer"
+)
+
+func TestNewRoleCatalog(t *testing.T) {
+	cases := map[string]struct {
+		roles   []types.Role
+		wantErr bool
+	}{
+		"ShouldSucceedWithRoles": {
+			roles: []types.Role{
+				{Name: ViewerPlatformRole, ClusterRole: ViewerClusterRole},
+				{Name: maintainerPlatformRole, Description: "Maintains the Capps of the namespace", ClusterRole: maintainerClusterRole},
+			},
+		},
+		"ShouldFailWithoutRoles": {
+			wantErr: true,
+		},
+		"ShouldFailWithoutClusterRole": {
+			roles:   []types.Role{{Name: ViewerPlatformRole}},
+			wantErr: true,
+		},
+		"ShouldFailWithDuplicateName": {
+			roles: []types.Role{
+				{Name: ViewerPlatformRole, ClusterRole: ViewerClusterRole},
+				{Name: ViewerPlatformRole, ClusterRole: maintainerClusterRole},
+			},
+			wantErr: true,
+		},
+		"ShouldFailWithDuplicateClusterRole": {
+			roles: []types.Role{
+				{Name: ViewerPlatformRole, ClusterRole: ViewerClusterRole},
+				{Name: maintainerPlatformRole, ClusterRole: ViewerClusterRole},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			catalog, err := NewRoleCatalog(tc.roles)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.roles, catalog.Roles())
+			}
+		})
+	}
+}
+
+func TestRoleCatalog(t *testing.T) {
+	catalog, err := NewRoleCatalog([]types.Role{
+		{Name: ViewerPlatformRole, ClusterRole: ViewerClusterRole},
+		{Name: maintainerPlatformRole, ClusterRole: maintainerClusterRole},
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, catalog.IsRole(maintainerPlatformRole))
+	assert.False(t, catalog.IsRole(AdminPlatformRole))
+
+	clusterRole, err := catalog.ClusterRole(maintainerPlatformRole)
+	assert.NoError(t, err)
+	assert.Equal(t, maintainerClusterRole, clusterRole)
+	_, err = catalog.ClusterRole(AdminPlatformRole)
+	assert.EqualError(t, err, fmt.Sprintf(ErrUnknownRole, AdminPlatformRole, "viewer, maintainer"))
+
+	platformRole, ok := catalog.PlatformRole(ViewerClusterRole)
+	assert.True(t, ok)
+	assert.Equal(t, ViewerPlatformRole, platformRole)
+	_, ok = catalog.PlatformRole(AdminClusterRole)
+	assert.False(t, ok)
+
+	assert.Equal(t, maintainerPlatformRole, catalog.MostPrivileged(ViewerPlatformRole, maintainerPlatformRole))
+	assert.Equal(t, ViewerPlatformRole, catalog.MostPrivileged(ViewerPlatformRole, AdminPlatformRole))
+}
+
+func TestRoleConversions(t *testing.T) {
+	catalog, err := NewRoleCatalog([]types.Role{{Name: maintainerPlatformRole, ClusterRole: maintainerClusterRole}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, maintainerClusterRole, catalog.convertToK8sRoles(maintainerPlatformRole))
+	assert.Equal(t, maintainerPlatformRole, catalog.convertToPlatformRole(maintainerClusterRole))
+	assert.Equal(t, ViewerClusterRole, catalog.convertToPlatformRole(ViewerClusterRole))
+
+	assert.NoError(t, catalog.validateRole(maintainerPlatformRole))
+	assert.IsType(t, &customerrors.ValidationError{}, catalog.validateRole(ViewerPlatformRole))
+}
+
+func TestNewRoleCatalogFromEnv(t *testing.T) {
+	catalog, err := NewRoleCatalogFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultRoleCatalog(), catalog)
+	assert.Equal(t, ViewerPlatformRole, catalog.convertToPlatformRole(ViewerClusterRole))
+
+	_ = os.Setenv(envRoleCatalog, `{"roles": [{"name": "maintainer", "clusterRole": "capp-user-maintainer"}]}`)
+	catalog, err = NewRoleCatalogFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Role{{Name: maintainerPlatformRole, ClusterRole: maintainerClusterRole}}, catalog.Roles())
+
+	_ = os.Setenv(envRoleCatalog, `{"roles": [{"name": "maintainer", "role": "capp-user-maintainer"}]}`)
+	_, err = NewRoleCatalogFromEnv()
+	assert.Error(t, err)
+	_ = os.Unsetenv(envRoleCatalog)
+
+	path := filepath.Join(t.TempDir(), "roles.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("roles:\n- name: maintainer\n  description: Maintains Capps\n  clusterRole: capp-user-maintainer\n"), 0o600))
+	_ = os.Setenv(envRoleCatalogFile, path)
+	catalog, err = NewRoleCatalogFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Role{{Name: maintainerPlatformRole, Description: "Maintains Capps", ClusterRole: maintainerClusterRole}}, catalog.Roles())
+
+	_ = os.Setenv(envRoleCatalogFile, filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = NewRoleCatalogFromEnv()
+	assert.Error(t, err)
+	_ = os.Unsetenv(envRoleCatalogFile)
+}
@@ -38,8 +38,9 @@ type SecretController interface {
 	// GetSecret gets a specific secret from the specified namespace.
 	GetSecret(namespace, name string) (types.GetSecretResponse, error)
 
-	// UpdateSecret updates a specific secret in the specified namespace.
-	UpdateSecret(namespace, name string, request types.UpdateSecretRequest) (types.UpdateSecretResponse, error)
+	// UpdateSecret updates a specific secret in the specified namespace. If resourceVersion is set, the secret
+	// is only updated if it has that resource version.
+	UpdateSecret(namespace, name string, request types.UpdateSecretRequest, resourceVersion string) (types.UpdateSecretResponse, error)
 
 	// DeleteSecret deletes a specific secret in the specified namespace.
 	DeleteSecret(namespace, name string) (types.DeleteSecretResponse, error)
@@ -142,17 +143,18 @@ func (n *secretController) GetSecret(namespace, name string) (types.GetSecretRes
 
 	}
 	response := types.GetSecretResponse{
-		Id:         string(secret.UID),
-		Type:       string(secret.Type),
-		SecretName: secret.Name,
-		Data:       secretData,
+		Id:              string(secret.UID),
+		Type:            string(secret.Type),
+		SecretName:      secret.Name,
+		Data:            secretData,
+		ResourceVersion: secret.ResourceVersion,
 	}
 
 	return response, nil
 }
 
 // UpdateSecret updates a specific secret in the specified namespace.
-func (n *secretController) UpdateSecret(namespace, name string, request types.UpdateSecretRequest) (types.UpdateSecretResponse, error) {
+func (n *secretController) UpdateSecret(namespace, name string, request types.UpdateSecretRequest, resourceVersion string) (types.UpdateSecretResponse, error) {
 	n.logger.Debug(fmt.Sprintf("Trying to update an existing secret in %q namespace", namespace))
 
 	secret, err := n.client.CoreV1().Secrets(namespace).Get(n.ctx, name, metav1.GetOptions{})
@@ -160,11 +162,18 @@ func (n *secretController) UpdateSecret(namespace, name string, request types.Up
 		n.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetSecret, name), err.Error()))
 		return types.UpdateSecretResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetSecret, name), err)
 	}
+
+	if err := checkResourceVersion(name, secret.ResourceVersion, resourceVersion); err != nil {
+		return types.UpdateSecretResponse{}, err
+	}
 	secret.Data = convertKeyValueToByteMap(request.Data)
 
 	result, err := n.client.CoreV1().Secrets(namespace).Update(n.ctx, secret, metav1.UpdateOptions{})
 	if err != nil {
 		n.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotUpdateSecret, name), err.Error()))
+		if conflictErr := checkUpdateConflict(name, resourceVersion, err); conflictErr != nil {
+			return types.UpdateSecretResponse{}, conflictErr
+		}
 		return types.UpdateSecretResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotUpdateSecret, name), err)
 	}
 
@@ -175,10 +184,11 @@ func (n *secretController) UpdateSecret(namespace, name string, request types.Up
 	}
 
 	response := types.UpdateSecretResponse{
-		Type:          string(result.Type),
-		SecretName:    result.Name,
-		NamespaceName: result.Namespace,
-		Data:          secretData,
+		Type:            string(result.Type),
+		SecretName:      result.Name,
+		NamespaceName:   result.Namespace,
+		Data:            secretData,
+		ResourceVersion: result.ResourceVersion,
 	}
 
 	return response, nil
@@ -10,7 +10,10 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 	"strings"
 	"testing"
 )
@@ -243,9 +246,10 @@ func TestCreateSecret(t *testing.T) {
 func TestUpdateSecret(t *testing.T) {
 	namespaceName := testutils.SecretNamespace + "-update"
 	type requestParams struct {
-		request   types.UpdateSecretRequest
-		name      string
-		namespace string
+		request         types.UpdateSecretRequest
+		name            string
+		namespace       string
+		resourceVersion string
 	}
 	type want struct {
 		response    types.UpdateSecretResponse
@@ -277,6 +281,38 @@ func TestUpdateSecret(t *testing.T) {
 				errorStatus: metav1.StatusSuccess,
 			},
 		},
+		"ShouldFailUpdatingSecretWithStaleResourceVersion": {
+			requestParams: requestParams{
+				namespace: namespaceName,
+				name:      testutils.SecretName + "-2",
+				request: mocks.PrepareSecretRequestType(
+					[]types.KeyValue{
+						{Key: testutils.SecretDataKey, Value: testutils.SecretDataNewValue},
+					},
+				),
+				resourceVersion: testutils.StaleResourceVersion,
+			},
+			want: want{
+				response:    types.UpdateSecretResponse{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
+		"ShouldFailUpdatingSecretChangedWhileUpdatingWithResourceVersion": {
+			requestParams: requestParams{
+				namespace: namespaceName,
+				name:      testutils.SecretName + "-3",
+				request: mocks.PrepareSecretRequestType(
+					[]types.KeyValue{
+						{Key: testutils.SecretDataKey, Value: testutils.SecretDataNewValue},
+					},
+				),
+				resourceVersion: testutils.CreatedResourceVersion,
+			},
+			want: want{
+				response:    types.UpdateSecretResponse{},
+				errorStatus: customerrors.StatusReasonPreconditionFailed,
+			},
+		},
 		"ShouldFailUpdatingNonExistingSecret": {
 			requestParams: requestParams{
 				namespace: namespaceName,
@@ -298,9 +334,24 @@ func TestUpdateSecret(t *testing.T) {
 	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
 	createTestSecret(testutils.SecretName+"-1", namespaceName, utils.AddManagedLabel(map[string]string{}))
 	createTestSecret(testutils.SecretName+"-2", namespaceName, utils.AddManagedLabel(map[string]string{}))
+	createTestSecret(testutils.SecretName+"-3", namespaceName, utils.AddManagedLabel(map[string]string{}))
+
+	// The third secret has a resource version, and is changed by someone else whenever it is updated.
+	secret, err := fakeClient.CoreV1().Secrets(namespaceName).Get(mocks.GinContext(), testutils.SecretName+"-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+	secret.ResourceVersion = testutils.CreatedResourceVersion
+	_, err = fakeClient.CoreV1().Secrets(namespaceName).Update(mocks.GinContext(), secret, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	fakeClient.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.UpdateAction).GetObject().(*corev1.Secret).Name != testutils.SecretName+"-3" {
+			return false, nil, nil
+		}
+		return true, nil, errors.NewConflict(corev1.Resource("secrets"), testutils.SecretName+"-3", fmt.Errorf("the object has been modified"))
+	})
+
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			response, err := secretController.UpdateSecret(test.requestParams.namespace, test.requestParams.name, test.requestParams.request)
+			response, err := secretController.UpdateSecret(test.requestParams.namespace, test.requestParams.name, test.requestParams.request, test.requestParams.resourceVersion)
 			if test.want.errorStatus != metav1.StatusSuccess {
 				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
 
@@ -3,6 +3,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/dana-team/platform-backend/internal/utils"
 
@@ -11,54 +13,98 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils/pagination"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
-	tokenKey = "token"
+	tokenKey        = "token"
+	secretTypeField = "type"
 )
 
 const (
-	ErrCouldNotGetServiceAccount    = "Could not get ServiceAccount %q in namespace %q"
-	ErrCouldNotGetServiceAccounts   = "Could not list ServiceAccounts"
-	ErrNoTokenFound                 = "No token found for ServiceAccount %q"
-	ErrCouldNotCreateServiceAccount = "Could not create ServiceAccount %q in namespace %q"
-	ErrCouldNotDeleteServiceAccount = "Could not delete ServiceAccount %q in namespace %q"
+	// TokenMechanismDockercfg is the mechanism of tokens read from the legacy token secret which owns
+	// the dockercfg secret of the ServiceAccount, as created by older versions of OpenShift.
+	TokenMechanismDockercfg = "dockercfg"
+	// TokenMechanismSecret is the mechanism of tokens read from a kubernetes.io/service-account-token secret.
+	TokenMechanismSecret = "secret"
+	// TokenMechanismTokenRequest is the mechanism of tokens issued by a TokenRequest.
+	TokenMechanismTokenRequest = "tokenRequest"
 )
 
-// NewServiceAccountController creates a new instance of ServiceAccountController.
-func NewServiceAccountController(client kubernetes.Interface, context context.Context, logger *zap.Logger) ServiceAccountController {
+const (
+	serviceAccountRoleBindingSuffix = "platform-role"
+)
+
+const (
+	ErrCouldNotGetServiceAccount      = "Could not get ServiceAccount %q in namespace %q"
+	ErrCouldNotGetServiceAccounts     = "Could not list ServiceAccounts"
+	ErrCouldNotGetServiceAccountToken = "Could not get token of ServiceAccount %q in namespace %q"
+	ErrTokenRequestSecretNotFound     = "ServiceAccount %q in namespace %q has no token request secret to bind its token to, create a named token instead"
+	ErrCouldNotCreateServiceAccount   = "Could not create ServiceAccount %q in namespace %q"
+	ErrCouldNotDeleteServiceAccount   = "Could not delete ServiceAccount %q in namespace %q"
+	ErrCouldNotGetServiceAccountRole  = "Could not get role of ServiceAccount %q in namespace %q"
+	ErrCouldNotSetServiceAccountRole  = "Could not set role of ServiceAccount %q in namespace %q"
+	ErrServiceAccountRoleNotFound     = "ServiceAccount %q in namespace %q has no role"
+	ErrInvalidServiceAccountLabels    = "Invalid labels for ServiceAccount %q: %s"
+)
+
+// NewServiceAccountController creates a new instance of ServiceAccountController, whose tokens expire
+// according to the given global token expiration policy.
+func NewServiceAccountController(client kubernetes.Interface, context context.Context, logger *zap.Logger, tokenPolicy TokenExpirationPolicy, roleCatalog *RoleCatalog) ServiceAccountController {
 	return &serviceAccountController{
-		client: client,
-		ctx:    context,
-		logger: logger,
+		client:      client,
+		ctx:         context,
+		logger:      logger,
+		tokenPolicy: tokenPolicy,
+		roleCatalog: roleCatalog,
 	}
 }
 
 // serviceAccount implements the ServiceAccountController interface.
 type serviceAccountController struct {
-	client kubernetes.Interface
-	ctx    context.Context
-	logger *zap.Logger
+	client      kubernetes.Interface
+	ctx         context.Context
+	logger      *zap.Logger
+	tokenPolicy TokenExpirationPolicy
+	roleCatalog *RoleCatalog
 }
 
 // ServiceAccountController defines methods to interact with ServiceAccounts.
 type ServiceAccountController interface {
-	// GetServiceAccount retrieves a ServiceAccount by name and namespace, returning the ServiceAccount and any error encountered.
+	// GetServiceAccount retrieves the details of a ServiceAccount by name and namespace, including its platform role,
+	// image pull secrets and the metadata of its named tokens, returning the ServiceAccount and any error encountered.
 	GetServiceAccount(name, namespace string) (types.ServiceAccount, error)
 
-	// GetServiceAccountToken retrieves the token for a given ServiceAccount by name and namespace, returning the token and any error encountered.
+	// GetServiceAccountToken retrieves the token for a given ServiceAccount by name and namespace, returning the token,
+	// the mechanism which supplied it and any error encountered. If the ServiceAccount has no token secret, as is
+	// the case on Kubernetes 1.24+, a token bound to its token request secret is issued through a TokenRequest.
+	// No object is created.
 	GetServiceAccountToken(serviceAccountName, namespace string) (types.TokenResponse, error)
 
-	// CreateServiceAccount creates a new ServiceAccount with the given name and namespace.
-	CreateServiceAccount(name, namespace string) (types.ServiceAccount, error)
+	// CreateServiceAccount creates a new ServiceAccount with the given name and namespace, along with the description and labels of the request,
+	// and the token request secret which the tokens issued by GetServiceAccountToken are bound to.
+	CreateServiceAccount(name, namespace string, request types.CreateServiceAccountRequest) (types.ServiceAccount, error)
 
 	// DeleteServiceAccount deletes a ServiceAccount by name and namespace.
 	DeleteServiceAccount(name, namespace string) error
 
 	// GetServiceAccounts retrieves all ServiceAccounts in a given namespace, returning the ServiceAccounts and any error encountered.
 	GetServiceAccounts(namespace string, limit, page int) (types.ServiceAccountOutput, error)
+
+	// GetServiceAccountRole retrieves the platform role of a ServiceAccount.
+	GetServiceAccountRole(name, namespace string) (types.ServiceAccountRole, error)
+
+	// SetServiceAccountRole binds a ServiceAccount to the cluster role of a platform role, replacing its previous role.
+	SetServiceAccountRole(name, namespace string, role types.ServiceAccountRole) (types.ServiceAccountRole, error)
+
+	// DeleteServiceAccountRole removes the platform role of a ServiceAccount.
+	DeleteServiceAccountRole(name, namespace string) error
 }
 
 // ServiceAccountPaginator paginates through secrets in a specified namespace.
@@ -87,7 +133,29 @@ func (c *serviceAccountController) GetServiceAccount(name, namespace string) (ty
 		return types.ServiceAccount{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, name, namespace), err)
 	}
 
-	return types.ServiceAccount{Name: serviceAccount.Name}, nil
+	// The role and tokens are left out if they cannot be read, so that the ServiceAccount can still be retrieved.
+	roleBinding, err := c.getServiceAccountRoleBinding(name, namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		c.logger.Warn(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccountRole, name, namespace), err.Error()))
+	}
+
+	tokenSecrets, err := c.client.CoreV1().Secrets(namespace).List(c.ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf(utils.ServiceAccountTokenLabelSelector, utils.LabelValue(name)),
+	})
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotListTokens, name, namespace), err.Error()))
+		tokenSecrets = &corev1.SecretList{}
+	}
+
+	details := convertServiceAccount(*serviceAccount, c.roleCatalog.convertToPlatformRole(roleBinding.RoleRef.Name))
+	details.Labels = serviceAccount.Labels
+	details.Annotations = serviceAccount.Annotations
+	details.Tokens = convertSecretsToTokens(tokenSecrets.Items)
+	for _, imagePullSecret := range serviceAccount.ImagePullSecrets {
+		details.ImagePullSecrets = append(details.ImagePullSecrets, imagePullSecret.Name)
+	}
+
+	return details, nil
 }
 
 func (c *serviceAccountController) GetServiceAccountToken(serviceAccountName, namespace string) (types.TokenResponse, error) {
@@ -97,56 +165,151 @@ func (c *serviceAccountController) GetServiceAccountToken(serviceAccountName, na
 		return types.TokenResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err)
 	}
 
-	token, err := c.getServiceAccountToken(serviceAccount, namespace)
+	response, err := c.getServiceAccountToken(serviceAccount)
 	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccountToken, serviceAccountName, namespace), err.Error()))
 		return types.TokenResponse{}, err
 	}
 
-	return types.TokenResponse{
-		Token: token,
-	}, nil
+	c.logger.Debug(fmt.Sprintf("Got token of ServiceAccount %q in namespace %q through %s", serviceAccountName, namespace, response.Mechanism))
+	return response, nil
+}
+
+// getServiceAccountToken returns a token of the ServiceAccount from its legacy dockercfg token secret or from a
+// service-account-token secret, and otherwise issues one through a TokenRequest.
+func (c *serviceAccountController) getServiceAccountToken(serviceAccount *corev1.ServiceAccount) (types.TokenResponse, error) {
+	token, err := c.getDockercfgToken(serviceAccount)
+	if err != nil || token != "" {
+		return types.TokenResponse{Token: token, Mechanism: TokenMechanismDockercfg}, err
+	}
+
+	token, err = c.getSecretToken(serviceAccount)
+	if err != nil || token != "" {
+		return types.TokenResponse{Token: token, Mechanism: TokenMechanismSecret}, err
+	}
+
+	return c.requestToken(serviceAccount)
 }
 
-// getServiceAccountToken extracts the token from a ServiceAccount's associated secrets, returning the token and any error encountered.
-func (c *serviceAccountController) getServiceAccountToken(serviceAccount *corev1.ServiceAccount, namespace string) (string, error) {
+// getDockercfgToken extracts the token from the legacy token secret which owns the dockercfg secret of the ServiceAccount.
+// It returns an empty token if the ServiceAccount has no such secret.
+func (c *serviceAccountController) getDockercfgToken(serviceAccount *corev1.ServiceAccount) (string, error) {
 	for _, ref := range serviceAccount.Secrets {
-		secret, err := c.client.CoreV1().Secrets(namespace).Get(c.ctx, ref.Name, metav1.GetOptions{})
+		secret, err := c.getSecret(serviceAccount.Namespace, ref.Name)
 		if err != nil {
 			return "", err
 		}
+		if secret == nil || secret.Type != corev1.SecretTypeDockercfg {
+			continue
+		}
 
-		if secret.Type == corev1.SecretTypeDockercfg {
-			for _, ownerRef := range secret.OwnerReferences {
-				tokenSecret, err := c.client.CoreV1().Secrets(namespace).Get(c.ctx, ownerRef.Name, metav1.GetOptions{})
-				if err != nil {
-					return "", err
-				}
-				if tokenSecret.Type == corev1.SecretTypeServiceAccountToken {
-					return string(tokenSecret.Data[tokenKey]), nil
-				}
+		for _, ownerRef := range secret.OwnerReferences {
+			tokenSecret, err := c.getSecret(serviceAccount.Namespace, ownerRef.Name)
+			if err != nil {
+				return "", err
+			}
+			if tokenSecret != nil && tokenSecret.Type == corev1.SecretTypeServiceAccountToken {
+				return string(tokenSecret.Data[tokenKey]), nil
 			}
 		}
 	}
 
-	return "", customerrors.NewValidationError(fmt.Sprintf(ErrNoTokenFound, serviceAccount.Name))
+	return "", nil
 }
 
-func (c *serviceAccountController) CreateServiceAccount(name, namespace string) (types.ServiceAccount, error) {
-	serviceAccount := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels: map[string]string{
-				utils.ManagedLabel: utils.ManagedLabelValue,
-			},
-		},
+// getSecretToken returns the token of the first populated service-account-token secret of the ServiceAccount,
+// or an empty token if it has none.
+func (c *serviceAccountController) getSecretToken(serviceAccount *corev1.ServiceAccount) (string, error) {
+	secrets, err := c.client.CoreV1().Secrets(serviceAccount.Namespace).List(c.ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(secretTypeField, string(corev1.SecretTypeServiceAccountToken)).String(),
+	})
+	if err != nil {
+		return "", customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccountToken, serviceAccount.Name, serviceAccount.Namespace), err)
+	}
+
+	sort.Slice(secrets.Items, func(i, j int) bool {
+		return secrets.Items[i].Name < secrets.Items[j].Name
+	})
+	for _, secret := range secrets.Items {
+		if isServiceAccountTokenSecret(secret, serviceAccount.Name) && len(secret.Data[corev1.ServiceAccountTokenKey]) > 0 {
+			return string(secret.Data[corev1.ServiceAccountTokenKey]), nil
+		}
 	}
-	_, err := c.client.CoreV1().ServiceAccounts(namespace).Create(c.ctx, serviceAccount, metav1.CreateOptions{})
+
+	return "", nil
+}
+
+// requestToken issues a token for the ServiceAccount through a TokenRequest, which expires after the default
+// expiration of the token expiration policy of the namespace. The token is bound to the token request secret of
+// the ServiceAccount, so that revoking the tokens of the ServiceAccount revokes it as well.
+func (c *serviceAccountController) requestToken(serviceAccount *corev1.ServiceAccount) (types.TokenResponse, error) {
+	expirationSeconds, err := resolveTokenExpirationSeconds(c.ctx, c.client, c.tokenPolicy, serviceAccount.Namespace, "")
+	if err != nil {
+		return types.TokenResponse{}, err
+	}
+
+	tokenRequestSecret, err := c.getSecret(serviceAccount.Namespace, tokenRequestSecretName(serviceAccount.Name))
+	if err != nil {
+		return types.TokenResponse{}, err
+	} else if tokenRequestSecret == nil {
+		return types.TokenResponse{}, customerrors.NewNotFoundError(fmt.Sprintf(ErrTokenRequestSecretNotFound, serviceAccount.Name, serviceAccount.Namespace))
+	}
+
+	tokenRequest := prepareTokenRequest(serviceAccount.Name, serviceAccount.Namespace, tokenRequestSecret, &expirationSeconds)
+	createdRequest, err := c.client.CoreV1().ServiceAccounts(serviceAccount.Namespace).CreateToken(c.ctx, serviceAccount.Name, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return types.TokenResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequest, serviceAccount.Name, serviceAccount.Namespace), err)
+	}
+
+	response := types.TokenResponse{Token: createdRequest.Status.Token, Mechanism: TokenMechanismTokenRequest}
+	if !createdRequest.Status.ExpirationTimestamp.IsZero() {
+		response.ExpirationTimestamp = &createdRequest.Status.ExpirationTimestamp.Time
+	}
+
+	return response, nil
+}
+
+// getSecret returns the secret, or nil if it does not exist.
+func (c *serviceAccountController) getSecret(namespace, name string) (*corev1.Secret, error) {
+	secret, err := c.client.CoreV1().Secrets(namespace).Get(c.ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetSecret, name), err)
+	}
+
+	return secret, nil
+}
+
+// isServiceAccountTokenSecret returns true if the secret is a service-account-token secret of the ServiceAccount.
+// The type is checked as well, since not every client supports the type field selector.
+func isServiceAccountTokenSecret(secret corev1.Secret, serviceAccountName string) bool {
+	return secret.Type == corev1.SecretTypeServiceAccountToken && secret.Annotations[corev1.ServiceAccountNameKey] == serviceAccountName
+}
+
+func (c *serviceAccountController) CreateServiceAccount(name, namespace string, request types.CreateServiceAccountRequest) (types.ServiceAccount, error) {
+	if errs := metav1validation.ValidateLabels(request.Labels, field.NewPath("labels")); len(errs) > 0 {
+		return types.ServiceAccount{}, customerrors.NewValidationError(fmt.Sprintf(ErrInvalidServiceAccountLabels, name, errs.ToAggregate().Error()))
+	}
+
+	createdServiceAccount, err := c.client.CoreV1().ServiceAccounts(namespace).Create(c.ctx, prepareServiceAccount(name, namespace, request), metav1.CreateOptions{})
 	if err != nil {
 		return types.ServiceAccount{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateServiceAccount, name, namespace), err)
 	}
 
-	return types.ServiceAccount{Name: name}, nil
+	if _, err := c.client.CoreV1().Secrets(namespace).Create(c.ctx, prepareTokenRequestSecret(createdServiceAccount), metav1.CreateOptions{}); err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, name, namespace), err.Error()))
+		if err := c.client.CoreV1().ServiceAccounts(namespace).Delete(c.ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			c.logger.Warn(fmt.Sprintf("Could not delete ServiceAccount %q in namespace %q with error: %v", name, namespace, err))
+		}
+		return types.ServiceAccount{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, name, namespace), err)
+	}
+
+	details := convertServiceAccount(*createdServiceAccount, "")
+	details.Labels = createdServiceAccount.Labels
+	details.Annotations = createdServiceAccount.Annotations
+
+	return details, nil
 }
 
 func (c *serviceAccountController) DeleteServiceAccount(name, namespace string) error {
@@ -173,10 +336,189 @@ func (c *serviceAccountController) GetServiceAccounts(namespace string, limit, p
 		c.logger.Error(fmt.Sprintf("%v with error: %v", ErrCouldNotGetServiceAccounts, err))
 		return types.ServiceAccountOutput{}, customerrors.NewAPIError(ErrCouldNotGetServiceAccounts, err)
 	}
+	roles := c.getServiceAccountRoles(namespace)
 	for _, serviceAccount := range serviceAccounts {
-		serviceAccountOutput.ServiceAccounts = append(serviceAccountOutput.ServiceAccounts, serviceAccount.Name)
+		serviceAccountOutput.ServiceAccounts = append(serviceAccountOutput.ServiceAccounts, convertServiceAccount(serviceAccount, roles[serviceAccount.Name]))
 	}
 	serviceAccountOutput.Count = len(serviceAccounts)
 
 	return serviceAccountOutput, nil
 }
+
+func (c *serviceAccountController) GetServiceAccountRole(name, namespace string) (types.ServiceAccountRole, error) {
+	roleBinding, err := c.getServiceAccountRoleBinding(name, namespace)
+	if errors.IsNotFound(err) {
+		return types.ServiceAccountRole{}, customerrors.NewNotFoundError(fmt.Sprintf(ErrServiceAccountRoleNotFound, name, namespace))
+	} else if err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccountRole, name, namespace), err.Error()))
+		return types.ServiceAccountRole{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccountRole, name, namespace), err)
+	}
+
+	return types.ServiceAccountRole{Role: c.roleCatalog.convertToPlatformRole(roleBinding.RoleRef.Name)}, nil
+}
+
+func (c *serviceAccountController) SetServiceAccountRole(name, namespace string, role types.ServiceAccountRole) (types.ServiceAccountRole, error) {
+	c.logger.Debug(fmt.Sprintf("Trying to set role %q of ServiceAccount %q in namespace %q", role.Role, name, namespace))
+
+	if err := c.roleCatalog.validateRole(role.Role); err != nil {
+		return types.ServiceAccountRole{}, err
+	}
+
+	serviceAccount, err := c.client.CoreV1().ServiceAccounts(namespace).Get(c.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccount, name, namespace), err.Error()))
+		return types.ServiceAccountRole{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, name, namespace), err)
+	}
+
+	desired := prepareServiceAccountRoleBinding(serviceAccount, c.roleCatalog.convertToK8sRoles(role.Role))
+	original, err := c.getServiceAccountRoleBinding(name, namespace)
+	if err == nil && original.RoleRef.Name == desired.RoleRef.Name {
+		return role, nil
+	}
+
+	// The role ref of a RoleBinding cannot be updated, so the previous RoleBinding is replaced while a pending
+	// RoleBinding keeps the ServiceAccount bound, as done when the role of a member is changed.
+	if err == nil {
+		_, err = replaceRoleBinding(c.ctx, c.client, c.logger, original, desired, time.Now())
+	} else if errors.IsNotFound(err) {
+		_, err = c.client.RbacV1().RoleBindings(namespace).Create(c.ctx, desired, metav1.CreateOptions{})
+	}
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotSetServiceAccountRole, name, namespace), err.Error()))
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) || errors.IsNotFound(err) {
+			return types.ServiceAccountRole{}, customerrors.NewConflictError(fmt.Sprintf(ErrConcurrentRoleChange, name))
+		}
+		return types.ServiceAccountRole{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotSetServiceAccountRole, name, namespace), err)
+	}
+
+	c.logger.Debug(fmt.Sprintf("Set role %q of ServiceAccount %q in namespace %q successfully", role.Role, name, namespace))
+	return role, nil
+}
+
+func (c *serviceAccountController) DeleteServiceAccountRole(name, namespace string) error {
+	// The pending RoleBinding left by a change of role whose original RoleBinding could not be restored binds the
+	// ServiceAccount as well.
+	deletePendingRoleBinding(c.ctx, c.client, c.logger, namespace, pendingRoleBindingName(serviceAccountRoleBindingName(name)))
+	err := c.client.RbacV1().RoleBindings(namespace).Delete(c.ctx, serviceAccountRoleBindingName(name), metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return customerrors.NewNotFoundError(fmt.Sprintf(ErrServiceAccountRoleNotFound, name, namespace))
+	} else if err != nil {
+		message := fmt.Sprintf(ErrCouldNotDeleteRolebinding, serviceAccountRoleBindingName(name))
+		c.logger.Error(fmt.Sprintf("%v with error: %v", message, err.Error()))
+		return customerrors.NewAPIError(message, err)
+	}
+
+	return nil
+}
+
+// getServiceAccountRoleBinding returns the RoleBinding which grants the ServiceAccount its platform role.
+func (c *serviceAccountController) getServiceAccountRoleBinding(name, namespace string) (*rbacv1.RoleBinding, error) {
+	roleBinding, err := c.client.RbacV1().RoleBindings(namespace).Get(c.ctx, serviceAccountRoleBindingName(name), metav1.GetOptions{})
+	if err != nil {
+		return &rbacv1.RoleBinding{}, err
+	}
+	if roleBinding.Labels[utils.ServiceAccountRoleLabel] != utils.LabelValue(name) {
+		return &rbacv1.RoleBinding{}, errors.NewNotFound(rbacv1.Resource(roleBindingsResource), roleBinding.Name)
+	}
+
+	return roleBinding, nil
+}
+
+// getServiceAccountRoles returns the platform roles of the ServiceAccounts of the namespace by their names.
+// The roles are left out if the RoleBindings cannot be listed, so that ServiceAccounts can still be listed.
+func (c *serviceAccountController) getServiceAccountRoles(namespace string) map[string]string {
+	roles := map[string]string{}
+	roleBindings, err := c.client.RbacV1().RoleBindings(namespace).List(c.ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s,!%s", utils.ServiceAccountRoleLabel, utils.PendingRoleLabel),
+	})
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("Could not list roles of ServiceAccounts in namespace %q with error: %v", namespace, err))
+		return roles
+	}
+
+	// The label may only hold a hash of the name of the ServiceAccount, so the ServiceAccount is taken from the subject.
+	for _, roleBinding := range roleBindings.Items {
+		for _, subject := range roleBinding.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == namespace {
+				roles[subject.Name] = c.roleCatalog.convertToPlatformRole(roleBinding.RoleRef.Name)
+			}
+		}
+	}
+
+	return roles
+}
+
+// prepareServiceAccount returns a managed ServiceAccount with the labels of the request. The description
+// is kept in an annotation, since label values cannot hold free text.
+func prepareServiceAccount(name, namespace string, request types.CreateServiceAccountRequest) *corev1.ServiceAccount {
+	labels := map[string]string{}
+	for key, value := range request.Labels {
+		labels[key] = value
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    utils.AddManagedLabel(labels),
+		},
+	}
+	if request.Description != "" {
+		serviceAccount.Annotations = map[string]string{utils.ServiceAccountDescriptionAnnotation: request.Description}
+	}
+
+	return serviceAccount
+}
+
+// convertServiceAccount converts a ServiceAccount to its summary, which holds its description, platform role and creation time.
+func convertServiceAccount(serviceAccount corev1.ServiceAccount, role string) types.ServiceAccount {
+	summary := types.ServiceAccount{
+		Name:        serviceAccount.Name,
+		Description: serviceAccount.Annotations[utils.ServiceAccountDescriptionAnnotation],
+		Role:        role,
+	}
+	if !serviceAccount.CreationTimestamp.IsZero() {
+		summary.CreationTimestamp = &serviceAccount.CreationTimestamp.Time
+	}
+
+	return summary
+}
+
+// serviceAccountRoleBindingName returns the name of the RoleBinding which grants the ServiceAccount its platform role.
+func serviceAccountRoleBindingName(serviceAccountName string) string {
+	return fmt.Sprintf("%s-%s", serviceAccountName, serviceAccountRoleBindingSuffix)
+}
+
+// prepareServiceAccountRoleBinding returns a RoleBinding which binds the ServiceAccount to the ClusterRole.
+// The RoleBinding is owned by the ServiceAccount, so that it is deleted along with it.
+func prepareServiceAccountRoleBinding(serviceAccount *corev1.ServiceAccount, clusterRole string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountRoleBindingName(serviceAccount.Name),
+			Namespace: serviceAccount.Namespace,
+			Labels: utils.AddManagedLabel(map[string]string{
+				utils.ServiceAccountRoleLabel: utils.LabelValue(serviceAccount.Name),
+			}),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: corev1.SchemeGroupVersion.String(),
+					Kind:       serviceAccountKind,
+					Name:       serviceAccount.Name,
+					UID:        serviceAccount.UID,
+				},
+			},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount.Name,
+				Namespace: serviceAccount.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+			APIGroup: rbacv1.GroupName,
+		},
+	}
+}
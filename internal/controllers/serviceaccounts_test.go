@@ -1,14 +1,25 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestGetServiceAccount(t *testing.T) {
@@ -58,7 +69,7 @@ func TestGetServiceAccount(t *testing.T) {
 				mocks.CreateTestServiceAccount(fakeClient, namespaceName, test.args.existingServiceAccountName, "")
 			}
 			c := mocks.GinContext()
-			serviceAccountController := NewServiceAccountController(fakeClient, c, logger)
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
 			response, err := serviceAccountController.GetServiceAccount(test.args.name, test.args.namespace)
 
 			if test.want.error != "" {
@@ -79,6 +90,7 @@ func TestGetServiceAccountToken(t *testing.T) {
 	}
 	type want struct {
 		tokenResponse types.TokenResponse
+		boundSecret   string
 		error         string
 	}
 	cases := map[string]struct {
@@ -92,10 +104,45 @@ func TestGetServiceAccountToken(t *testing.T) {
 			},
 			want: want{
 				tokenResponse: types.TokenResponse{
-					Token: "value",
+					Token:     "value",
+					Mechanism: TokenMechanismDockercfg,
 				},
 			},
 		},
+		"ShouldSucceedGettingTokenFromServiceAccountTokenSecret": {
+			args: args{
+				namespace: namespaceName,
+				name:      testutils.ServiceAccountName + "-secret",
+			},
+			want: want{
+				tokenResponse: types.TokenResponse{
+					Token:     "secret-value",
+					Mechanism: TokenMechanismSecret,
+				},
+			},
+		},
+		"ShouldSucceedGettingTokenThroughTokenRequest": {
+			args: args{
+				namespace: namespaceName,
+				name:      testutils.ServiceAccountName + "-bound",
+			},
+			want: want{
+				// Since there is no TokenController running, the token of the TokenRequest is empty.
+				tokenResponse: types.TokenResponse{
+					Mechanism: TokenMechanismTokenRequest,
+				},
+				boundSecret: tokenRequestSecretName(testutils.ServiceAccountName + "-bound"),
+			},
+		},
+		"ShouldNotSucceedGettingTokenWithoutTokenRequestSecret": {
+			args: args{
+				namespace: namespaceName,
+				name:      testutils.ServiceAccountName + "-unbound",
+			},
+			want: want{
+				error: fmt.Sprintf(ErrTokenRequestSecretNotFound, testutils.ServiceAccountName+"-unbound", namespaceName),
+			},
+		},
 		"ShouldNotFindServiceAccountInNonExistingNamespace": {
 			args: args{
 				name:      testutils.CappName + testutils.NonExistentSuffix,
@@ -110,11 +157,22 @@ func TestGetServiceAccountToken(t *testing.T) {
 	setup()
 	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
 	mocks.CreateTestServiceAccountWithToken(fakeClient, namespaceName, testutils.ServiceAccountName, "token-secret", "value", "docker-cfg")
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName+"-secret", "")
+	tokenSecret := mocks.PrepareTokenSecret(testutils.ServiceAccountName+"-secret-token", namespaceName, "secret-value", testutils.ServiceAccountName+"-secret")
+	_, err := fakeClient.CoreV1().Secrets(namespaceName).Create(context.TODO(), &tokenSecret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName+"-unbound", "")
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName+"-bound", "")
+	boundServiceAccount, err := fakeClient.CoreV1().ServiceAccounts(namespaceName).Get(context.TODO(), testutils.ServiceAccountName+"-bound", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = fakeClient.CoreV1().Secrets(namespaceName).Create(context.TODO(), prepareTokenRequestSecret(boundServiceAccount), metav1.CreateOptions{})
+	assert.NoError(t, err)
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
+			fakeClient.ClearActions()
 			c := mocks.GinContext()
-			serviceAccountController := NewServiceAccountController(fakeClient, c, logger)
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
 			response, err := serviceAccountController.GetServiceAccountToken(test.args.name, test.args.namespace)
 
 			if test.want.error != "" {
@@ -123,6 +181,17 @@ func TestGetServiceAccountToken(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			assert.Equal(t, test.want.tokenResponse, response)
+
+			var boundSecret string
+			for _, action := range fakeClient.Actions() {
+				if !action.Matches("create", "serviceaccounts") || action.GetSubresource() != tokenKey {
+					assert.NotEqual(t, "create", action.GetVerb(), "no object should be created")
+					continue
+				}
+				tokenRequest := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+				boundSecret = tokenRequest.Spec.BoundObjectRef.Name
+			}
+			assert.Equal(t, test.want.boundSecret, boundSecret)
 		})
 	}
 }
@@ -133,6 +202,7 @@ func TestCreateServiceAccount(t *testing.T) {
 		namespace                  string
 		name                       string
 		existingServiceAccountName string
+		request                    types.CreateServiceAccountRequest
 	}
 	type want struct {
 		response types.ServiceAccount
@@ -149,8 +219,42 @@ func TestCreateServiceAccount(t *testing.T) {
 				existingServiceAccountName: "",
 			},
 			want: want{
-				response: types.ServiceAccount{Name: testutils.ServiceAccountName},
-				error:    "",
+				response: types.ServiceAccount{
+					Name:   testutils.ServiceAccountName,
+					Labels: map[string]string{testutils.ManagedLabel: testutils.ManagedLabelValue},
+				},
+				error: "",
+			},
+		},
+		"ShouldSucceedCreatingServiceAccountWithDescriptionAndLabels": {
+			args: args{
+				namespace: namespaceName,
+				name:      testutils.ServiceAccountName + "-described",
+				request: types.CreateServiceAccountRequest{
+					Description: testutils.ServiceAccountDescription,
+					Labels:      map[string]string{testutils.LabelKey: testutils.LabelValue, testutils.ManagedLabel: "false"},
+				},
+			},
+			want: want{
+				response: types.ServiceAccount{
+					Name:        testutils.ServiceAccountName + "-described",
+					Description: testutils.ServiceAccountDescription,
+					Labels:      map[string]string{testutils.LabelKey: testutils.LabelValue, testutils.ManagedLabel: testutils.ManagedLabelValue},
+					Annotations: map[string]string{utils.ServiceAccountDescriptionAnnotation: testutils.ServiceAccountDescription},
+				},
+			},
+		},
+		"ShouldFailWithInvalidLabels": {
+			args: args{
+				namespace: namespaceName,
+				name:      testutils.ServiceAccountName + "-invalid",
+				request: types.CreateServiceAccountRequest{
+					Labels: map[string]string{testutils.LabelKey: "invalid value"},
+				},
+			},
+			want: want{
+				response: types.ServiceAccount{},
+				error:    fmt.Sprintf(ErrInvalidServiceAccountLabels, testutils.ServiceAccountName+"-invalid", ""),
 			},
 		},
 		"ShouldHandleAlreadyExistingServiceAccount": {
@@ -175,13 +279,16 @@ func TestCreateServiceAccount(t *testing.T) {
 				mocks.CreateTestServiceAccount(fakeClient, namespaceName, test.args.existingServiceAccountName, "")
 			}
 			c := mocks.GinContext()
-			serviceAccountController := NewServiceAccountController(fakeClient, c, logger)
-			response, err := serviceAccountController.CreateServiceAccount(test.args.name, test.args.namespace)
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
+			response, err := serviceAccountController.CreateServiceAccount(test.args.name, test.args.namespace, test.args.request)
 
 			if test.want.error != "" {
 				assert.ErrorContains(t, err, test.want.error)
 			} else {
 				assert.NoError(t, err)
+				secret, err := fakeClient.CoreV1().Secrets(test.args.namespace).Get(context.TODO(), tokenRequestSecretName(test.args.name), metav1.GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, test.args.name, secret.OwnerReferences[0].Name)
 			}
 			assert.Equal(t, test.want.response, response)
 		})
@@ -228,7 +335,7 @@ func TestDeleteServiceAccount(t *testing.T) {
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
 			c := mocks.GinContext()
-			serviceAccountController := NewServiceAccountController(fakeClient, c, logger)
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
 			err := serviceAccountController.DeleteServiceAccount(test.args.name, test.args.namespace)
 
 			if test.want.error != "" {
@@ -239,3 +346,187 @@ func TestDeleteServiceAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestSetServiceAccountRole(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-setServiceAccountRole"
+	type args struct {
+		name         string
+		existingRole string
+		role         string
+	}
+	type want struct {
+		role       string
+		statusCode int
+		error      string
+	}
+	cases := map[string]struct {
+		args    args
+		prepare func(name string)
+		want    want
+	}{
+		"ShouldSucceedSettingRole": {
+			args: args{
+				name: testutils.ServiceAccountName + "-1",
+				role: testutils.AdminKey,
+			},
+			want: want{role: testutils.AdminKey},
+		},
+		"ShouldSucceedReplacingRole": {
+			args: args{
+				name:         testutils.ServiceAccountName + "-2",
+				existingRole: testutils.ViewerKey,
+				role:         testutils.ContributorKey,
+			},
+			want: want{role: testutils.ContributorKey},
+		},
+		"ShouldKeepSameRole": {
+			args: args{
+				name:         testutils.ServiceAccountName + "-3",
+				existingRole: testutils.ContributorKey,
+				role:         testutils.ContributorKey,
+			},
+			want: want{role: testutils.ContributorKey},
+		},
+		"ShouldSucceedSettingRoleOfServiceAccountWithLongName": {
+			args: args{
+				name:         testutils.ServiceAccountName + "-" + strings.Repeat("long", 20),
+				existingRole: testutils.AdminKey,
+				role:         testutils.ContributorKey,
+			},
+			want: want{role: testutils.ContributorKey},
+		},
+		"ShouldRestoreRoleWhenReplacementFails": {
+			args: args{
+				name:         testutils.ServiceAccountName + "-4",
+				existingRole: testutils.AdminKey,
+				role:         testutils.ContributorKey,
+			},
+			prepare: func(name string) {
+				failed := false
+				fakeClient.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					roleBinding := action.(k8stesting.CreateAction).GetObject().(*rbacv1.RoleBinding)
+					if failed || roleBinding.Name != serviceAccountRoleBindingName(name) {
+						return false, nil, nil
+					}
+					failed = true
+					return true, nil, k8serrors.NewForbidden(rbacv1.Resource("rolebindings"), roleBinding.Name, fmt.Errorf("denied"))
+				})
+			},
+			want: want{
+				role:       testutils.AdminKey,
+				statusCode: http.StatusForbidden,
+				error:      fmt.Sprintf(ErrCouldNotSetServiceAccountRole, testutils.ServiceAccountName+"-4", namespaceName),
+			},
+		},
+		"ShouldHandleNonExistingServiceAccount": {
+			args: args{
+				name: testutils.ServiceAccountName + testutils.NonExistentSuffix,
+				role: testutils.AdminKey,
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				error:      fmt.Sprintf(ErrCouldNotGetServiceAccount, testutils.ServiceAccountName+testutils.NonExistentSuffix, namespaceName),
+			},
+		},
+	}
+
+	setup()
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			if test.want.statusCode != http.StatusNotFound {
+				mocks.CreateTestServiceAccount(fakeClient, namespaceName, test.args.name, "")
+			}
+			if test.args.existingRole != "" {
+				mocks.CreateTestServiceAccountRoleBinding(fakeClient, namespaceName, test.args.name, test.args.existingRole)
+			}
+			if test.prepare != nil {
+				test.prepare(test.args.name)
+			}
+
+			c := mocks.GinContext()
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
+			response, err := serviceAccountController.SetServiceAccountRole(test.args.name, namespaceName, types.ServiceAccountRole{Role: test.args.role})
+
+			if test.want.error != "" {
+				assert.ErrorContains(t, err, test.want.error)
+				var errWithStatusCode customerrors.ErrorWithStatusCode
+				assert.ErrorAs(t, err, &errWithStatusCode)
+				assert.Equal(t, test.want.statusCode, errWithStatusCode.StatusCode())
+				if test.want.statusCode == http.StatusNotFound {
+					return
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want.role, response.Role)
+			}
+
+			serviceAccount, err := serviceAccountController.GetServiceAccount(test.args.name, namespaceName)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.role, serviceAccount.Role)
+
+			roleBinding, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, serviceAccountRoleBindingName(test.args.name), metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Empty(t, validation.IsValidLabelValue(roleBinding.Labels[utils.ServiceAccountRoleLabel]))
+
+			_, err = fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, pendingRoleBindingName(roleBinding.Name), metav1.GetOptions{})
+			assert.True(t, k8serrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestDeleteServiceAccountRole(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-deleteServiceAccountRole"
+	type args struct {
+		name         string
+		existingRole string
+	}
+	type want struct {
+		error string
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedDeletingRole": {
+			args: args{
+				name:         testutils.ServiceAccountName + "-1",
+				existingRole: testutils.AdminKey,
+			},
+		},
+		"ShouldHandleServiceAccountWithoutRole": {
+			args: args{
+				name: testutils.ServiceAccountName + "-2",
+			},
+			want: want{
+				error: fmt.Sprintf(ErrServiceAccountRoleNotFound, testutils.ServiceAccountName+"-2", namespaceName),
+			},
+		},
+	}
+
+	setup()
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			mocks.CreateTestServiceAccount(fakeClient, namespaceName, test.args.name, "")
+			if test.args.existingRole != "" {
+				mocks.CreateTestServiceAccountRoleBinding(fakeClient, namespaceName, test.args.name, test.args.existingRole)
+			}
+
+			c := mocks.GinContext()
+			serviceAccountController := NewServiceAccountController(fakeClient, c, logger, DefaultTokenExpirationPolicy(), DefaultRoleCatalog())
+			err := serviceAccountController.DeleteServiceAccountRole(test.args.name, namespaceName)
+
+			if test.want.error != "" {
+				assert.ErrorContains(t, err, test.want.error)
+				return
+			}
+			assert.NoError(t, err)
+
+			_, err = serviceAccountController.GetServiceAccountRole(test.args.name, namespaceName)
+			assert.ErrorContains(t, err, fmt.Sprintf(ErrServiceAccountRoleNotFound, test.args.name, namespaceName))
+		})
+	}
+}
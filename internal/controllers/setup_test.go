@@ -2,13 +2,16 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	dnsrecordv1alpha1 "github.com/dana-team/provider-dns/apis/record/v1alpha1"
+	userv1 "github.com/openshift/api/user/v1"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -18,6 +21,7 @@ import (
 
 	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
 	runtimeFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 var (
@@ -68,11 +72,25 @@ func createTestSecret(secretName string, namespace string, labels map[string]str
 	}
 }
 
+// conflictingClient returns a client which rejects the updates of the named object as conflicts,
+// as if the object was changed after it was read.
+func conflictingClient(name string) runtimeClient.WithWatch {
+	return interceptor.NewClient(dynClient, interceptor.Funcs{
+		Update: func(ctx context.Context, c runtimeClient.WithWatch, obj runtimeClient.Object, opts ...runtimeClient.UpdateOption) error {
+			if obj.GetName() == name {
+				return errors.NewConflict(cappv1alpha1.GroupVersion.WithResource("capps").GroupResource(), name, fmt.Errorf("the object has been modified"))
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	})
+}
+
 func setupScheme() *runtime.Scheme {
 	schema := scheme.Scheme
 	utilruntime.Must(cappv1alpha1.AddToScheme(schema))
 	utilruntime.Must(dnsrecordv1alpha1.AddToScheme(schema))
 	utilruntime.Must(clusterv1beta1.AddToScheme(schema))
+	utilruntime.Must(userv1.AddToScheme(schema))
 
 	return schema
 }
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	envTokenMinExpiration     = "TOKEN_MIN_EXPIRATION"
+	envTokenMaxExpiration     = "TOKEN_MAX_EXPIRATION"
+	envTokenDefaultExpiration = "TOKEN_DEFAULT_EXPIRATION"
+)
+
+const (
+	// defaultTokenMinExpiration is the minimum expiration the API server accepts for a TokenRequest.
+	defaultTokenMinExpiration     = 10 * time.Minute
+	defaultTokenMaxExpiration     = 30 * 24 * time.Hour
+	defaultTokenDefaultExpiration = 10 * time.Hour
+)
+
+const (
+	ErrInvalidExpirationSeconds     = "Invalid expiration seconds: %s"
+	ErrExpirationOutOfRange         = "Expiration of %d seconds is over the maximum, expiration must be between %d and %d seconds"
+	ErrInvalidTokenExpirationPolicy = "Invalid token expiration policy of namespace %q: %v"
+)
+
+// TokenExpirationPolicy bounds the expiration of the tokens created for serviceaccounts.
+// The global policy can be overridden per namespace through the token expiration policy annotation.
+type TokenExpirationPolicy struct {
+	Min     time.Duration
+	Max     time.Duration
+	Default time.Duration
+}
+
+// tokenExpirationPolicyOverride is the value of the token expiration policy annotation of a namespace,
+// for example {"min": "10m", "max": "24h", "default": "1h"}. Unset fields keep their global value.
+type tokenExpirationPolicyOverride struct {
+	Min     string `json:"min,omitempty"`
+	Max     string `json:"max,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// DefaultTokenExpirationPolicy returns the TokenExpirationPolicy used unless the TOKEN_*_EXPIRATION environment
+// variables are set.
+func DefaultTokenExpirationPolicy() TokenExpirationPolicy {
+	return TokenExpirationPolicy{Min: defaultTokenMinExpiration, Max: defaultTokenMaxExpiration, Default: defaultTokenDefaultExpiration}
+}
+
+// NewTokenExpirationPolicyFromEnv returns the global TokenExpirationPolicy, set by the TOKEN_*_EXPIRATION environment variables.
+func NewTokenExpirationPolicyFromEnv() (TokenExpirationPolicy, error) {
+	policy := TokenExpirationPolicy{}
+	var err error
+
+	if policy.Min, err = utils.GetEnvDuration(envTokenMinExpiration, defaultTokenMinExpiration); err != nil {
+		return TokenExpirationPolicy{}, err
+	}
+	if policy.Max, err = utils.GetEnvDuration(envTokenMaxExpiration, defaultTokenMaxExpiration); err != nil {
+		return TokenExpirationPolicy{}, err
+	}
+	if policy.Default, err = utils.GetEnvDuration(envTokenDefaultExpiration, defaultTokenDefaultExpiration); err != nil {
+		return TokenExpirationPolicy{}, err
+	}
+
+	return policy, policy.validate()
+}
+
+// WithOverride returns the policy with the fields set by the value of a token expiration policy annotation.
+func (p TokenExpirationPolicy) WithOverride(annotation string) (TokenExpirationPolicy, error) {
+	var override tokenExpirationPolicyOverride
+	if err := json.Unmarshal([]byte(annotation), &override); err != nil {
+		return TokenExpirationPolicy{}, err
+	}
+
+	fields := []struct {
+		value    string
+		duration *time.Duration
+	}{
+		{override.Min, &p.Min},
+		{override.Max, &p.Max},
+		{override.Default, &p.Default},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return TokenExpirationPolicy{}, err
+		}
+		*field.duration = duration
+	}
+
+	return p, p.validate()
+}
+
+// Resolve returns the effective expiration in seconds of a token for which the given expiration was requested.
+// The default expiration is used if none is requested, and expirations under the minimum are raised to it.
+func (p TokenExpirationPolicy) Resolve(expirationSeconds string) (int64, error) {
+	if expirationSeconds == "" {
+		return int64(p.Default.Seconds()), nil
+	}
+
+	seconds, err := strconv.ParseInt(expirationSeconds, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf(ErrInvalidExpirationSeconds, expirationSeconds)
+	}
+
+	minSeconds, maxSeconds := int64(p.Min.Seconds()), int64(p.Max.Seconds())
+	if seconds > maxSeconds {
+		return 0, fmt.Errorf(ErrExpirationOutOfRange, seconds, minSeconds, maxSeconds)
+	}
+
+	return max(seconds, minSeconds), nil
+}
+
+// resolveTokenExpirationSeconds returns the effective expiration in seconds of a token created in the namespace,
+// according to the global token expiration policy and the override of the namespace, if it has one.
+// Users who may create tokens are not necessarily allowed to get the namespace itself, in which case
+// its override cannot be read and the global token expiration policy applies.
+func resolveTokenExpirationSeconds(ctx context.Context, client kubernetes.Interface, policy TokenExpirationPolicy, namespace, expirationSeconds string) (int64, error) {
+	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if errors.IsForbidden(err) {
+		ns, err = &corev1.Namespace{}, nil
+	}
+	if err != nil {
+		return 0, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotFetchNamespace, namespace), err)
+	}
+
+	if annotation, ok := ns.Annotations[utils.TokenExpirationPolicyAnnotation]; ok {
+		policy, err = policy.WithOverride(annotation)
+		if err != nil {
+			return 0, customerrors.NewInternalServerError(fmt.Sprintf(ErrInvalidTokenExpirationPolicy, namespace, err))
+		}
+	}
+
+	seconds, err := policy.Resolve(expirationSeconds)
+	if err != nil {
+		return 0, customerrors.NewValidationError(err.Error())
+	}
+
+	return seconds, nil
+}
+
+// validate makes sure the default expiration lies between the positive minimum and maximum expirations.
+func (p TokenExpirationPolicy) validate() error {
+	if p.Min <= 0 {
+		return fmt.Errorf("minimum token expiration must be positive, got %v", p.Min)
+	}
+	if p.Max < p.Min {
+		return fmt.Errorf("maximum token expiration %v must not be less than the minimum %v", p.Max, p.Min)
+	}
+	if p.Default < p.Min || p.Default > p.Max {
+		return fmt.Errorf("default token expiration %v must be between %v and %v", p.Default, p.Min, p.Max)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTokenExpirationPolicyResolve(t *testing.T) {
+	policy := TokenExpirationPolicy{Min: 10 * time.Minute, Max: 24 * time.Hour, Default: time.Hour}
+
+	type want struct {
+		seconds int64
+		err     string
+	}
+	cases := map[string]struct {
+		expirationSeconds string
+		want              want
+	}{
+		"ShouldUseDefaultExpiration": {
+			expirationSeconds: "",
+			want:              want{seconds: 3600},
+		},
+		"ShouldKeepExpirationInRange": {
+			expirationSeconds: "7200",
+			want:              want{seconds: 7200},
+		},
+		"ShouldKeepMaximumExpiration": {
+			expirationSeconds: "86400",
+			want:              want{seconds: 86400},
+		},
+		"ShouldClampExpirationToMinimum": {
+			expirationSeconds: "60",
+			want:              want{seconds: 600},
+		},
+		"ShouldFailWithExpirationOverMaximum": {
+			expirationSeconds: "86401",
+			want:              want{err: fmt.Sprintf(ErrExpirationOutOfRange, 86401, 600, 86400)},
+		},
+		"ShouldFailWithInvalidExpiration": {
+			expirationSeconds: "soon",
+			want:              want{err: fmt.Sprintf(ErrInvalidExpirationSeconds, "soon")},
+		},
+		"ShouldFailWithNegativeExpiration": {
+			expirationSeconds: "-60",
+			want:              want{err: fmt.Sprintf(ErrInvalidExpirationSeconds, "-60")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			seconds, err := policy.Resolve(tc.expirationSeconds)
+			if tc.want.err != "" {
+				assert.EqualError(t, err, tc.want.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.seconds, seconds)
+			}
+		})
+	}
+}
+
+func TestTokenExpirationPolicyWithOverride(t *testing.T) {
+	policy := TokenExpirationPolicy{Min: 10 * time.Minute, Max: 24 * time.Hour, Default: time.Hour}
+
+	type want struct {
+		policy  TokenExpirationPolicy
+		wantErr bool
+	}
+	cases := map[string]struct {
+		annotation string
+		want       want
+	}{
+		"ShouldOverrideAllFields": {
+			annotation: `{"min": "1h", "max": "1h", "default": "1h"}`,
+			want:       want{policy: TokenExpirationPolicy{Min: time.Hour, Max: time.Hour, Default: time.Hour}},
+		},
+		"ShouldKeepUnsetFields": {
+			annotation: `{"max": "2h"}`,
+			want:       want{policy: TokenExpirationPolicy{Min: 10 * time.Minute, Max: 2 * time.Hour, Default: time.Hour}},
+		},
+		"ShouldFailWithInvalidJSON": {
+			annotation: `max: 2h`,
+			want:       want{wantErr: true},
+		},
+		"ShouldFailWithInvalidDuration": {
+			annotation: `{"max": "two hours"}`,
+			want:       want{wantErr: true},
+		},
+		"ShouldFailWithDefaultOverMaximum": {
+			annotation: `{"max": "30m"}`,
+			want:       want{wantErr: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			overridden, err := policy.WithOverride(tc.annotation)
+			if tc.want.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.policy, overridden)
+			}
+		})
+	}
+}
+
+func TestNewTokenExpirationPolicyFromEnv(t *testing.T) {
+	policy, err := NewTokenExpirationPolicyFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, TokenExpirationPolicy{Min: defaultTokenMinExpiration, Max: defaultTokenMaxExpiration, Default: defaultTokenDefaultExpiration}, policy)
+
+	_ = os.Setenv(envTokenDefaultExpiration, "1000h")
+	_, err = NewTokenExpirationPolicyFromEnv()
+	assert.Error(t, err)
+
+	_ = os.Setenv(envTokenMinExpiration, "1h")
+	_ = os.Setenv(envTokenMaxExpiration, "2h")
+	_ = os.Setenv(envTokenDefaultExpiration, "90m")
+	policy, err = NewTokenExpirationPolicyFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, TokenExpirationPolicy{Min: time.Hour, Max: 2 * time.Hour, Default: 90 * time.Minute}, policy)
+
+	_ = os.Unsetenv(envTokenMinExpiration)
+	_ = os.Unsetenv(envTokenMaxExpiration)
+	_ = os.Unsetenv(envTokenDefaultExpiration)
+}
+
+func TestResolveTokenExpirationSeconds(t *testing.T) {
+	policy := TokenExpirationPolicy{Min: 10 * time.Minute, Max: 24 * time.Hour, Default: time.Hour}
+	namespaceName := testutils.TokenNamespace + "-policy"
+
+	type want struct {
+		seconds     int64
+		errorStatus metav1.StatusReason
+	}
+	cases := map[string]struct {
+		namespace string
+		want      want
+	}{
+		"ShouldUseOverrideOfNamespace": {
+			namespace: namespaceName,
+			want:      want{seconds: int64((30 * time.Minute).Seconds()), errorStatus: metav1.StatusSuccess},
+		},
+		"ShouldUseGlobalPolicyWhenNamespaceIsForbidden": {
+			namespace: namespaceName + "-forbidden",
+			want:      want{seconds: int64(time.Hour.Seconds()), errorStatus: metav1.StatusSuccess},
+		},
+		"ShouldFailWithNonExistingNamespace": {
+			namespace: namespaceName + testutils.NonExistentSuffix,
+			want:      want{errorStatus: metav1.StatusReasonNotFound},
+		},
+	}
+
+	setup()
+	namespace := mocks.PrepareNamespace(namespaceName, map[string]string{})
+	namespace.Annotations = map[string]string{utils.TokenExpirationPolicyAnnotation: `{"default": "30m"}`}
+	_, err := fakeClient.CoreV1().Namespaces().Create(context.TODO(), &namespace, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	// The caller may create tokens in the forbidden namespace without being allowed to get the namespace itself.
+	fakeClient.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.GetAction).GetName() == namespaceName+"-forbidden" {
+			return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, namespaceName+"-forbidden", nil)
+		}
+		return false, nil, nil
+	})
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			seconds, err := resolveTokenExpirationSeconds(context.TODO(), fakeClient, policy, tc.namespace, "")
+			if tc.want.errorStatus != metav1.StatusSuccess {
+				assert.Equal(t, tc.want.errorStatus, err.(customerrors.ErrorWithStatusCode).StatusReason())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.seconds, seconds)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"sort"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 
@@ -15,16 +17,20 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils"
 	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 const (
 	secretKind         = "Secret"
 	tokenRequestSuffix = "token-request"
+	tokenSecretInfix   = "platform-token"
 	serviceAccountKind = "ServiceAccount"
-	defaultExpiration  = "36000"
+	tokenNameLength    = 5
 )
 
 const (
@@ -32,29 +38,44 @@ const (
 	ErrCouldNotGetTokenRequestSecret    = "Could not get token request secret for serviceaccount %q in namespace %q"
 	ErrCouldNotDeleteTokenRequestSecret = "Could not delete token request secret for serviceaccount %q in namespace %q"
 	ErrCouldNotCreateTokenRequest       = "Could not create token request for serviceaccount %q in namespace %q"
-	ErrInvalidExpirationSeconds         = "Invalid expiration seconds: %s"
+	ErrInvalidTokenName                 = "Invalid token name %q: %s"
+	ErrCouldNotListTokens               = "Could not list tokens for serviceaccount %q in namespace %q"
+	ErrCouldNotGetToken                 = "Could not get token %q of serviceaccount %q in namespace %q"
+	ErrCouldNotRevokeToken              = "Could not revoke token %q of serviceaccount %q in namespace %q"
 )
 
 type tokenController struct {
-	client kubernetes.Interface
-	ctx    context.Context
-	logger *zap.Logger
+	client      kubernetes.Interface
+	ctx         context.Context
+	logger      *zap.Logger
+	tokenPolicy TokenExpirationPolicy
 }
 
 type TokenController interface {
-	// RevokeToken revokes a token by name of the serviceaccount.
+	// RevokeToken revokes all the tokens of the serviceaccount.
 	RevokeToken(serviceAccountName, namespace string) error
 
-	// CreateToken creates a token for a serviceaccount.
-	CreateToken(serviceAccountName, namespace string, expirationSeconds string) (types.TokenRequestResponse, error)
+	// RevokeNamedToken revokes a single token of the serviceaccount by its name.
+	RevokeNamedToken(serviceAccountName, namespace, tokenName string) error
+
+	// CreateToken creates a named token for a serviceaccount, bound to a secret of its own.
+	CreateToken(serviceAccountName, namespace, creator string, query types.CreateTokenQuery) (types.TokenRequestResponse, error)
+
+	// GetTokens lists the metadata of the named tokens of a serviceaccount. Token values are never returned.
+	GetTokens(serviceAccountName, namespace string) (types.TokensOutput, error)
+
+	// GetKubeconfig creates a named token for a serviceaccount and returns a kubeconfig which authenticates with it.
+	GetKubeconfig(serviceAccountName, namespace, creator string, query types.KubeconfigQuery) ([]byte, error)
 }
 
-// NewTokenController creates a new token controller.
-func NewTokenController(client kubernetes.Interface, ctx context.Context, logger *zap.Logger) TokenController {
+// NewTokenController creates a new token controller, whose tokens expire according to the given global
+// token expiration policy.
+func NewTokenController(client kubernetes.Interface, ctx context.Context, logger *zap.Logger, tokenPolicy TokenExpirationPolicy) TokenController {
 	return &tokenController{
-		client: client,
-		ctx:    ctx,
-		logger: logger,
+		client:      client,
+		ctx:         ctx,
+		logger:      logger,
+		tokenPolicy: tokenPolicy,
 	}
 }
 
@@ -65,8 +86,23 @@ func (t *tokenController) RevokeToken(serviceAccountName, namespace string) erro
 		return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err)
 	}
 
-	_, err = t.client.CoreV1().Secrets(namespace).Get(t.ctx, fmt.Sprintf("%s-%s", serviceAccount.Name, tokenRequestSuffix), metav1.GetOptions{})
+	tokenSecrets, err := t.listTokenSecrets(serviceAccountName, namespace)
+	if err != nil {
+		return err
+	}
+	for _, secret := range tokenSecrets {
+		if err := t.client.CoreV1().Secrets(namespace).Delete(t.ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotRevokeToken, secret.Labels[utils.TokenNameLabel], serviceAccountName, namespace), err.Error()))
+			return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotRevokeToken, secret.Labels[utils.TokenNameLabel], serviceAccountName, namespace), err)
+		}
+	}
+
+	// Tokens created before named tokens were introduced are all bound to a single token request secret.
+	_, err = t.client.CoreV1().Secrets(namespace).Get(t.ctx, tokenRequestSecretName(serviceAccount.Name), metav1.GetOptions{})
 	if err != nil {
+		if errors.IsNotFound(err) && len(tokenSecrets) > 0 {
+			return nil
+		}
 		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err.Error()))
 		return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err)
 	}
@@ -77,65 +113,149 @@ func (t *tokenController) RevokeToken(serviceAccountName, namespace string) erro
 	return nil
 }
 
-func (t *tokenController) CreateToken(serviceAccountName, namespace string, expirationSeconds string) (types.TokenRequestResponse, error) {
-	tokenRequestSecret, err := t.client.CoreV1().Secrets(namespace).Get(t.ctx, fmt.Sprintf("%s-%s", serviceAccountName, tokenRequestSuffix), metav1.GetOptions{})
+func (t *tokenController) RevokeNamedToken(serviceAccountName, namespace, tokenName string) error {
+	secretName := tokenSecretName(serviceAccountName, tokenName)
+	secret, err := t.client.CoreV1().Secrets(namespace).Get(t.ctx, secretName, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
-			createdSecret, createSecretErr := CreateTokenRequestSecret(t.ctx, t.client, serviceAccountName, namespace, t.logger)
-			if createSecretErr != nil {
-				t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), createSecretErr.Error()))
-				return types.TokenRequestResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), createSecretErr)
-			}
-			tokenRequestSecret = createdSecret
-		} else {
-			t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err.Error()))
-			return types.TokenRequestResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err)
-		}
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetToken, tokenName, serviceAccountName, namespace), err.Error()))
+		return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetToken, tokenName, serviceAccountName, namespace), err)
+	}
+
+	// Make sure the secret is a token secret of the serviceaccount, and not a secret which happens to share its name.
+	if secret.Labels[utils.ServiceAccountTokenLabel] != utils.LabelValue(serviceAccountName) || secret.Labels[utils.TokenNameLabel] != tokenName {
+		return customerrors.NewNotFoundError(fmt.Sprintf(ErrCouldNotGetToken, tokenName, serviceAccountName, namespace))
 	}
-	expireIn, err := getExpirationSeconds(expirationSeconds)
+
+	if err := t.client.CoreV1().Secrets(namespace).Delete(t.ctx, secretName, metav1.DeleteOptions{}); err != nil {
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotRevokeToken, tokenName, serviceAccountName, namespace), err.Error()))
+		return customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotRevokeToken, tokenName, serviceAccountName, namespace), err)
+	}
+
+	t.logger.Debug(fmt.Sprintf("Revoked token %q of serviceaccount %q in namespace %q", tokenName, serviceAccountName, namespace))
+	return nil
+}
+
+func (t *tokenController) CreateToken(serviceAccountName, namespace, creator string, query types.CreateTokenQuery) (types.TokenRequestResponse, error) {
+	tokenName := query.Name
+	if tokenName == "" {
+		tokenName = utilrand.String(tokenNameLength)
+	} else if errs := validation.IsDNS1123Label(tokenName); len(errs) > 0 {
+		return types.TokenRequestResponse{}, customerrors.NewValidationError(fmt.Sprintf(ErrInvalidTokenName, tokenName, strings.Join(errs, ", ")))
+	}
+
+	expireIn, err := resolveTokenExpirationSeconds(t.ctx, t.client, t.tokenPolicy, namespace, query.ExpirationSeconds)
 	if err != nil {
 		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequest, serviceAccountName, namespace), err.Error()))
-		return types.TokenRequestResponse{}, customerrors.NewValidationError(err.Error())
+		return types.TokenRequestResponse{}, err
+	}
+
+	serviceAccount, err := t.client.CoreV1().ServiceAccounts(namespace).Get(t.ctx, serviceAccountName, metav1.GetOptions{})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err.Error()))
+		return types.TokenRequestResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err)
+	}
+
+	createdAt := time.Now()
+	tokenSecret, err := t.client.CoreV1().Secrets(namespace).Create(t.ctx, prepareTokenSecret(serviceAccount, tokenName, query.Description, creator, createdAt), metav1.CreateOptions{})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), err.Error()))
+		return types.TokenRequestResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), err)
 	}
 
-	tokenRequest := prepareTokenRequest(serviceAccountName, namespace, tokenRequestSecret, expireIn)
+	tokenRequest := prepareTokenRequest(serviceAccountName, namespace, tokenSecret, &expireIn)
 
 	createdRequest, err := t.client.CoreV1().ServiceAccounts(namespace).CreateToken(t.ctx, serviceAccountName, tokenRequest, metav1.CreateOptions{})
 	if err != nil {
 		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequest, serviceAccountName, namespace), err.Error()))
+		t.deleteTokenSecret(tokenSecret)
 		return types.TokenRequestResponse{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequest, serviceAccountName, namespace), err)
 	}
-	return types.TokenRequestResponse{Token: createdRequest.Status.Token, ExpirationTimestamp: createdRequest.Status.ExpirationTimestamp.Time}, nil
+
+	// The API server may change the requested expiration, so the expiration of the issued token is recorded.
+	if createdRequest.Spec.ExpirationSeconds != nil {
+		expireIn = *createdRequest.Spec.ExpirationSeconds
+	}
+	expiresAt := createdRequest.Status.ExpirationTimestamp.Time
+	if expiresAt.IsZero() {
+		expiresAt = createdAt.Add(time.Duration(expireIn) * time.Second)
+	}
+	tokenSecret.Annotations[utils.TokenExpirationTimestampAnnotation] = expiresAt.UTC().Format(time.RFC3339)
+	if _, err := t.client.CoreV1().Secrets(namespace).Update(t.ctx, tokenSecret, metav1.UpdateOptions{}); err != nil {
+		t.logger.Warn(fmt.Sprintf("Could not record the expiration of token %q of serviceaccount %q in namespace %q with error: %v", tokenName, serviceAccountName, namespace, err))
+	}
+
+	return types.TokenRequestResponse{
+		Name:                tokenName,
+		Token:               createdRequest.Status.Token,
+		ExpirationTimestamp: createdRequest.Status.ExpirationTimestamp.Time,
+		ExpirationSeconds:   expireIn,
+	}, nil
 }
 
-// CreateTokenRequestSecret creates a secret for the token request.
-// All token requests for a service account are bound to this secret.
-// When this secret is deleted, all tokens bound to it are revoked.
-func CreateTokenRequestSecret(ctx context.Context, client kubernetes.Interface, serviceAccountName, namespace string, log *zap.Logger) (*corev1.Secret, error) {
-	_, err := client.CoreV1().Secrets(namespace).Get(ctx, fmt.Sprintf("%s-%s", serviceAccountName, tokenRequestSuffix), metav1.GetOptions{})
+func (t *tokenController) GetTokens(serviceAccountName, namespace string) (types.TokensOutput, error) {
+	if _, err := t.client.CoreV1().ServiceAccounts(namespace).Get(t.ctx, serviceAccountName, metav1.GetOptions{}); err != nil {
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err.Error()))
+		return types.TokensOutput{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err)
+	}
+
+	tokenSecrets, err := t.listTokenSecrets(serviceAccountName, namespace)
 	if err != nil {
-		if !errors.IsNotFound(err) {
-			log.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err.Error()))
-			return nil, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetTokenRequestSecret, serviceAccountName, namespace), err)
-		}
+		return types.TokensOutput{}, err
+	}
+
+	tokens := convertSecretsToTokens(tokenSecrets)
+	return types.TokensOutput{Tokens: tokens, ListMetadata: types.ListMetadata{Count: len(tokens)}}, nil
+}
+
+func (t *tokenController) GetKubeconfig(serviceAccountName, namespace, creator string, query types.KubeconfigQuery) ([]byte, error) {
+	if err := validateClusterNames(query.Clusters); err != nil {
+		return nil, err
+	}
+
+	server, err := t.getKubeconfigServer(namespace)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetKubeconfig, serviceAccountName, namespace), err.Error()))
+		return nil, err
+	}
+
+	token, err := t.CreateToken(serviceAccountName, namespace, creator, query.CreateTokenQuery)
+	if err != nil {
+		return nil, err
 	}
-	serviceAccount, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, serviceAccountName, metav1.GetOptions{})
+
+	kubeconfig, err := clientcmd.Write(*prepareKubeconfig(server, serviceAccountName, namespace, token.Token, query.Clusters))
 	if err != nil {
-		log.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err.Error()))
-		return nil, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetServiceAccount, serviceAccountName, namespace), err)
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotGetKubeconfig, serviceAccountName, namespace), err.Error()))
+		return nil, customerrors.NewInternalServerError(fmt.Sprintf(ErrCouldNotGetKubeconfig, serviceAccountName, namespace))
 	}
-	serviceAccountSecret := prepareTokenRequestSecret(serviceAccount)
-	requestSecret, err := client.CoreV1().Secrets(namespace).Create(ctx, serviceAccountSecret, metav1.CreateOptions{})
+
+	t.logger.Debug(fmt.Sprintf("Created kubeconfig with token %q for serviceaccount %q in namespace %q", token.Name, serviceAccountName, namespace))
+	return kubeconfig, nil
+}
+
+// listTokenSecrets lists the secrets the named tokens of the serviceaccount are bound to.
+func (t *tokenController) listTokenSecrets(serviceAccountName, namespace string) ([]corev1.Secret, error) {
+	secrets, err := t.client.CoreV1().Secrets(namespace).List(t.ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf(utils.ServiceAccountTokenLabelSelector, utils.LabelValue(serviceAccountName)),
+	})
 	if err != nil {
-		log.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), err.Error()))
-		return nil, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotCreateTokenRequestSecret, serviceAccountName, namespace), err)
+		t.logger.Error(fmt.Sprintf("%s with error: %s", fmt.Sprintf(ErrCouldNotListTokens, serviceAccountName, namespace), err.Error()))
+		return nil, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotListTokens, serviceAccountName, namespace), err)
+	}
+
+	return secrets.Items, nil
+}
+
+// deleteTokenSecret deletes the secret of a token which could not be issued.
+func (t *tokenController) deleteTokenSecret(secret *corev1.Secret) {
+	if err := t.client.CoreV1().Secrets(secret.Namespace).Delete(t.ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		t.logger.Warn(fmt.Sprintf("Could not delete token secret %q in namespace %q with error: %v", secret.Name, secret.Namespace, err))
 	}
-	return requestSecret, nil
 }
 
 // DeleteTokenRequestSecret deletes the secret for token requests.
 func DeleteTokenRequestSecret(ctx context.Context, client kubernetes.Interface, serviceAccountName, namespace string) error {
-	err := client.CoreV1().Secrets(namespace).Delete(ctx, fmt.Sprintf("%s-%s", serviceAccountName, tokenRequestSuffix), metav1.DeleteOptions{})
+	err := client.CoreV1().Secrets(namespace).Delete(ctx, tokenRequestSecretName(serviceAccountName), metav1.DeleteOptions{})
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return err
@@ -144,20 +264,6 @@ func DeleteTokenRequestSecret(ctx context.Context, client kubernetes.Interface,
 	return nil
 }
 
-// getExpirationSeconds returns the expiration seconds for a token as a pointer.
-func getExpirationSeconds(expirationSeconds string) (*int64, error) {
-	if expirationSeconds == "" {
-		expirationSeconds = defaultExpiration
-	}
-	expireIn, err := strconv.Atoi(expirationSeconds)
-	if err != nil {
-		return nil, errors.NewBadRequest(fmt.Sprintf(ErrInvalidExpirationSeconds, expirationSeconds))
-	}
-	seconds := new(int64)
-	*seconds = int64(expireIn)
-	return seconds, nil
-}
-
 // prepareTokenRequest creates a token request object.
 func prepareTokenRequest(name, namespace string, tokenRequestSecret *corev1.Secret, expirationSeconds *int64) *authenticationv1.TokenRequest {
 	return &authenticationv1.TokenRequest{
@@ -177,11 +283,42 @@ func prepareTokenRequest(name, namespace string, tokenRequestSecret *corev1.Secr
 	}
 }
 
-// prepareTokenRequestSecret creates a secret for the token requests.
+// prepareTokenSecret creates the secret a single named token is bound to. When this secret is deleted,
+// the token is revoked. The description and creator are kept in annotations, since label values cannot hold free text.
+func prepareTokenSecret(serviceAccount *corev1.ServiceAccount, tokenName, description, creator string, createdAt time.Time) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tokenSecretName(serviceAccount.Name, tokenName),
+			Namespace: serviceAccount.Namespace,
+			Labels: map[string]string{
+				utils.ManagedLabel:             utils.ManagedLabelValue,
+				utils.ServiceAccountTokenLabel: utils.LabelValue(serviceAccount.Name),
+				utils.TokenNameLabel:           tokenName,
+			},
+			Annotations: map[string]string{
+				utils.TokenDescriptionAnnotation:       description,
+				utils.TokenCreatorAnnotation:           creator,
+				utils.TokenCreationTimestampAnnotation: createdAt.UTC().Format(time.RFC3339),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: corev1.SchemeGroupVersion.String(),
+					Kind:       serviceAccountKind,
+					Name:       serviceAccount.Name,
+					UID:        serviceAccount.UID,
+				},
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+// prepareTokenRequestSecret creates the token request secret of the serviceaccount, which the tokens issued without
+// a name are bound to. It is owned by the serviceaccount, and deleting it revokes these tokens.
 func prepareTokenRequestSecret(serviceAccount *corev1.ServiceAccount) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", serviceAccount.Name, tokenRequestSuffix),
+			Name:      tokenRequestSecretName(serviceAccount.Name),
 			Namespace: serviceAccount.Namespace,
 			Labels: map[string]string{
 				utils.ManagedLabel: utils.ManagedLabelValue,
@@ -198,3 +335,40 @@ func prepareTokenRequestSecret(serviceAccount *corev1.ServiceAccount) *corev1.Se
 		Type: corev1.SecretTypeOpaque,
 	}
 }
+
+// tokenRequestSecretName returns the name of the token request secret of the serviceaccount.
+func tokenRequestSecretName(serviceAccountName string) string {
+	return fmt.Sprintf("%s-%s", serviceAccountName, tokenRequestSuffix)
+}
+
+// tokenSecretName returns the name of the secret a named token of the serviceaccount is bound to.
+func tokenSecretName(serviceAccountName, tokenName string) string {
+	return fmt.Sprintf("%s-%s-%s", serviceAccountName, tokenSecretInfix, tokenName)
+}
+
+// convertSecretsToTokens converts the secrets of named tokens to the metadata of the tokens, sorted by name.
+func convertSecretsToTokens(secrets []corev1.Secret) []types.Token {
+	tokens := make([]types.Token, 0, len(secrets))
+	for _, secret := range secrets {
+		tokens = append(tokens, convertSecretToToken(secret))
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+
+	return tokens
+}
+
+// convertSecretToToken converts the secret of a named token to the metadata of the token.
+func convertSecretToToken(secret corev1.Secret) types.Token {
+	createdAt, _ := time.Parse(time.RFC3339, secret.Annotations[utils.TokenCreationTimestampAnnotation])
+	expiresAt, _ := time.Parse(time.RFC3339, secret.Annotations[utils.TokenExpirationTimestampAnnotation])
+
+	return types.Token{
+		Name:                secret.Labels[utils.TokenNameLabel],
+		Description:         secret.Annotations[utils.TokenDescriptionAnnotation],
+		Creator:             secret.Annotations[utils.TokenCreatorAnnotation],
+		CreationTimestamp:   createdAt,
+		ExpirationTimestamp: expiresAt,
+	}
+}
@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dana-team/platform-backend/internal/types"
 
@@ -12,15 +16,18 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestCreateToken(t *testing.T) {
 	namespaceName := testutils.TokenNamespace + "-create-token"
+	overriddenNamespaceName := namespaceName + "-policy"
 	type requestParams struct {
 		name                 string
 		namespace            string
 		createServiceAccount bool
-		expiration           string
+		existingTokenName    string
+		query                types.CreateTokenQuery
 	}
 	type want struct {
 		response    types.TokenRequestResponse
@@ -35,19 +42,110 @@ func TestCreateToken(t *testing.T) {
 				name:                 testutils.ServiceAccountName,
 				namespace:            namespaceName,
 				createServiceAccount: true,
-				expiration:           "3600",
+				query:                types.CreateTokenQuery{ExpirationSeconds: "3600"},
 			},
 			want: want{
-				response:    types.TokenRequestResponse{Token: ""},
+				response:    types.TokenRequestResponse{Token: "", ExpirationSeconds: 3600},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedCreatingTokenWithDefaultExpiration": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-default-expiration",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+			},
+			want: want{
+				response:    types.TokenRequestResponse{Token: "", ExpirationSeconds: 36000},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedCreatingTokenWithExpirationUnderMinimum": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-short-expiration",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+				query:                types.CreateTokenQuery{ExpirationSeconds: "60"},
+			},
+			want: want{
+				response:    types.TokenRequestResponse{Token: "", ExpirationSeconds: 600},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedCreatingTokenWithNamespacePolicy": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName,
+				namespace:            overriddenNamespaceName,
+				createServiceAccount: true,
+			},
+			want: want{
+				response:    types.TokenRequestResponse{Token: "", ExpirationSeconds: 3600},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailCreatingTokenWithExpirationOverNamespacePolicy": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-over-policy",
+				namespace:            overriddenNamespaceName,
+				createServiceAccount: true,
+				query:                types.CreateTokenQuery{ExpirationSeconds: "36000"},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
+		"ShouldFailCreatingTokenWithExpirationOverMaximum": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-long-expiration",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+				query:                types.CreateTokenQuery{ExpirationSeconds: "31536000"},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
+		"ShouldSucceedCreatingNamedToken": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-named",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+				query:                types.CreateTokenQuery{ExpirationSeconds: "3600", Name: testutils.NamedTokenName, Description: testutils.NamedTokenDescription},
+			},
+			want: want{
+				response:    types.TokenRequestResponse{Name: testutils.NamedTokenName, Token: "", ExpirationSeconds: 3600},
 				errorStatus: metav1.StatusSuccess,
 			},
 		},
+		"ShouldFailCreatingNamedTokenWithExistingName": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-existing",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+				existingTokenName:    testutils.NamedTokenName,
+				query:                types.CreateTokenQuery{Name: testutils.NamedTokenName},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonAlreadyExists,
+			},
+		},
+		"ShouldFailCreatingTokenWithInvalidName": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-invalid-name",
+				namespace:            namespaceName,
+				createServiceAccount: true,
+				query:                types.CreateTokenQuery{Name: "Invalid_Name"},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
 		"ShouldFailCreatingTokenWithoutServiceAccount": {
 			request: requestParams{
 				name:                 testutils.ServiceAccountName + testutils.NonExistentSuffix,
 				namespace:            namespaceName,
 				createServiceAccount: false,
-				expiration:           "3600",
+				query:                types.CreateTokenQuery{ExpirationSeconds: "3600"},
 			},
 			want: want{
 				response:    types.TokenRequestResponse{Token: ""},
@@ -59,7 +157,7 @@ func TestCreateToken(t *testing.T) {
 				name:                 testutils.ServiceAccountName + "-invalid-expiration",
 				namespace:            namespaceName,
 				createServiceAccount: true,
-				expiration:           testutils.TestName,
+				query:                types.CreateTokenQuery{ExpirationSeconds: testutils.TestName},
 			},
 			want: want{
 				response:    types.TokenRequestResponse{Token: ""},
@@ -68,25 +166,213 @@ func TestCreateToken(t *testing.T) {
 		},
 	}
 	setup()
-	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger)
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, DefaultTokenExpirationPolicy())
 	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	overriddenNamespace := mocks.PrepareNamespace(overriddenNamespaceName, map[string]string{})
+	overriddenNamespace.Annotations = map[string]string{utils.TokenExpirationPolicyAnnotation: `{"max": "2h", "default": "1h"}`}
+	_, err := fakeClient.CoreV1().Namespaces().Create(context.TODO(), &overriddenNamespace, metav1.CreateOptions{})
+	assert.NoError(t, err)
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			if tc.request.createServiceAccount {
 				mocks.CreateTestServiceAccount(fakeClient, tc.request.namespace, tc.request.name, "")
 			}
-			response, err := tokenController.CreateToken(tc.request.name, tc.request.namespace, tc.request.expiration)
+			if tc.request.existingTokenName != "" {
+				mocks.CreateTestNamedTokenSecret(fakeClient, tc.request.name, tc.request.namespace, tc.request.existingTokenName)
+			}
+			response, err := tokenController.CreateToken(tc.request.name, tc.request.namespace, testutils.NamedTokenCreator, tc.request.query)
 			if tc.want.errorStatus != metav1.StatusSuccess {
 				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
 
 				assert.Equal(t, tc.want.errorStatus, reason)
 			} else {
 				assert.NoError(t, err)
+				if tc.want.response.Name == "" {
+					assert.NotEmpty(t, response.Name)
+					tc.want.response.Name = response.Name
+				}
 				// Since there is no TokenController running, no token is created so this is not worth much.
 				// TODO: Rework this when https://github.com/kubernetes-sigs/controller-runtime/pull/2969 is released.
 				assert.Equal(t, tc.want.response, response)
 
+				secret, err := fakeClient.CoreV1().Secrets(tc.request.namespace).Get(context.TODO(),
+					fmt.Sprintf("%s-%s-%s", tc.request.name, testutils.NamedTokenInfix, response.Name), metav1.GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, utils.LabelValue(tc.request.name), secret.Labels[utils.ServiceAccountTokenLabel])
+				assert.Equal(t, tc.request.query.Description, secret.Annotations[utils.TokenDescriptionAnnotation])
+				assert.Equal(t, testutils.NamedTokenCreator, secret.Annotations[utils.TokenCreatorAnnotation])
+				assert.NotEmpty(t, secret.Annotations[utils.TokenExpirationTimestampAnnotation])
+			}
+		})
+	}
+}
+
+func TestCreateTokenWithTokenPolicy(t *testing.T) {
+	namespaceName := testutils.TokenNamespace + "-token-policy"
+	t.Setenv(envTokenDefaultExpiration, "5h")
+
+	setup()
+	policy := TokenExpirationPolicy{Min: 10 * time.Minute, Max: time.Hour, Default: 30 * time.Minute}
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, policy)
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName, "")
+
+	// The policy the controller was created with is used rather than the environment.
+	response, err := tokenController.CreateToken(testutils.ServiceAccountName, namespaceName, testutils.NamedTokenCreator, types.CreateTokenQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1800), response.ExpirationSeconds)
+
+	_, err = tokenController.CreateToken(testutils.ServiceAccountName, namespaceName, testutils.NamedTokenCreator, types.CreateTokenQuery{ExpirationSeconds: "7200"})
+	assert.Equal(t, metav1.StatusReasonBadRequest, err.(customerrors.ErrorWithStatusCode).StatusReason())
+}
+
+func TestGetTokens(t *testing.T) {
+	namespaceName := testutils.TokenNamespace + "-get-tokens"
+	type requestParams struct {
+		name                 string
+		createServiceAccount bool
+		tokenNames           []string
+	}
+	type want struct {
+		response    types.TokensOutput
+		errorStatus metav1.StatusReason
+	}
+	createdAt, _ := time.Parse(time.RFC3339, testutils.NamedTokenCreatedAt)
+	expiresAt, _ := time.Parse(time.RFC3339, testutils.NamedTokenExpiresAt)
+	token := func(name string) types.Token {
+		return types.Token{
+			Name:                name,
+			Description:         testutils.NamedTokenDescription,
+			Creator:             testutils.NamedTokenCreator,
+			CreationTimestamp:   createdAt,
+			ExpirationTimestamp: expiresAt,
+		}
+	}
+	cases := map[string]struct {
+		request requestParams
+		want    want
+	}{
+		"ShouldSucceedGettingTokens": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName,
+				createServiceAccount: true,
+				tokenNames:           []string{"deploy", testutils.NamedTokenName},
+			},
+			want: want{
+				response: types.TokensOutput{
+					Tokens:       []types.Token{token(testutils.NamedTokenName), token("deploy")},
+					ListMetadata: types.ListMetadata{Count: 2},
+				},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedGettingNoTokens": {
+			request: requestParams{
+				name:                 testutils.ServiceAccountName + "-no-tokens",
+				createServiceAccount: true,
+			},
+			want: want{
+				response:    types.TokensOutput{Tokens: []types.Token{}},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailGettingTokensWithoutServiceAccount": {
+			request: requestParams{
+				name: testutils.ServiceAccountName + testutils.NonExistentSuffix,
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonNotFound,
+			},
+		},
+	}
+	setup()
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, DefaultTokenExpirationPolicy())
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tc.request.createServiceAccount {
+				mocks.CreateTestServiceAccount(fakeClient, namespaceName, tc.request.name, "")
+			}
+			for _, tokenName := range tc.request.tokenNames {
+				mocks.CreateTestNamedTokenSecret(fakeClient, tc.request.name, namespaceName, tokenName)
+			}
+
+			response, err := tokenController.GetTokens(tc.request.name, namespaceName)
+			if tc.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, tc.want.errorStatus, reason)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.response, response)
+			}
+		})
+	}
+}
+
+func TestRevokeNamedToken(t *testing.T) {
+	namespaceName := testutils.TokenNamespace + "-revoke-named-token"
+	type requestParams struct {
+		name      string
+		tokenName string
+	}
+	type want struct {
+		errorStatus metav1.StatusReason
+	}
+	cases := map[string]struct {
+		requestParams requestParams
+		want          want
+	}{
+		"ShouldSucceedRevokingNamedToken": {
+			requestParams: requestParams{
+				name:      testutils.ServiceAccountName,
+				tokenName: testutils.NamedTokenName,
+			},
+			want: want{
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailRevokingNonExistentToken": {
+			requestParams: requestParams{
+				name:      testutils.ServiceAccountName,
+				tokenName: testutils.NamedTokenName + testutils.NonExistentSuffix,
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonNotFound,
+			},
+		},
+		"ShouldFailRevokingSecretWhichIsNotAToken": {
+			requestParams: requestParams{
+				name:      testutils.ServiceAccountName,
+				tokenName: "not-a-token",
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonNotFound,
+			},
+		},
+	}
+	setup()
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, DefaultTokenExpirationPolicy())
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName, "")
+	mocks.CreateTestNamedTokenSecret(fakeClient, testutils.ServiceAccountName, namespaceName, testutils.NamedTokenName)
+	mocks.CreateTestNamedTokenSecret(fakeClient, testutils.ServiceAccountName, namespaceName, "other")
+	createTestSecret(fmt.Sprintf("%s-%s-%s", testutils.ServiceAccountName, testutils.NamedTokenInfix, "not-a-token"), namespaceName, utils.AddManagedLabel(map[string]string{}))
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tokenController.RevokeNamedToken(tc.requestParams.name, namespaceName, tc.requestParams.tokenName)
+			if tc.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+				assert.Equal(t, tc.want.errorStatus, reason)
+			} else {
+				assert.NoError(t, err)
+
+				tokens, err := tokenController.GetTokens(tc.requestParams.name, namespaceName)
+				assert.NoError(t, err)
+				assert.Len(t, tokens.Tokens, 1)
+				assert.Equal(t, "other", tokens.Tokens[0].Name)
 			}
 		})
 	}
@@ -142,7 +428,7 @@ func TestRevokeToken(t *testing.T) {
 		},
 	}
 	setup()
-	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger)
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, DefaultTokenExpirationPolicy())
 	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -162,3 +448,171 @@ func TestRevokeToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGetKubeconfig(t *testing.T) {
+	namespaceName := testutils.TokenNamespace + "-kubeconfig"
+	namespaceWithoutCAName := namespaceName + "-no-ca"
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NoError(t, os.WriteFile(caFile, []byte(testutils.ConfiguredCAData), 0o600))
+	type requestParams struct {
+		name               string
+		namespace          string
+		insecureSkipVerify string
+		ca                 string
+		caFile             string
+		query              types.KubeconfigQuery
+	}
+	type want struct {
+		contexts    []string
+		servers     map[string]string
+		caData      string
+		errorStatus metav1.StatusReason
+	}
+	cases := map[string]struct {
+		request requestParams
+		want    want
+	}{
+		"ShouldSucceedGettingInsecureKubeconfig": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceName,
+				insecureSkipVerify: "true",
+				query:              types.KubeconfigQuery{CreateTokenQuery: types.CreateTokenQuery{Name: testutils.NamedTokenName}},
+			},
+			want: want{
+				contexts:    []string{namespaceName + "/" + testutils.ServiceAccountName},
+				servers:     map[string]string{hubClusterName: testutils.KubeAPIServer},
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedGettingKubeconfigWithCAAndClusters": {
+			request: requestParams{
+				name:      testutils.ServiceAccountName,
+				namespace: namespaceName,
+				query:     types.KubeconfigQuery{Clusters: []string{testutils.ManagedClusterName}},
+			},
+			want: want{
+				contexts: []string{
+					namespaceName + "/" + testutils.ServiceAccountName,
+					namespaceName + "/" + testutils.ServiceAccountName + "/" + testutils.ManagedClusterName,
+				},
+				servers: map[string]string{
+					hubClusterName: testutils.KubeAPIServer,
+					hubClusterName + "/" + testutils.ManagedClusterName: testutils.KubeAPIServer +
+						"/apis/cluster.core.oam.dev/v1alpha1/clustergateways/" + testutils.ManagedClusterName + "/proxy",
+				},
+				caData:      testutils.RootCAData,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedGettingKubeconfigWithConfiguredCA": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceName,
+				insecureSkipVerify: "false",
+				ca:                 testutils.ConfiguredCAData,
+				caFile:             caFile + testutils.NonExistentSuffix,
+			},
+			want: want{
+				contexts:    []string{namespaceName + "/" + testutils.ServiceAccountName},
+				servers:     map[string]string{hubClusterName: testutils.KubeAPIServer},
+				caData:      testutils.ConfiguredCAData,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldSucceedGettingKubeconfigWithConfiguredCAFile": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceWithoutCAName,
+				insecureSkipVerify: "false",
+				caFile:             caFile,
+			},
+			want: want{
+				contexts:    []string{namespaceWithoutCAName + "/" + testutils.ServiceAccountName},
+				servers:     map[string]string{hubClusterName: testutils.KubeAPIServer},
+				caData:      testutils.ConfiguredCAData,
+				errorStatus: metav1.StatusSuccess,
+			},
+		},
+		"ShouldFailGettingKubeconfigWithMissingCAFile": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceName,
+				insecureSkipVerify: "false",
+				caFile:             caFile + testutils.NonExistentSuffix,
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonInternalError,
+			},
+		},
+		"ShouldFailGettingKubeconfigWithoutCA": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceWithoutCAName,
+				insecureSkipVerify: "false",
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonNotFound,
+			},
+		},
+		"ShouldFailGettingKubeconfigWithInvalidCluster": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName,
+				namespace:          namespaceName,
+				insecureSkipVerify: "true",
+				query:              types.KubeconfigQuery{Clusters: []string{"Invalid_Cluster"}},
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonBadRequest,
+			},
+		},
+		"ShouldFailGettingKubeconfigWithoutServiceAccount": {
+			request: requestParams{
+				name:               testutils.ServiceAccountName + testutils.NonExistentSuffix,
+				namespace:          namespaceName,
+				insecureSkipVerify: "true",
+			},
+			want: want{
+				errorStatus: metav1.StatusReasonNotFound,
+			},
+		},
+	}
+	setup()
+	tokenController := NewTokenController(fakeClient, mocks.GinContext(), logger, DefaultTokenExpirationPolicy())
+	for _, namespace := range []string{namespaceName, namespaceWithoutCAName} {
+		createTestNamespace(namespace, utils.AddManagedLabel(map[string]string{}))
+		mocks.CreateTestServiceAccount(fakeClient, namespace, testutils.ServiceAccountName, "")
+	}
+	mocks.CreateTestRootCAConfigMap(fakeClient, namespaceName)
+
+	t.Setenv(utils.EnvKubeAPIServer, testutils.KubeAPIServer)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(utils.EnvInsecureSkipVerify, tc.request.insecureSkipVerify)
+			t.Setenv(envKubeAPIServerCA, tc.request.ca)
+			t.Setenv(envKubeAPIServerCAFile, tc.request.caFile)
+			response, err := tokenController.GetKubeconfig(tc.request.name, tc.request.namespace, testutils.NamedTokenCreator, tc.request.query)
+			if tc.want.errorStatus != metav1.StatusSuccess {
+				reason := err.(customerrors.ErrorWithStatusCode).StatusReason()
+
+				assert.Equal(t, tc.want.errorStatus, reason)
+				return
+			}
+
+			assert.NoError(t, err)
+			kubeconfig, err := clientcmd.Load(response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.contexts[0], kubeconfig.CurrentContext)
+			assert.Len(t, kubeconfig.Contexts, len(tc.want.contexts))
+			for _, contextName := range tc.want.contexts {
+				assert.Equal(t, tc.request.namespace, kubeconfig.Contexts[contextName].Namespace)
+			}
+			assert.Len(t, kubeconfig.Clusters, len(tc.want.servers))
+			for clusterName, server := range tc.want.servers {
+				assert.Equal(t, server, kubeconfig.Clusters[clusterName].Server)
+				assert.Equal(t, tc.want.caData, string(kubeconfig.Clusters[clusterName].CertificateAuthorityData))
+				assert.Equal(t, tc.want.caData == "", kubeconfig.Clusters[clusterName].InsecureSkipTLSVerify)
+			}
+		})
+	}
+}
@@ -2,41 +2,65 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/pagination"
+	userv1 "github.com/openshift/api/user/v1"
 	"go.uber.org/zap"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	AdminClusterRole        = "capp-user-admin"
-	ContributorClusterRole  = "capp-user-contributor"
-	ViewerClusterRole       = "capp-user-viewer"
-	AdminPlatformRole       = "admin"
-	ContributorPlatformRole = "contributor"
-	ViewerPlatformRole      = "viewer"
+	// roleBindingNameMaxLength bounds the sanitized names of the RoleBindings of members to the length of a DNS label.
+	roleBindingNameMaxLength  = 63
+	roleBindingNameHashLength = 10
+
+	// pendingRoleBindingSuffix names the RoleBinding which keeps a member bound while its role is changed.
+	pendingRoleBindingSuffix = "-pending-role"
+	roleBindingNameMaxSize   = 253
+	// pendingRoleBindingTTL is the age after which a pending RoleBinding is no longer kept by a change of role in
+	// progress, but was left behind by one whose original RoleBinding could not be restored.
+	pendingRoleBindingTTL = time.Minute
 )
 
 const (
-	ErrCouldNotListUsers         = "Could not list users"
-	ErrCouldNotGetRoleBinding    = "Could not get rolebinding %q"
-	ErrCouldNotCreateRolebinding = "Could not create rolebinding %q"
-	ErrCouldNotUpdateRolebinding = "Could not update rolebinding %q"
-	ErrCouldNotGetRolebindings   = "Could not get rolebindings"
-	ErrCouldNotDeleteRolebinding = "Could not delete rolebinding %q"
+	ErrCouldNotGetGroup           = "Could not get members of group %q"
+	ErrCouldNotListUsers          = "Could not list users"
+	ErrCouldNotGetRoleBinding     = "Could not get rolebinding %q"
+	ErrCouldNotCreateRolebinding  = "Could not create rolebinding %q"
+	ErrCouldNotUpdateRolebinding  = "Could not update rolebinding %q"
+	ErrCouldNotGetRolebindings    = "Could not get rolebindings"
+	ErrCouldNotDeleteRolebinding  = "Could not delete rolebinding %q"
+	ErrCouldNotRestoreRoleBinding = "Could not restore rolebinding %q"
+	ErrConcurrentRoleChange       = "The role of %q was changed concurrently, retry the request"
+	ErrExpirationNotInFuture      = "Expiration %v of %q is not in the future"
 )
 
+// invalidRoleBindingNameCharacters matches the characters of a member name which may not appear in the name of its RoleBinding.
+var invalidRoleBindingNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// UserController manages the members of namespaces, which are User or Group subjects bound to a platform role.
 type UserController interface {
 	// GetUsers get users from specified namespace and returns them as users.
 	GetUsers(namespace string, limit, page int) (types.UsersOutput, error)
 
 	// GetUser gets a specific user from specified namespace and returns it as user.
+	// The members of a Group are expanded through the OpenShift Group of the same name.
 	GetUser(userIdentifier types.UserIdentifier) (types.User, error)
 
 	// AddUser creates a new roleBinding in the specified namespace.
@@ -45,14 +69,18 @@ type UserController interface {
 	// DeleteUser deletes user in the specified namespace.
 	DeleteUser(userIdentifier types.UserIdentifier) (types.DeleteUserResponse, error)
 
-	// UpdateUser updates user in the specified namespace.
-	UpdateUser(user types.UserInput) (types.User, error)
+	// UpdateUser updates user in the specified namespace. If resourceVersion is set, the user is only updated
+	// if its RoleBinding has that resource version.
+	UpdateUser(user types.UserInput, resourceVersion string) (types.User, error)
 }
 
 type userController struct {
-	client kubernetes.Interface
-	ctx    context.Context
-	logger *zap.Logger
+	client      kubernetes.Interface
+	dynClient   client.Client
+	ctx         context.Context
+	logger      *zap.Logger
+	clock       clock.PassiveClock
+	roleCatalog *RoleCatalog
 }
 
 // UserPaginator paginates through secrets in a specified namespace.
@@ -62,11 +90,14 @@ type UserPaginator struct {
 	namespace string
 }
 
-func NewUserController(client kubernetes.Interface, context context.Context, logger *zap.Logger) UserController {
+func NewUserController(client kubernetes.Interface, dynClient client.Client, context context.Context, logger *zap.Logger, roleCatalog *RoleCatalog) UserController {
 	return &userController{
-		logger: logger,
-		client: client,
-		ctx:    context,
+		logger:      logger,
+		client:      client,
+		dynClient:   dynClient,
+		ctx:         context,
+		clock:       clock.RealClock{},
+		roleCatalog: roleCatalog,
 	}
 }
 
@@ -86,7 +117,7 @@ func (u *userController) GetUsers(namespace string, limit, page int) (types.User
 		return types.UsersOutput{}, customerrors.NewAPIError(ErrCouldNotListUsers, err)
 	}
 	for _, roleBinding := range roleBindings {
-		userOutputs.Users = append(userOutputs.Users, types.User{Name: roleBinding.Name, Role: convertToPlatformRole(roleBinding.RoleRef.Name)})
+		userOutputs.Users = append(userOutputs.Users, convertRoleBindingToUser(roleBinding, u.roleCatalog, u.clock.Now()))
 	}
 	userOutputs.Count = len(roleBindings)
 
@@ -94,86 +125,246 @@ func (u *userController) GetUsers(namespace string, limit, page int) (types.User
 }
 
 func (u *userController) GetUser(userIdentifier types.UserIdentifier) (types.User, error) {
-	userOutput := types.User{}
-	u.logger.Debug(fmt.Sprintf("Trying to fetch rolebinding %q in %q namespace", userIdentifier.UserName, userIdentifier.NamespaceName))
+	u.logger.Debug(fmt.Sprintf("Trying to fetch rolebinding of %q in %q namespace", userIdentifier.UserName, userIdentifier.NamespaceName))
 
-	roleBinding, err := u.client.RbacV1().RoleBindings(userIdentifier.NamespaceName).Get(u.ctx, userIdentifier.UserName, metav1.GetOptions{})
+	roleBinding, err := u.getMemberRoleBinding(userIdentifier.NamespaceName, subjectType(userIdentifier.Type), userIdentifier.UserName)
 	if err != nil {
 		u.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetRoleBinding, userIdentifier.UserName), err.Error()))
-		return userOutput, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetRoleBinding, userIdentifier.UserName), err)
+		return types.User{}, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotGetRoleBinding, userIdentifier.UserName), err)
 	}
 	u.logger.Debug(fmt.Sprintf("fetched roleBinding %q successfully", roleBinding.Name))
 
-	userOutput.Name = roleBinding.Name
-	userOutput.Role = convertToPlatformRole(roleBinding.RoleRef.Name)
+	userOutput := convertRoleBindingToUser(*roleBinding, u.roleCatalog, u.clock.Now())
+	if userOutput.Type == rbacv1.GroupKind {
+		userOutput.Members = u.getGroupMembers(userOutput.Name)
+	}
 
 	return userOutput, nil
 }
 
 func (u *userController) AddUser(user types.UserInput) (types.User, error) {
-	userOutput := types.User{}
-	u.logger.Debug(fmt.Sprintf("Trying to create rolebinding %q in %q namespace", user.Name, user.Namespace))
+	u.logger.Debug(fmt.Sprintf("Trying to create rolebinding of %q in %q namespace", user.Name, user.Namespace))
+
+	if err := u.roleCatalog.validateRole(user.Role); err != nil {
+		return types.User{}, err
+	}
+	if err := u.validateExpiration(user.User); err != nil {
+		return types.User{}, err
+	}
 
 	roleBinding, err := u.client.RbacV1().RoleBindings(user.Namespace).Create(u.ctx,
-		prepareRoleBinding(user.Name, user.Role), metav1.CreateOptions{})
+		prepareRoleBinding(subjectType(user.Type), user.Name, u.roleCatalog.convertToK8sRoles(user.Role), user.ExpiresAt), metav1.CreateOptions{})
 	if err != nil {
 		u.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotCreateRolebinding, user.Name), err.Error()))
-		return userOutput, err
+		return types.User{}, err
 	}
 	u.logger.Debug(fmt.Sprintf("created roleBinding %q successfully", roleBinding.Name))
-	userOutput.Name = roleBinding.Name
-	userOutput.Role = user.Role
 
-	return userOutput, nil
+	return convertRoleBindingToUser(*roleBinding, u.roleCatalog, u.clock.Now()), nil
 }
 
-func (u *userController) UpdateUser(user types.UserInput) (types.User, error) {
-	userOutput := types.User{}
-
+// UpdateUser sets the role and expiration of the member, whose access no longer expires if no expiration is set.
+// Since the role of a RoleBinding cannot be changed, the RoleBinding is replaced: a pending RoleBinding with the new
+// role keeps the member bound while the original RoleBinding is recreated with the new role, and the original
+// RoleBinding is restored if the replacement fails.
+func (u *userController) UpdateUser(user types.UserInput, resourceVersion string) (types.User, error) {
 	u.logger.Debug(fmt.Sprintf("Trying to update rolebinding %q in %q namespace", user.Name, user.Namespace))
 
-	// K8s does not allow to update role ref of roleBinding. So we need to delete the old roleBinding and create the desired one
-	_, err := u.DeleteUser(types.UserIdentifier{UserName: user.Name, NamespaceName: user.Namespace})
+	if err := u.roleCatalog.validateRole(user.Role); err != nil {
+		return types.User{}, err
+	}
+	if err := u.validateExpiration(user.User); err != nil {
+		return types.User{}, err
+	}
+
+	message := fmt.Sprintf(ErrCouldNotUpdateRolebinding, user.Name)
+	memberType := subjectType(user.Type)
+	original, err := u.getMemberRoleBinding(user.Namespace, memberType, user.Name)
+	if err != nil {
+		u.logger.Error(fmt.Sprintf("%v with error: %v", message, err.Error()))
+		return types.User{}, customerrors.NewAPIError(message, err)
+	}
+
+	if err := checkResourceVersion(user.Name, original.ResourceVersion, resourceVersion); err != nil {
+		return types.User{}, err
+	}
+
+	var updated *rbacv1.RoleBinding
+	desired := prepareRoleBinding(memberType, user.Name, u.roleCatalog.convertToK8sRoles(user.Role), user.ExpiresAt)
+	if original.Labels[utils.PendingRoleLabel] != "" {
+		updated, err = u.replacePendingRoleBinding(original, desired)
+	} else if original.RoleRef.Name == desired.RoleRef.Name {
+		// The role is kept, so only the expiration of the original RoleBinding may need to change.
+		updated, err = u.updateExpiration(original, user.ExpiresAt)
+	} else {
+		updated, err = replaceRoleBinding(u.ctx, u.client, u.logger, original, desired, u.clock.Now())
+	}
 	if err != nil {
-		return userOutput, err
+		u.logger.Error(fmt.Sprintf("%v with error: %v", message, err.Error()))
+		if conflictErr := checkUpdateConflict(user.Name, resourceVersion, err); conflictErr != nil {
+			return types.User{}, conflictErr
+		}
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) || errors.IsNotFound(err) {
+			return types.User{}, customerrors.NewConflictError(fmt.Sprintf(ErrConcurrentRoleChange, user.Name))
+		}
+		return types.User{}, customerrors.NewAPIError(message, err)
 	}
 
-	// The retry is needed because K8s is eventually consistent the old roleBinding takes time to delete.
-	err = retry.OnError(retry.DefaultRetry, func(err error) bool {
-		return err != nil
+	u.logger.Debug(fmt.Sprintf("updated roleBinding of %q successfully", user.Name))
+	return convertRoleBindingToUser(*updated, u.roleCatalog, u.clock.Now()), nil
+}
+
+// DeleteUser deletes the RoleBinding of the member, along with the pending RoleBinding which may be left binding
+// the member by a change of role.
+func (u *userController) DeleteUser(userIdentifier types.UserIdentifier) (types.DeleteUserResponse, error) {
+	u.logger.Debug(fmt.Sprintf("Trying to delete rolebinding of %q in namespace %q", userIdentifier.UserName, userIdentifier.NamespaceName))
 
-	}, func() error {
-		_, err := u.AddUser(user)
+	message := fmt.Sprintf(ErrCouldNotDeleteRolebinding, userIdentifier.UserName)
+	roleBinding, err := u.getMemberRoleBinding(userIdentifier.NamespaceName, subjectType(userIdentifier.Type), userIdentifier.UserName)
+	if err == nil {
+		err = u.client.RbacV1().RoleBindings(userIdentifier.NamespaceName).Delete(u.ctx, roleBinding.Name, metav1.DeleteOptions{})
+	}
+	if err != nil {
+		u.logger.Error(fmt.Sprintf("%v with error: %v", message, err.Error()))
+		return types.DeleteUserResponse{Message: fmt.Sprintf("%v with error: %v", message, err.Error())}, customerrors.NewAPIError(message, err)
+	}
+	if roleBinding.Labels[utils.PendingRoleLabel] == "" {
+		deletePendingRoleBinding(u.ctx, u.client, u.logger, userIdentifier.NamespaceName, pendingRoleBindingName(roleBinding.Name))
+	}
+
+	u.logger.Debug(fmt.Sprintf("Deleted roleBinding %q in namespace %q successfully", roleBinding.Name, userIdentifier.NamespaceName))
+	return types.DeleteUserResponse{Message: fmt.Sprintf("Deleted roleBinding %q in namespace %q successfully", roleBinding.Name, userIdentifier.NamespaceName)}, nil
+}
+
+// replaceRoleBinding replaces the original RoleBinding by one with the role of the desired RoleBinding, under the
+// name of the original, and returns the replacement. The original must not have changed since it was read, and an
+// AlreadyExists, Conflict or NotFound error is returned if it has, or if another change of the role is in progress.
+// The pending RoleBinding is only deleted once the original is replaced or restored, so that a failed change never
+// leaves the member unbound.
+func replaceRoleBinding(ctx context.Context, client kubernetes.Interface, logger *zap.Logger, original, desired *rbacv1.RoleBinding, now time.Time) (*rbacv1.RoleBinding, error) {
+	roleBindings := client.RbacV1().RoleBindings(original.Namespace)
+
+	pending := desired.DeepCopy()
+	pending.Name = pendingRoleBindingName(original.Name)
+	pending.Labels[utils.PendingRoleLabel] = "true"
+	if err := createPendingRoleBinding(ctx, client, original.Namespace, pending, now); err != nil {
+		return nil, err
+	}
+
+	err := roleBindings.Delete(ctx, original.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &original.UID, ResourceVersion: &original.ResourceVersion},
+	})
+	if err != nil {
+		deletePendingRoleBinding(ctx, client, logger, original.Namespace, pending.Name)
+		return nil, err
+	}
+
+	desired.Name = original.Name
+	// The original RoleBinding may take time to be deleted, in which case its name is not yet free.
+	var replacement *rbacv1.RoleBinding
+	err = retry.OnError(retry.DefaultRetry, errors.IsAlreadyExists, func() error {
+		replacement, err = roleBindings.Create(ctx, desired, metav1.CreateOptions{})
 		return err
 	})
+	if err != nil {
+		if restoreErr := restoreRoleBinding(ctx, client, original); restoreErr != nil {
+			logger.Error(fmt.Sprintf("%v with error: %v, keeping rolebinding %q", fmt.Sprintf(ErrCouldNotRestoreRoleBinding, original.Name), restoreErr.Error(), pending.Name))
+			return nil, err
+		}
+		deletePendingRoleBinding(ctx, client, logger, original.Namespace, pending.Name)
+		return nil, err
+	}
 
+	deletePendingRoleBinding(ctx, client, logger, original.Namespace, pending.Name)
+	return replacement, nil
+}
+
+// createPendingRoleBinding creates the pending RoleBinding of a change of role. A stale pending RoleBinding of the same
+// name is taken over, while an AlreadyExists error is returned if another change of role is in progress.
+func createPendingRoleBinding(ctx context.Context, client kubernetes.Interface, namespace string, pending *rbacv1.RoleBinding, now time.Time) error {
+	roleBindings := client.RbacV1().RoleBindings(namespace)
+	_, err := roleBindings.Create(ctx, pending, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, getErr := roleBindings.Get(ctx, pending.Name, metav1.GetOptions{})
+	if getErr != nil || !isStalePendingRoleBinding(*existing, now) {
+		return err
+	}
+	if err := roleBindings.Delete(ctx, existing.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &existing.UID}}); err != nil {
+		return err
+	}
+
+	_, err = roleBindings.Create(ctx, pending, metav1.CreateOptions{})
+	return err
+}
+
+// replacePendingRoleBinding replaces the pending RoleBinding which is left binding the member by a change of role whose
+// original RoleBinding could not be restored, by one with the role of the desired RoleBinding. A Conflict error is
+// returned unless the pending RoleBinding is stale, since the change of role which created it may be in progress.
+func (u *userController) replacePendingRoleBinding(pending, desired *rbacv1.RoleBinding) (*rbacv1.RoleBinding, error) {
+	if !isStalePendingRoleBinding(*pending, u.clock.Now()) {
+		return nil, errors.NewConflict(rbacv1.Resource(roleBindingsResource), pending.Name, fmt.Errorf("the role is being changed"))
+	}
+
+	replacement, err := u.client.RbacV1().RoleBindings(pending.Namespace).Create(u.ctx, desired, metav1.CreateOptions{})
 	if err != nil {
-		u.logger.Error(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotUpdateRolebinding, user.Name), err.Error()))
-		return userOutput, customerrors.NewAPIError(fmt.Sprintf(ErrCouldNotUpdateRolebinding, user.Name), err)
+		return nil, err
 	}
 
-	u.logger.Debug(fmt.Sprintf("updated roleBinding %q successfully", user.Name))
-	userOutput.Name = user.Name
-	userOutput.Role = user.Role
+	deletePendingRoleBinding(u.ctx, u.client, u.logger, pending.Namespace, pending.Name)
+	return replacement, nil
+}
 
-	return userOutput, nil
+// isStalePendingRoleBinding returns true if the RoleBinding is a pending RoleBinding which is older than the
+// pending RoleBinding TTL, so that no change of role is in progress with it anymore.
+func isStalePendingRoleBinding(roleBinding rbacv1.RoleBinding, now time.Time) bool {
+	return roleBinding.Labels[utils.PendingRoleLabel] != "" && now.Sub(roleBinding.CreationTimestamp.Time) > pendingRoleBindingTTL
 }
 
-func (u *userController) DeleteUser(userIdentifier types.UserIdentifier) (types.DeleteUserResponse, error) {
-	u.logger.Debug(fmt.Sprintf("Trying to delete rolebinding %q in namespace %q", userIdentifier.UserName, userIdentifier.NamespaceName))
+// deletePendingRoleBinding deletes the pending RoleBinding of a change of role which is over.
+func deletePendingRoleBinding(ctx context.Context, client kubernetes.Interface, logger *zap.Logger, namespace, name string) {
+	if err := client.RbacV1().RoleBindings(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		logger.Warn(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotDeleteRolebinding, name), err.Error()))
+	}
+}
 
-	if err := u.client.RbacV1().RoleBindings(userIdentifier.NamespaceName).Delete(u.ctx, userIdentifier.UserName, metav1.DeleteOptions{}); err != nil {
-		message := fmt.Sprintf(ErrCouldNotDeleteRolebinding, userIdentifier.UserName)
-		u.logger.Error(fmt.Sprintf("%v with error: %v", message, err.Error()))
-		return types.DeleteUserResponse{Message: fmt.Sprintf("%v with error: %v", message, err.Error())}, customerrors.NewAPIError(message, err)
+// updateExpiration sets the expiration of the RoleBinding, which must not have changed since it was read,
+// and returns the updated RoleBinding.
+func (u *userController) updateExpiration(roleBinding *rbacv1.RoleBinding, expiresAt *time.Time) (*rbacv1.RoleBinding, error) {
+	updated := roleBinding.DeepCopy()
+	setExpiration(updated, expiresAt)
+	if updated.Annotations[utils.RoleBindingExpirationAnnotation] == roleBinding.Annotations[utils.RoleBindingExpirationAnnotation] {
+		return roleBinding, nil
+	}
+
+	return u.client.RbacV1().RoleBindings(roleBinding.Namespace).Update(u.ctx, updated, metav1.UpdateOptions{})
+}
+
+// validateExpiration makes sure the expiration of the member, if it has one, is in the future.
+func (u *userController) validateExpiration(user types.User) error {
+	if user.ExpiresAt != nil && !user.ExpiresAt.After(u.clock.Now()) {
+		return customerrors.NewValidationError(fmt.Sprintf(ErrExpirationNotInFuture, user.ExpiresAt.UTC().Format(time.RFC3339), user.Name))
 	}
 
-	u.logger.Debug(fmt.Sprintf("Deleted roleBinding %q in namespace %q successfully", userIdentifier.UserName, userIdentifier.NamespaceName))
-	return types.DeleteUserResponse{Message: fmt.Sprintf("Deleted roleBinding %q in namespace %q successfully", userIdentifier.UserName, userIdentifier.NamespaceName)}, nil
+	return nil
+}
+
+// restoreRoleBinding recreates a RoleBinding which was deleted by a failed change of role.
+func restoreRoleBinding(ctx context.Context, client kubernetes.Interface, original *rbacv1.RoleBinding) error {
+	restored := original.DeepCopy()
+	restored.ResourceVersion = ""
+	restored.UID = ""
+	_, err := client.RbacV1().RoleBindings(original.Namespace).Create(ctx, restored, metav1.CreateOptions{})
+	return err
 }
 
 // FetchList retrieves a list of secrets from the specified namespace with given options.
 func (p *UserPaginator) FetchList(listOptions metav1.ListOptions) (*types.List[rbacv1.RoleBinding], error) {
+	// The RoleBindings of ServiceAccount roles are managed as well, but they do not bind users.
+	// Pending RoleBindings only keep members bound while their role is changed.
+	listOptions.LabelSelector = fmt.Sprintf("%s,!%s,!%s", listOptions.LabelSelector, utils.ServiceAccountRoleLabel, utils.PendingRoleLabel)
 	roleBindings, err := p.client.RbacV1().RoleBindings(p.namespace).List(p.Ctx, listOptions)
 	if err != nil {
 		p.Logger.Error(fmt.Sprintf("%v with error: %v", ErrCouldNotGetRolebindings, err.Error()))
@@ -184,35 +375,163 @@ func (p *UserPaginator) FetchList(listOptions metav1.ListOptions) (*types.List[r
 	return (*types.List[rbacv1.RoleBinding])(roleBindings), nil
 }
 
-func prepareRoleBinding(roleBindingName string, role string) *rbacv1.RoleBinding {
-	return &rbacv1.RoleBinding{
+// getMemberRoleBinding returns the RoleBinding which grants the member its role. RoleBindings which are not
+// named by memberRoleBindingName, such as those created for users before their names were sanitized, are
+// found by their subject. The pending RoleBinding of the member is returned if it has no other RoleBinding,
+// which is the case while its role is changed, or after its original RoleBinding could not be restored.
+func (u *userController) getMemberRoleBinding(namespace, memberType, name string) (*rbacv1.RoleBinding, error) {
+	roleBindingName := memberRoleBindingName(memberType, name)
+	roleBinding, err := u.client.RbacV1().RoleBindings(namespace).Get(u.ctx, roleBindingName, metav1.GetOptions{})
+	if err == nil && isMemberRoleBinding(*roleBinding, memberType, name) {
+		return roleBinding, nil
+	} else if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	roleBindings, err := u.client.RbacV1().RoleBindings(namespace).List(u.ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s,!%s", utils.ManagedLabelSelector, utils.ServiceAccountRoleLabel),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pending *rbacv1.RoleBinding
+	for i, roleBinding := range roleBindings.Items {
+		if !isMemberRoleBinding(roleBinding, memberType, name) {
+			continue
+		}
+		if roleBinding.Labels[utils.PendingRoleLabel] == "" {
+			return &roleBindings.Items[i], nil
+		}
+		pending = &roleBindings.Items[i]
+	}
+	if pending != nil {
+		return pending, nil
+	}
+
+	return nil, errors.NewNotFound(rbacv1.Resource(roleBindingsResource), roleBindingName)
+}
+
+// getGroupMembers returns the users of an OpenShift Group, such as a group synced from LDAP. The members are left out
+// if the Group cannot be read, since Group subjects may also be provided by the identity provider alone.
+func (u *userController) getGroupMembers(name string) []string {
+	group := &userv1.Group{}
+	if err := u.dynClient.Get(u.ctx, client.ObjectKey{Name: name}, group); err != nil {
+		u.logger.Warn(fmt.Sprintf("%v with error: %v", fmt.Sprintf(ErrCouldNotGetGroup, name), err.Error()))
+		return nil
+	}
+
+	return group.Users
+}
+
+// subjectType returns the type of the subject of a member, which is a User unless set otherwise.
+func subjectType(memberType string) string {
+	if memberType == "" {
+		return rbacv1.UserKind
+	}
+
+	return memberType
+}
+
+// memberRoleBindingName returns the name of the RoleBinding which grants the member its role. Users whose names are
+// valid object names keep them, so that their RoleBindings are named as before Group members were supported. Other
+// names are sanitized and prefixed by the type of the member, and a hash of the member keeps them unique.
+func memberRoleBindingName(memberType, name string) string {
+	if memberType == rbacv1.UserKind && len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(memberType + "/" + name))
+	prefix := strings.ToLower(memberType) + "-" + invalidRoleBindingNameCharacters.ReplaceAllString(strings.ToLower(name), "-")
+	prefix = strings.TrimRight(prefix[:min(len(prefix), roleBindingNameMaxLength-roleBindingNameHashLength-1)], "-")
+
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(hash[:])[:roleBindingNameHashLength])
+}
+
+// pendingRoleBindingName returns the name of the RoleBinding which keeps a member bound while its role is changed.
+func pendingRoleBindingName(roleBindingName string) string {
+	return roleBindingName[:min(len(roleBindingName), roleBindingNameMaxSize-len(pendingRoleBindingSuffix))] + pendingRoleBindingSuffix
+}
+
+// isMemberRoleBinding returns true if the subject of the RoleBinding is the member.
+func isMemberRoleBinding(roleBinding rbacv1.RoleBinding, memberType, name string) bool {
+	for _, subject := range roleBinding.Subjects {
+		if subject.Kind == memberType && subject.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertRoleBindingToUser converts the RoleBinding of a member to the member, along with the time left until its
+// access expires. RoleBindings without subjects are named after their user.
+func convertRoleBindingToUser(roleBinding rbacv1.RoleBinding, roleCatalog *RoleCatalog, now time.Time) types.User {
+	user := types.User{Name: roleBinding.Name, Type: rbacv1.UserKind, Role: roleCatalog.convertToPlatformRole(roleBinding.RoleRef.Name),
+		ResourceVersion: roleBinding.ResourceVersion}
+	if len(roleBinding.Subjects) > 0 {
+		user.Name = roleBinding.Subjects[0].Name
+		user.Type = roleBinding.Subjects[0].Kind
+	}
+
+	if expiresAt, ok := getExpiration(roleBinding); ok {
+		user.ExpiresAt = &expiresAt
+		user.ExpiresInSeconds = max(int64(expiresAt.Sub(now).Seconds()), 0)
+	}
+
+	return user
+}
+
+// prepareRoleBinding returns a RoleBinding which binds the member to the ClusterRole,
+// until the given expiration if it is set.
+func prepareRoleBinding(memberType, name, clusterRole string, expiresAt *time.Time) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   roleBindingName,
+			Name:   memberRoleBindingName(memberType, name),
 			Labels: utils.AddManagedLabel(map[string]string{}),
 		},
 		Subjects: []rbacv1.Subject{
 			{
-				Kind:     rbacv1.UserKind,
-				Name:     roleBindingName,
+				Kind:     memberType,
+				Name:     name,
 				APIGroup: rbacv1.GroupName,
 			},
 		},
 		RoleRef: rbacv1.RoleRef{
 			Kind:     "ClusterRole",
-			Name:     convertToK8sRoles(role),
+			Name:     clusterRole,
 			APIGroup: rbacv1.GroupName,
 		},
 	}
+	setExpiration(roleBinding, expiresAt)
+
+	return roleBinding
 }
 
-// convertToK8sRoles converts user given roles to the role which exists in the cluster
-func convertToK8sRoles(requestRole string) string {
-	rolesConvertor := map[string]string{AdminPlatformRole: AdminClusterRole, ContributorPlatformRole: ContributorClusterRole, ViewerPlatformRole: ViewerClusterRole}
-	return rolesConvertor[requestRole]
+// setExpiration sets the expiration annotation of the RoleBinding, or removes it if no expiration is given.
+func setExpiration(roleBinding *rbacv1.RoleBinding, expiresAt *time.Time) {
+	if expiresAt == nil {
+		delete(roleBinding.Annotations, utils.RoleBindingExpirationAnnotation)
+		return
+	}
+
+	if roleBinding.Annotations == nil {
+		roleBinding.Annotations = map[string]string{}
+	}
+	roleBinding.Annotations[utils.RoleBindingExpirationAnnotation] = expiresAt.UTC().Format(time.RFC3339)
 }
 
-// convertToPlatformRoles converts from the cluster role to platform roles.
-func convertToPlatformRole(k8sRole string) string {
-	rolesConvertor := map[string]string{AdminClusterRole: AdminPlatformRole, ContributorClusterRole: ContributorPlatformRole, ViewerClusterRole: ViewerClusterRole}
-	return rolesConvertor[k8sRole]
+// getExpiration returns the expiration of the RoleBinding, and false if it does not expire.
+// An invalid expiration annotation is ignored, so that the access it grants is never removed by mistake.
+func getExpiration(roleBinding rbacv1.RoleBinding) (time.Time, bool) {
+	annotation, ok := roleBinding.Annotations[utils.RoleBindingExpirationAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, annotation)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiresAt, true
 }
@@ -0,0 +1,495 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestMemberRoleBindingName(t *testing.T) {
+	cases := map[string]struct {
+		memberType string
+		name       string
+		want       string
+	}{
+		"ShouldKeepNameOfUser": {
+			memberType: testutils.UserKind,
+			name:       testutils.TestName,
+			want:       testutils.TestName,
+		},
+		"ShouldSanitizeNameOfUserWithInvalidName": {
+			memberType: testutils.UserKind,
+			name:       "User@Example.com",
+		},
+		"ShouldSanitizeNameOfGroup": {
+			memberType: testutils.GroupKind,
+			name:       testutils.GroupName,
+		},
+		"ShouldSanitizeNameOfLDAPGroup": {
+			memberType: testutils.GroupKind,
+			name:       testutils.LDAPGroupName,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			roleBindingName := memberRoleBindingName(test.memberType, test.name)
+			if test.want != "" {
+				assert.Equal(t, test.want, roleBindingName)
+				return
+			}
+
+			assert.Empty(t, validation.IsDNS1123Label(roleBindingName))
+			assert.NotEqual(t, memberRoleBindingName(testutils.UserKind, test.name), memberRoleBindingName(testutils.GroupKind, test.name))
+			assert.Equal(t, roleBindingName, memberRoleBindingName(test.memberType, test.name))
+		})
+	}
+}
+
+func TestGroupMembers(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-groupMembers"
+	cases := map[string]struct {
+		user types.User
+		want types.User
+	}{
+		"ShouldSucceedAddingAndGettingGroupWithMembers": {
+			user: types.User{Name: testutils.GroupName, Type: testutils.GroupKind, Role: testutils.AdminKey},
+			want: types.User{Name: testutils.GroupName, Type: testutils.GroupKind, Role: testutils.AdminKey, Members: []string{testutils.GroupMemberName}},
+		},
+		"ShouldSucceedAddingAndGettingLDAPGroup": {
+			user: types.User{Name: testutils.LDAPGroupName, Type: testutils.GroupKind, Role: testutils.ContributorKey},
+			want: types.User{Name: testutils.LDAPGroupName, Type: testutils.GroupKind, Role: testutils.ContributorKey},
+		},
+		"ShouldSucceedAddingUserWithGroupName": {
+			user: types.User{Name: testutils.GroupName, Type: testutils.UserKind, Role: testutils.ContributorKey},
+			want: types.User{Name: testutils.GroupName, Type: testutils.UserKind, Role: testutils.ContributorKey},
+		},
+	}
+
+	setup()
+	createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+	mocks.CreateTestGroup(dynClient, testutils.GroupName, []string{testutils.GroupMemberName})
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := mocks.GinContext()
+			userController := NewUserController(fakeClient, dynClient, c, logger, DefaultRoleCatalog())
+
+			_, err := userController.AddUser(types.UserInput{Namespace: namespaceName, User: test.user})
+			assert.NoError(t, err)
+
+			user, err := userController.GetUser(types.UserIdentifier{UserName: test.user.Name, NamespaceName: namespaceName, Type: test.user.Type})
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, user)
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-updateUser"
+	userName := testutils.TestName
+	pendingName := userName + pendingRoleBindingSuffix
+	type want struct {
+		role         string
+		keepsPending bool
+		statusCode   int
+		error        string
+	}
+	cases := map[string]struct {
+		role            string
+		resourceVersion string
+		prepare         func()
+		want            want
+	}{
+		"ShouldSucceedChangingRole": {
+			role: testutils.ContributorKey,
+			want: want{role: testutils.ContributorKey},
+		},
+		"ShouldSucceedKeepingSameRole": {
+			role: testutils.AdminKey,
+			want: want{role: testutils.AdminKey},
+		},
+		"ShouldFailWithStaleResourceVersion": {
+			role:            testutils.ViewerKey,
+			resourceVersion: testutils.StaleResourceVersion,
+			want: want{
+				role:       testutils.AdminKey,
+				statusCode: http.StatusPreconditionFailed,
+				error:      fmt.Sprintf(ErrResourceVersionMismatch, userName, "", testutils.StaleResourceVersion),
+			},
+		},
+		"ShouldReturnConflictWhenChangeIsInProgress": {
+			role: testutils.ContributorKey,
+			prepare: func() {
+				mocks.CreateTestRoleBinding(fakeClient, pendingName, namespaceName, testutils.ContributorKey)
+			},
+			want: want{
+				role:         testutils.AdminKey,
+				keepsPending: true,
+				statusCode:   http.StatusConflict,
+				error:        fmt.Sprintf(ErrConcurrentRoleChange, userName),
+			},
+		},
+		"ShouldReturnConflictWhenRoleBindingChangedConcurrently": {
+			role: testutils.ContributorKey,
+			prepare: func() {
+				fakeClient.PrependReactor("delete", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if action.(k8stesting.DeleteAction).GetName() != userName {
+						return false, nil, nil
+					}
+					return true, nil, errors.NewConflict(rbacv1.Resource("rolebindings"), userName, fmt.Errorf("the object has been modified"))
+				})
+			},
+			want: want{
+				role:       testutils.AdminKey,
+				statusCode: http.StatusConflict,
+				error:      fmt.Sprintf(ErrConcurrentRoleChange, userName),
+			},
+		},
+		"ShouldFailWithPreconditionWhenRoleBindingChangedConcurrently": {
+			role:            testutils.ContributorKey,
+			resourceVersion: testutils.CreatedResourceVersion,
+			prepare: func() {
+				roleBinding, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(mocks.GinContext(), userName, metav1.GetOptions{})
+				assert.NoError(t, err)
+				roleBinding.ResourceVersion = testutils.CreatedResourceVersion
+				_, err = fakeClient.RbacV1().RoleBindings(namespaceName).Update(mocks.GinContext(), roleBinding, metav1.UpdateOptions{})
+				assert.NoError(t, err)
+				fakeClient.PrependReactor("delete", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if action.(k8stesting.DeleteAction).GetName() != userName {
+						return false, nil, nil
+					}
+					return true, nil, errors.NewConflict(rbacv1.Resource("rolebindings"), userName, fmt.Errorf("the object has been modified"))
+				})
+			},
+			want: want{
+				role:       testutils.AdminKey,
+				statusCode: http.StatusPreconditionFailed,
+				error:      fmt.Sprintf(ErrResourceVersionConflict, userName, testutils.CreatedResourceVersion),
+			},
+		},
+		"ShouldTakeOverStalePendingRoleBinding": {
+			role: testutils.ContributorKey,
+			prepare: func() {
+				createTestPendingRoleBinding(namespaceName, userName, testutils.ViewerKey, time.Now().Add(-2*pendingRoleBindingTTL))
+			},
+			want: want{role: testutils.ContributorKey},
+		},
+		"ShouldRestoreRoleBindingWhenReplacementFails": {
+			role: testutils.ContributorKey,
+			prepare: func() {
+				failed := false
+				fakeClient.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					roleBinding := action.(k8stesting.CreateAction).GetObject().(*rbacv1.RoleBinding)
+					if failed || roleBinding.Name != userName {
+						return false, nil, nil
+					}
+					failed = true
+					return true, nil, errors.NewForbidden(rbacv1.Resource("rolebindings"), userName, fmt.Errorf("denied"))
+				})
+			},
+			want: want{
+				role:       testutils.AdminKey,
+				statusCode: http.StatusForbidden,
+				error:      fmt.Sprintf(ErrCouldNotUpdateRolebinding, userName),
+			},
+		},
+		"ShouldKeepPendingRoleBindingWhenRestoreFails": {
+			role: testutils.ContributorKey,
+			prepare: func() {
+				fakeClient.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					roleBinding := action.(k8stesting.CreateAction).GetObject().(*rbacv1.RoleBinding)
+					if roleBinding.Name != userName {
+						return false, nil, nil
+					}
+					return true, nil, errors.NewForbidden(rbacv1.Resource("rolebindings"), userName, fmt.Errorf("denied"))
+				})
+			},
+			want: want{
+				keepsPending: true,
+				statusCode:   http.StatusForbidden,
+				error:        fmt.Sprintf(ErrCouldNotUpdateRolebinding, userName),
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			setup()
+			createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+			mocks.CreateTestRoleBinding(fakeClient, userName, namespaceName, testutils.AdminKey)
+			if test.prepare != nil {
+				test.prepare()
+			}
+
+			c := mocks.GinContext()
+			userController := NewUserController(fakeClient, dynClient, c, logger, DefaultRoleCatalog())
+			user, err := userController.UpdateUser(types.UserInput{Namespace: namespaceName, User: types.User{Name: userName, Role: test.role}}, test.resourceVersion)
+
+			if test.want.error != "" {
+				assert.ErrorContains(t, err, test.want.error)
+				var errWithStatusCode customerrors.ErrorWithStatusCode
+				assert.ErrorAs(t, err, &errWithStatusCode)
+				assert.Equal(t, test.want.statusCode, errWithStatusCode.StatusCode())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, types.User{Name: userName, Type: testutils.UserKind, Role: test.role}, user)
+			}
+
+			roleBinding, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, userName, metav1.GetOptions{})
+			if test.want.role == "" {
+				assert.True(t, errors.IsNotFound(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, DefaultRoleCatalog().convertToK8sRoles(test.want.role), roleBinding.RoleRef.Name)
+			}
+
+			// The pending RoleBinding of another change in progress, or the one left binding the member after the
+			// original could not be restored, is kept.
+			pending, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, pendingName, metav1.GetOptions{})
+			assert.Equal(t, !test.want.keepsPending, errors.IsNotFound(err))
+			if test.want.keepsPending && test.want.role == "" {
+				assert.Equal(t, DefaultRoleCatalog().convertToK8sRoles(test.role), pending.RoleRef.Name)
+			}
+		})
+	}
+}
+
+func TestRoleChangeWhoseRestoreFails(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-failedRestore"
+	userName := testutils.TestName
+	userIdentifier := types.UserIdentifier{UserName: userName, NamespaceName: namespaceName}
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		response    interface{}
+		role        string
+		pendingRole string
+		statusCode  int
+	}
+	cases := map[string]struct {
+		elapsed time.Duration
+		request func(userController UserController) (interface{}, error)
+		want    want
+	}{
+		"ShouldGetRoleOfPendingRoleBinding": {
+			request: func(userController UserController) (interface{}, error) {
+				return userController.GetUser(userIdentifier)
+			},
+			want: want{
+				response:    types.User{Name: userName, Type: testutils.UserKind, Role: testutils.ContributorKey},
+				pendingRole: testutils.ContributorKey,
+			},
+		},
+		"ShouldDeletePendingRoleBinding": {
+			request: func(userController UserController) (interface{}, error) {
+				return userController.DeleteUser(userIdentifier)
+			},
+		},
+		"ShouldReturnConflictWhileChangeMayBeInProgress": {
+			elapsed: pendingRoleBindingTTL / 2,
+			request: func(userController UserController) (interface{}, error) {
+				return userController.UpdateUser(types.UserInput{Namespace: namespaceName, User: types.User{Name: userName, Role: testutils.ViewerKey}}, "")
+			},
+			want: want{pendingRole: testutils.ContributorKey, statusCode: http.StatusConflict},
+		},
+		"ShouldReplaceStalePendingRoleBinding": {
+			elapsed: 2 * pendingRoleBindingTTL,
+			request: func(userController UserController) (interface{}, error) {
+				return userController.UpdateUser(types.UserInput{Namespace: namespaceName, User: types.User{Name: userName, Role: testutils.ViewerKey}}, "")
+			},
+			want: want{
+				response: types.User{Name: userName, Type: testutils.UserKind, Role: testutils.ViewerKey},
+				role:     testutils.ViewerKey,
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			setup()
+			createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+			mocks.CreateTestRoleBinding(fakeClient, userName, namespaceName, testutils.AdminKey)
+
+			clock := testingclock.NewFakeClock(now)
+			// RoleBindings are given their creation timestamp, as done by the API server.
+			fakeClient.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				action.(k8stesting.CreateAction).GetObject().(*rbacv1.RoleBinding).CreationTimestamp = metav1.NewTime(clock.Now())
+				return false, nil, nil
+			})
+			// Neither the replacement nor the original RoleBinding can be created, so the pending RoleBinding is left behind.
+			failing := true
+			fakeClient.PrependReactor("create", "rolebindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if !failing || action.(k8stesting.CreateAction).GetObject().(*rbacv1.RoleBinding).Name != userName {
+					return false, nil, nil
+				}
+				return true, nil, errors.NewForbidden(rbacv1.Resource("rolebindings"), userName, fmt.Errorf("denied"))
+			})
+
+			c := mocks.GinContext()
+			userController := &userController{client: fakeClient, dynClient: dynClient, ctx: c, logger: logger, clock: clock, roleCatalog: DefaultRoleCatalog()}
+			_, err := userController.UpdateUser(types.UserInput{Namespace: namespaceName, User: types.User{Name: userName, Role: testutils.ContributorKey}}, "")
+			assert.Error(t, err)
+
+			failing = false
+			clock.Step(test.elapsed)
+			response, err := test.request(userController)
+			if test.want.statusCode != 0 {
+				var errWithStatusCode customerrors.ErrorWithStatusCode
+				assert.ErrorAs(t, err, &errWithStatusCode)
+				assert.Equal(t, test.want.statusCode, errWithStatusCode.StatusCode())
+			} else {
+				assert.NoError(t, err)
+			}
+			if test.want.response != nil {
+				assert.Equal(t, test.want.response, response)
+			}
+
+			for roleBindingName, role := range map[string]string{userName: test.want.role, userName + pendingRoleBindingSuffix: test.want.pendingRole} {
+				roleBinding, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, roleBindingName, metav1.GetOptions{})
+				if role == "" {
+					assert.True(t, errors.IsNotFound(err))
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, DefaultRoleCatalog().convertToK8sRoles(role), roleBinding.RoleRef.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestUserExpiration(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-userExpiration"
+	userName := testutils.TestName
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	inOneHour := now.Add(time.Hour)
+	inOneDay := now.Add(24 * time.Hour)
+	anHourAgo := now.Add(-time.Hour)
+
+	type want struct {
+		user       types.User
+		annotation string
+		error      string
+	}
+	cases := map[string]struct {
+		existingExpiresAt string
+		update            bool
+		user              types.User
+		want              want
+	}{
+		"ShouldSucceedAddingUserWithExpiration": {
+			user: types.User{Name: userName, Role: testutils.ContributorKey, ExpiresAt: &inOneHour},
+			want: want{
+				user:       types.User{Name: userName, Type: testutils.UserKind, Role: testutils.ContributorKey, ExpiresAt: &inOneHour, ExpiresInSeconds: 3600},
+				annotation: inOneHour.Format(time.RFC3339),
+			},
+		},
+		"ShouldFailAddingUserWithPastExpiration": {
+			user: types.User{Name: userName, Role: testutils.ContributorKey, ExpiresAt: &anHourAgo},
+			want: want{
+				error: fmt.Sprintf(ErrExpirationNotInFuture, anHourAgo.Format(time.RFC3339), userName),
+			},
+		},
+		"ShouldSucceedExtendingExpirationOfSameRole": {
+			existingExpiresAt: inOneHour.Format(time.RFC3339),
+			update:            true,
+			user:              types.User{Name: userName, Role: testutils.ContributorKey, ExpiresAt: &inOneDay},
+			want: want{
+				user:       types.User{Name: userName, Type: testutils.UserKind, Role: testutils.ContributorKey, ExpiresAt: &inOneDay, ExpiresInSeconds: 86400},
+				annotation: inOneDay.Format(time.RFC3339),
+			},
+		},
+		"ShouldSucceedChangingRoleWithExpiration": {
+			existingExpiresAt: inOneHour.Format(time.RFC3339),
+			update:            true,
+			user:              types.User{Name: userName, Role: testutils.AdminKey, ExpiresAt: &inOneHour},
+			want: want{
+				user:       types.User{Name: userName, Type: testutils.UserKind, Role: testutils.AdminKey, ExpiresAt: &inOneHour, ExpiresInSeconds: 3600},
+				annotation: inOneHour.Format(time.RFC3339),
+			},
+		},
+		"ShouldSucceedRemovingExpiration": {
+			existingExpiresAt: inOneHour.Format(time.RFC3339),
+			update:            true,
+			user:              types.User{Name: userName, Role: testutils.ContributorKey},
+			want: want{
+				user: types.User{Name: userName, Type: testutils.UserKind, Role: testutils.ContributorKey},
+			},
+		},
+		"ShouldFailUpdatingUserWithPastExpiration": {
+			existingExpiresAt: inOneHour.Format(time.RFC3339),
+			update:            true,
+			user:              types.User{Name: userName, Role: testutils.ContributorKey, ExpiresAt: &anHourAgo},
+			want: want{
+				error:      fmt.Sprintf(ErrExpirationNotInFuture, anHourAgo.Format(time.RFC3339), userName),
+				annotation: inOneHour.Format(time.RFC3339),
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			setup()
+			createTestNamespace(namespaceName, utils.AddManagedLabel(map[string]string{}))
+			if test.existingExpiresAt != "" {
+				mocks.CreateTestExpiringRoleBinding(fakeClient, userName, namespaceName, testutils.ContributorKey, test.existingExpiresAt)
+			}
+
+			c := mocks.GinContext()
+			userController := &userController{client: fakeClient, dynClient: dynClient, ctx: c, logger: logger, clock: testingclock.NewFakePassiveClock(now), roleCatalog: DefaultRoleCatalog()}
+
+			var user types.User
+			var err error
+			if test.update {
+				user, err = userController.UpdateUser(types.UserInput{Namespace: namespaceName, User: test.user}, "")
+			} else {
+				user, err = userController.AddUser(types.UserInput{Namespace: namespaceName, User: test.user})
+			}
+
+			if test.want.error != "" {
+				assert.ErrorContains(t, err, test.want.error)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want.user, user)
+
+				users, err := userController.GetUsers(namespaceName, 10, 1)
+				assert.NoError(t, err)
+				assert.Equal(t, []types.User{test.want.user}, users.Users)
+			}
+
+			roleBinding, err := fakeClient.RbacV1().RoleBindings(namespaceName).Get(c, userName, metav1.GetOptions{})
+			if test.want.annotation == "" && test.want.error != "" {
+				assert.True(t, errors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.annotation, roleBinding.Annotations[utils.RoleBindingExpirationAnnotation])
+		})
+	}
+}
+
+// createTestPendingRoleBinding creates the pending RoleBinding of a change of the role of the user, created at the given time.
+func createTestPendingRoleBinding(namespace, userName, role string, creationTimestamp time.Time) {
+	pending := mocks.PrepareRoleBinding(userName, namespace, role)
+	pending.Name = userName + pendingRoleBindingSuffix
+	pending.Labels[utils.PendingRoleLabel] = "true"
+	pending.CreationTimestamp = metav1.NewTime(creationTimestamp)
+	if _, err := fakeClient.RbacV1().RoleBindings(namespace).Create(mocks.GinContext(), &pending, metav1.CreateOptions{}); err != nil {
+		panic(err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math"
 	"net/http"
+	"time"
 )
 
 // ErrorWithStatusCode is a common interface for errors that include a status code.
@@ -147,6 +149,55 @@ func (e *ConflictError) StatusReason() metav1.StatusReason {
 	return metav1.StatusReasonConflict
 }
 
+// StatusReasonPreconditionFailed is the reason of a PreconditionFailedError, which Kubernetes has no reason for.
+const StatusReasonPreconditionFailed metav1.StatusReason = "PreconditionFailed"
+
+// PreconditionFailedError represents an error due to a precondition of a request, such as If-Match, which does not hold.
+type PreconditionFailedError struct {
+	Message string
+}
+
+func NewPreconditionFailedError(message string) *PreconditionFailedError {
+	return &PreconditionFailedError{
+		Message: message,
+	}
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+func (e *PreconditionFailedError) StatusCode() int {
+	return http.StatusPreconditionFailed
+}
+
+func (e *PreconditionFailedError) StatusReason() metav1.StatusReason {
+	return StatusReasonPreconditionFailed
+}
+
+// UnsupportedMediaTypeError represents an error due to a request body of an unsupported content type.
+type UnsupportedMediaTypeError struct {
+	Message string
+}
+
+func NewUnsupportedMediaTypeError(message string) *UnsupportedMediaTypeError {
+	return &UnsupportedMediaTypeError{
+		Message: message,
+	}
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return e.Message
+}
+
+func (e *UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+func (e *UnsupportedMediaTypeError) StatusReason() metav1.StatusReason {
+	return metav1.StatusReasonUnsupportedMediaType
+}
+
 // InternalServerError represents an error due to an unknown error.
 type InternalServerError struct {
 	Message string
@@ -169,3 +220,33 @@ func (e *InternalServerError) StatusCode() int {
 func (e *InternalServerError) StatusReason() metav1.StatusReason {
 	return metav1.StatusReasonInternalError
 }
+
+// TooManyRequestsError represents an error when a client sent too many requests and has to wait before retrying.
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func NewTooManyRequestsError(message string, retryAfter time.Duration) *TooManyRequestsError {
+	return &TooManyRequestsError{
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.Message
+}
+
+func (e *TooManyRequestsError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (e *TooManyRequestsError) StatusReason() metav1.StatusReason {
+	return metav1.StatusReasonTooManyRequests
+}
+
+// RetryAfterSeconds returns the number of seconds to wait before retrying, rounded up, as used in the Retry-After header.
+func (e *TooManyRequestsError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
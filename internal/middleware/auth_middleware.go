@@ -1,21 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	multicluster "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/transport"
-	"k8s.io/apimachinery/pkg/runtime"
-	"os"
 	"strings"
 
 	"github.com/dana-team/platform-backend/internal/auth"
 	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	zapctrl "sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -33,79 +30,147 @@ const (
 	DynamicClientCtxKey = "dynClient"
 	TokenCtxKey         = "token"
 	ConfigKey           = "config"
-)
-
-const (
-	envKubeAPIServer      = "KUBE_API_SERVER"
-	envInsecureSkipVerify = "INSECURE_SKIP_VERIFY"
+	IdentityCtxKey      = "identity"
 )
 
 // TokenAuthMiddleware validates the Authorization header and sets up Kubernetes client.
-func TokenAuthMiddleware(tokenProvider auth.TokenProvider, scheme *runtime.Scheme) gin.HandlerFunc {
+// The identity and clients of each token are cached in the given ClientCache.
+// When a SessionManager is set, requests without an Authorization header may authenticate with a session cookie.
+func TokenAuthMiddleware(tokenProvider auth.TokenProvider, clientCache *ClientCache, sessionManager *SessionManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger, err := GetLogger(c)
 		if AddErrorToContext(c, err) {
 			return
 		}
 
-		token, err := validateToken(c)
-		if err != nil {
+		token, err := validateToken(c, sessionManager)
+		if errors.Is(err, ErrInvalidCSRF) {
+			logger.Warn("Rejected session request without a valid CSRF token", zap.Error(err))
+			AddErrorToContext(c, customerrors.NewUnauthorizedError(err.Error()))
+			c.Abort()
+			return
+		} else if err != nil {
 			logger.Error("Failed to obtain OpenShift token", zap.Error(err))
 			AddErrorToContext(c, customerrors.NewUnauthorizedError("failed to obtain OpenShift token"))
 			c.Abort()
 			return
 		}
 
-		username, err := tokenProvider.ObtainUsername(token, logger)
+		clients, err := getClients(tokenProvider, clientCache, token, logger)
 		if err != nil {
-			logger.Error("Failed to get user info", zap.Error(err))
-			AddErrorToContext(c, customerrors.NewInternalServerError("failed to get user info"))
+			AddErrorToContext(c, err)
 			c.Abort()
 			return
 		}
-		userLogger := logger.With(zap.String("user", username))
 
-		config, err := createKubernetesConfig(token, os.Getenv(envKubeAPIServer))
-		if err != nil {
-			userLogger.Error("Failed to create Kubernetes client config", zap.Error(err))
-			AddErrorToContext(c, customerrors.NewInternalServerError("failed to create Kubernetes client config"))
-			c.Abort()
+		setClients(c, token, clients, logger)
+		c.Next()
+	}
+}
+
+// WebSocketAuthMiddleware authenticates WebSocket routes. Requests with a ticket query parameter are
+// authenticated by redeeming the ticket for the token it was issued for, so that the token is never sent
+// by the client. Other requests are authenticated like in TokenAuthMiddleware.
+func WebSocketAuthMiddleware(tokenProvider auth.TokenProvider, clientCache *ClientCache, sessionManager *SessionManager, ticketStore *WSTicketStore) gin.HandlerFunc {
+	tokenAuthMiddleware := TokenAuthMiddleware(tokenProvider, clientCache, sessionManager)
+
+	return func(c *gin.Context) {
+		ticket := c.Query(WSTicketQueryKey)
+		if ticket == "" || ticketStore == nil {
+			tokenAuthMiddleware(c)
+			return
+		}
+
+		logger, err := GetLogger(c)
+		if AddErrorToContext(c, err) {
 			return
 		}
 
-		kubeClient, err := kubernetes.NewForConfig(config)
+		token, username, err := ticketStore.Redeem(ticket, c.Request.URL.Path)
 		if err != nil {
-			userLogger.Error("Failed to create Kubernetes client", zap.Error(err))
-			AddErrorToContext(c, customerrors.NewInternalServerError("failed to create Kubernetes client"))
+			logger.Warn("Rejected WebSocket ticket", zap.Error(err))
+			AddErrorToContext(c, customerrors.NewUnauthorizedError(err.Error()))
 			c.Abort()
 			return
 		}
 
-		dynClient, err := client.New(config, client.Options{Scheme: scheme})
+		clients, err := getClients(tokenProvider, clientCache, token, logger)
 		if err != nil {
-			userLogger.Error("Failed to create Kubernetes dynamic client", zap.Error(err))
-			AddErrorToContext(c, customerrors.NewInternalServerError("failed to create Kubernetes dynamic client"))
+			AddErrorToContext(c, err)
 			c.Abort()
 			return
 		}
 
-		opts := zapctrl.Options{Development: true}
-		ctrl.SetLogger(zapctrl.New(zapctrl.UseFlagOptions(&opts)))
+		if clients.Identity.Username != username {
+			logger.Warn("Rejected WebSocket ticket of another user", zap.String("user", clients.Identity.Username), zap.String("ticketUser", username))
+			AddErrorToContext(c, customerrors.NewUnauthorizedError(ErrInvalidWSTicket.Error()))
+			c.Abort()
+			return
+		}
 
-		// Update the logger with the username
-		c.Set(LoggerCtxKey, userLogger)
-		c.Set(KubeClientCtxKey, kubeClient)
-		c.Set(DynamicClientCtxKey, dynClient)
-		c.Set(TokenCtxKey, token)
-		c.Set(ConfigKey, config)
+		setClients(c, token, clients, logger)
 		c.Next()
 	}
 }
 
-// validateToken validates the format and presence of the Authorization token.
-func validateToken(c *gin.Context) (string, error) {
+// getClients returns the cached clients of the token, resolving and caching them if they are not cached.
+func getClients(tokenProvider auth.TokenProvider, clientCache *ClientCache, token string, logger *zap.Logger) (*CachedClients, error) {
+	clients, ok := clientCache.Get(token)
+	if ok {
+		return clients, nil
+	}
+
+	clients, err := resolveClients(tokenProvider, clientCache, token, logger)
+	if err != nil {
+		return nil, err
+	}
+	clientCache.Add(token, clients)
+
+	return clients, nil
+}
+
+// setClients sets the token, identity and clients of the user in the context.
+func setClients(c *gin.Context, token string, clients *CachedClients, logger *zap.Logger) {
+	opts := zapctrl.Options{Development: true}
+	ctrl.SetLogger(zapctrl.New(zapctrl.UseFlagOptions(&opts)))
+
+	// Update the logger with the username
+	c.Set(LoggerCtxKey, logger.With(zap.String("user", clients.Identity.Username), zap.Strings("groups", clients.Identity.Groups)))
+	c.Set(KubeClientCtxKey, clients.KubeClient)
+	c.Set(DynamicClientCtxKey, clients.DynClient)
+	c.Set(TokenCtxKey, token)
+	c.Set(ConfigKey, clients.Config)
+	c.Set(IdentityCtxKey, clients.Identity)
+}
+
+// resolveClients resolves the identity behind the token and creates its Kubernetes clients.
+func resolveClients(tokenProvider auth.TokenProvider, clientCache *ClientCache, token string, logger *zap.Logger) (*CachedClients, error) {
+	identity, err := auth.ResolveIdentity(tokenProvider, token, logger)
+	if err != nil {
+		logger.Error("Failed to get user info", zap.Error(err))
+		return nil, customerrors.NewInternalServerError("failed to get user info")
+	}
+
+	clients, err := clientCache.NewClients(token, identity)
+	if err != nil {
+		logger.With(zap.String("user", identity.Username)).Error("Failed to create Kubernetes clients", zap.Error(err))
+		return nil, customerrors.NewInternalServerError("failed to create Kubernetes clients")
+	}
+
+	return clients, nil
+}
+
+// validateToken validates the format and presence of the Authorization token. Without an Authorization header,
+// the token is taken from the session cookie, if the session mode is enabled, or from the WebSocket protocol header.
+func validateToken(c *gin.Context, sessionManager *SessionManager) (string, error) {
 	token := c.GetHeader(httpAuthorizationHeader)
 	if token == "" {
+		if sessionManager != nil {
+			token, err := sessionManager.TokenFromSession(c)
+			if !errors.Is(err, ErrSessionNotFound) {
+				return token, err
+			}
+		}
 		return validateTokenFromWS(c)
 	}
 
@@ -131,7 +196,7 @@ func validateTokenFromWS(c *gin.Context) (string, error) {
 
 // createKubernetesConfig creates a new Kubernetes client config using the provided token.
 func createKubernetesConfig(token, kubeApiServer string) (*rest.Config, error) {
-	skipTlsVerify, err := utils.GetEnvBool(envInsecureSkipVerify, true)
+	skipTlsVerify, err := utils.GetEnvBool(utils.EnvInsecureSkipVerify, true)
 	if err != nil {
 		return nil, err
 	}
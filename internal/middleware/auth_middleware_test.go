@@ -2,6 +2,7 @@ package middleware
 
 import (
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/utils"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,8 +23,8 @@ type MockTokenProvider struct {
 	Err      error
 }
 
-func (m MockTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (string, error) {
-	return m.Token, m.Err
+func (m MockTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: m.Token}, m.Err
 }
 
 func (m MockTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
@@ -30,8 +32,8 @@ func (m MockTokenProvider) ObtainUsername(token string, logger *zap.Logger) (str
 }
 
 func TestTokenAuthMiddleware(t *testing.T) {
-	_ = os.Setenv(envKubeAPIServer, "https://example.com/api")
-	_ = os.Setenv(envInsecureSkipVerify, "true")
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
 
 	logger, _ := zap.NewDevelopment()
 	router := gin.New()
@@ -43,7 +45,11 @@ func TestTokenAuthMiddleware(t *testing.T) {
 	})
 
 	router.Use(ErrorHandlingMiddleware())
-	router.Use(TokenAuthMiddleware(MockTokenProvider{Token: "valid_token", Username: "user", Err: nil}, newScheme()))
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	if err != nil {
+		t.Fatalf("Failed to create client cache: %v", err)
+	}
+	router.Use(TokenAuthMiddleware(MockTokenProvider{Token: "valid_token", Username: "user", Err: nil}, clientCache, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		_, ok := c.Get("kubeClient")
 		if !ok {
@@ -57,6 +63,12 @@ func TestTokenAuthMiddleware(t *testing.T) {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "dynClient not set in context"})
 			return
 		}
+		identity, err := GetIdentity(c)
+		if err != nil || identity.Username != "user" {
+			t.Error("Expected identity to be set in context")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "identity not set in context"})
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "pong",
@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/auth"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	envClientCacheSize          = "CLIENT_CACHE_SIZE"
+	envClientCacheTTL           = "CLIENT_CACHE_TTL"
+	envClientCacheStatsInterval = "CLIENT_CACHE_STATS_INTERVAL"
+)
+
+const (
+	defaultClientCacheSize          = 1000
+	defaultClientCacheTTL           = time.Minute
+	defaultClientCacheStatsInterval = 5 * time.Minute
+)
+
+// CachedClients holds the identity and Kubernetes clients resolved for a single token.
+type CachedClients struct {
+	Identity   auth.Identity
+	KubeClient kubernetes.Interface
+	DynClient  client.Client
+	Config     *rest.Config
+}
+
+// ClientCacheStats holds the counters of a ClientCache.
+type ClientCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// ClientCache is a bounded LRU cache of identities and Kubernetes clients, keyed by a hash of the token.
+// Entries expire after a TTL, so that revoked tokens stop being served from the cache.
+// All the clients created by the cache share a single underlying transport.
+type ClientCache struct {
+	entries       *cache.LRUExpireCache
+	ttl           time.Duration
+	scheme        *runtime.Scheme
+	kubeApiServer string
+	transport     http.RoundTripper
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	statsInterval time.Duration
+}
+
+// NewClientCacheFromEnv returns a new ClientCache sized by the CLIENT_CACHE_SIZE and CLIENT_CACHE_TTL environment variables,
+// which reports its stats every CLIENT_CACHE_STATS_INTERVAL.
+func NewClientCacheFromEnv(scheme *runtime.Scheme) (*ClientCache, error) {
+	size, err := utils.GetEnvNumber(envClientCacheSize, defaultClientCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := utils.GetEnvDuration(envClientCacheTTL, defaultClientCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	statsInterval, err := utils.GetEnvDuration(envClientCacheStatsInterval, defaultClientCacheStatsInterval)
+	if err != nil {
+		return nil, err
+	}
+	if statsInterval <= 0 {
+		return nil, fmt.Errorf("client cache stats interval must be positive, got %v", statsInterval)
+	}
+
+	clientCache, err := NewClientCache(scheme, size, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCache.statsInterval = statsInterval
+	return clientCache, nil
+}
+
+// NewClientCache returns a new ClientCache holding at most size entries, each for at most ttl.
+func NewClientCache(scheme *runtime.Scheme, size int, ttl time.Duration) (*ClientCache, error) {
+	return newClientCacheWithClock(scheme, size, ttl, clock.RealClock{})
+}
+
+// newClientCacheWithClock returns a new ClientCache which uses the given clock to expire entries.
+func newClientCacheWithClock(scheme *runtime.Scheme, size int, ttl time.Duration, clock cache.Clock) (*ClientCache, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("client cache size must be positive, got %d", size)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("client cache TTL must be positive, got %v", ttl)
+	}
+
+	kubeApiServer := os.Getenv(utils.EnvKubeAPIServer)
+	config, err := createKubernetesConfig("", kubeApiServer)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedTransport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shared transport: %v", err)
+	}
+
+	return &ClientCache{
+		entries:       cache.NewLRUExpireCacheWithClock(size, clock),
+		ttl:           ttl,
+		scheme:        scheme,
+		kubeApiServer: kubeApiServer,
+		transport:     sharedTransport,
+		statsInterval: defaultClientCacheStatsInterval,
+	}, nil
+}
+
+// Get returns the cached clients of the token, if they exist and have not expired.
+func (c *ClientCache) Get(token string) (*CachedClients, bool) {
+	value, ok := c.entries.Get(hashToken(token))
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return value.(*CachedClients), true
+}
+
+// Add caches the clients of the token, evicting the least recently used entry if the cache is full.
+func (c *ClientCache) Add(token string, clients *CachedClients) {
+	c.entries.Add(hashToken(token), clients, c.ttl)
+}
+
+// Remove removes the cached clients of the token.
+func (c *ClientCache) Remove(token string) {
+	c.entries.Remove(hashToken(token))
+}
+
+// Len returns the number of entries in the cache, including expired entries which were not evicted yet.
+func (c *ClientCache) Len() int {
+	return len(c.entries.Keys())
+}
+
+// Stats returns the hit and miss counters of the cache.
+func (c *ClientCache) Stats() ClientCacheStats {
+	return ClientCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+// ReportStats logs the stats of the cache every stats interval, until the context is done.
+func (c *ClientCache) ReportStats(ctx context.Context, logger *zap.Logger) {
+	ticker := time.NewTicker(c.statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.logStats(logger)
+		}
+	}
+}
+
+// logStats logs the hit and miss counters and the number of entries of the cache.
+func (c *ClientCache) logStats(logger *zap.Logger) {
+	stats := c.Stats()
+	logger.Info("Client cache stats",
+		zap.Uint64("hits", stats.Hits),
+		zap.Uint64("misses", stats.Misses),
+		zap.Int("entries", c.Len()))
+}
+
+// NewClients creates the Kubernetes clients for the token on top of the shared transport.
+func (c *ClientCache) NewClients(token string, identity auth.Identity) (*CachedClients, error) {
+	config, err := createKubernetesConfig(token, c.kubeApiServer)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Transport: transport.NewBearerAuthRoundTripper(token, c.transport)}
+
+	kubeClient, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	dynClient, err := client.New(config, client.Options{Scheme: c.scheme, HTTPClient: httpClient})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes dynamic client: %v", err)
+	}
+
+	return &CachedClients{
+		Identity:   identity,
+		KubeClient: kubeClient,
+		DynClient:  dynClient,
+		Config:     config,
+	}, nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of the token, so that raw tokens are not used as cache keys.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/auth"
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// countingTokenProvider is a TokenProvider which counts the identity lookups it serves.
+type countingTokenProvider struct {
+	MockTokenProvider
+	userInfoURL string
+	lookups     *atomic.Int32
+}
+
+func (p countingTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
+	p.lookups.Add(1)
+	if p.userInfoURL != "" {
+		resp, err := http.Get(p.userInfoURL)
+		if err != nil {
+			return "", err
+		}
+		_ = resp.Body.Close()
+	}
+
+	return p.MockTokenProvider.ObtainUsername(token, logger)
+}
+
+func newClientCacheTestRouter(tokenProvider countingTokenProvider, clientCache *ClientCache) *gin.Engine {
+	logger, _ := zap.NewDevelopment()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", logger)
+		c.Next()
+	})
+	router.Use(ErrorHandlingMiddleware())
+	router.Use(TokenAuthMiddleware(tokenProvider, clientCache, nil))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return router
+}
+
+func sendClientCacheTestRequest(router *gin.Engine, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(httpAuthorizationHeader, httpBearerTokenPrefix+" "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestClientCache(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	type want struct {
+		lookups int32
+		stats   ClientCacheStats
+		len     int
+	}
+	cases := map[string]struct {
+		size    int
+		steps   []string
+		advance time.Duration
+		want    want
+	}{
+		"ShouldHitForRepeatedToken": {
+			size:  2,
+			steps: []string{"token-a", "token-a", "token-a"},
+			want:  want{lookups: 1, stats: ClientCacheStats{Hits: 2, Misses: 1}, len: 1},
+		},
+		"ShouldMissForDifferentTokens": {
+			size:  2,
+			steps: []string{"token-a", "token-b"},
+			want:  want{lookups: 2, stats: ClientCacheStats{Hits: 0, Misses: 2}, len: 2},
+		},
+		"ShouldEvictLeastRecentlyUsedToken": {
+			size:  2,
+			steps: []string{"token-a", "token-b", "token-a", "token-c", "token-b"},
+			want:  want{lookups: 4, stats: ClientCacheStats{Hits: 1, Misses: 4}, len: 2},
+		},
+		"ShouldMissAfterTTL": {
+			size:    2,
+			steps:   []string{"token-a", "expire", "token-a"},
+			advance: defaultClientCacheTTL + time.Second,
+			want:    want{lookups: 2, stats: ClientCacheStats{Hits: 0, Misses: 2}, len: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clock := testingclock.NewFakeClock(time.Now())
+			clientCache, err := newClientCacheWithClock(newScheme(), tc.size, defaultClientCacheTTL, clock)
+			assert.NoError(t, err)
+
+			lookups := &atomic.Int32{}
+			router := newClientCacheTestRouter(countingTokenProvider{
+				MockTokenProvider: MockTokenProvider{Username: "user"},
+				lookups:           lookups,
+			}, clientCache)
+
+			for _, step := range tc.steps {
+				if step == "expire" {
+					clock.Step(tc.advance)
+					continue
+				}
+				assert.Equal(t, http.StatusOK, sendClientCacheTestRequest(router, step))
+			}
+
+			assert.Equal(t, tc.want.lookups, lookups.Load())
+			assert.Equal(t, tc.want.stats, clientCache.Stats())
+			assert.Equal(t, tc.want.len, clientCache.Len())
+		})
+	}
+}
+
+func TestClientCacheDoesNotCacheFailures(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	assert.NoError(t, err)
+
+	lookups := &atomic.Int32{}
+	router := newClientCacheTestRouter(countingTokenProvider{
+		MockTokenProvider: MockTokenProvider{Err: fmt.Errorf("invalid token")},
+		lookups:           lookups,
+	}, clientCache)
+
+	assert.Equal(t, http.StatusInternalServerError, sendClientCacheTestRequest(router, "invalid"))
+	assert.Equal(t, http.StatusInternalServerError, sendClientCacheTestRequest(router, "invalid"))
+	assert.Equal(t, int32(2), lookups.Load())
+	assert.Equal(t, 0, clientCache.Len())
+}
+
+func TestClientCacheRemove(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	assert.NoError(t, err)
+
+	clients, err := clientCache.NewClients("token", auth.Identity{Username: "user"})
+	assert.NoError(t, err)
+	clientCache.Add("token", clients)
+
+	cached, ok := clientCache.Get("token")
+	assert.True(t, ok)
+	assert.Equal(t, clients, cached)
+
+	clientCache.Remove("token")
+	_, ok = clientCache.Get("token")
+	assert.False(t, ok)
+}
+
+func TestClientCacheReportStats(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	assert.NoError(t, err)
+	clientCache.statsInterval = 10 * time.Millisecond
+
+	clients, err := clientCache.NewClients("token", auth.Identity{Username: "user"})
+	assert.NoError(t, err)
+	clientCache.Add("token", clients)
+	clientCache.Get("token")
+	clientCache.Get("other-token")
+
+	core, logs := observer.New(zap.InfoLevel)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go clientCache.ReportStats(ctx, zap.New(core))
+
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessage("Client cache stats").Len() > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]interface{}{"hits": uint64(1), "misses": uint64(1), "entries": int64(1)},
+		logs.FilterMessage("Client cache stats").All()[0].ContextMap())
+}
+
+func TestNewClientCacheWithInvalidSettings(t *testing.T) {
+	_, err := NewClientCache(newScheme(), 0, defaultClientCacheTTL)
+	assert.Error(t, err)
+
+	_, err = NewClientCache(newScheme(), defaultClientCacheSize, 0)
+	assert.Error(t, err)
+}
+
+// BenchmarkTokenAuthMiddleware compares requests which resolve the identity and create clients
+// on every call with requests served from the client cache. The identity lookup goes through
+// a local HTTP server, like the userinfo call made against the cluster.
+func BenchmarkTokenAuthMiddleware(b *testing.B) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+	gin.SetMode(gin.ReleaseMode)
+
+	userInfo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer userInfo.Close()
+
+	newRouter := func(b *testing.B) *gin.Engine {
+		clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return newClientCacheTestRouter(countingTokenProvider{
+			MockTokenProvider: MockTokenProvider{Username: "user"},
+			userInfoURL:       userInfo.URL,
+			lookups:           &atomic.Int32{},
+		}, clientCache)
+	}
+
+	b.Run("Uncached", func(b *testing.B) {
+		router := newRouter(b)
+		for i := 0; i < b.N; i++ {
+			sendClientCacheTestRequest(router, fmt.Sprintf("token-%d", i))
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		router := newRouter(b)
+		for i := 0; i < b.N; i++ {
+			sendClientCacheTestRequest(router, "token")
+		}
+	})
+}
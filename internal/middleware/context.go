@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/dana-team/platform-backend/internal/auth"
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -36,6 +37,15 @@ func GetConfig(c *gin.Context) (*rest.Config, error) {
 	return config.(*rest.Config), nil
 }
 
+// GetToken retrieves the token of the authenticated user from the gin.Context.
+func GetToken(c *gin.Context) (string, error) {
+	token, exists := c.Get(TokenCtxKey)
+	if !exists {
+		return "", c.Error(customerrors.NewNotFoundError("token not found in context"))
+	}
+	return token.(string), nil
+}
+
 // GetLogger retrieves the logger from the gin.Context.
 func GetLogger(c *gin.Context) (*zap.Logger, error) {
 	logger, exists := c.Get(LoggerCtxKey)
@@ -45,6 +55,15 @@ func GetLogger(c *gin.Context) (*zap.Logger, error) {
 	return logger.(*zap.Logger), nil
 }
 
+// GetIdentity retrieves the identity of the authenticated user from the gin.Context.
+func GetIdentity(c *gin.Context) (auth.Identity, error) {
+	identity, exists := c.Get(IdentityCtxKey)
+	if !exists {
+		return auth.Identity{}, c.Error(customerrors.NewNotFoundError("identity not found in context"))
+	}
+	return identity.(auth.Identity), nil
+}
+
 // GetCluster retrieves the cluster from the gin.Context.
 func GetCluster(c *gin.Context) (string, bool) {
 	cluster, exists := c.Get(ClusterCtxKey)
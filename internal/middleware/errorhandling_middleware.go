@@ -7,6 +7,11 @@ import (
 	"github.com/gin-gonic/gin"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
+	"strconv"
+)
+
+const (
+	retryAfterHeader = "Retry-After"
 )
 
 const (
@@ -43,6 +48,11 @@ func ErrorHandlingMiddleware() gin.HandlerFunc {
 			errorReason = string(k8sErr.ErrStatus.Reason)
 		}
 
+		var tooManyRequestsErr *customerrors.TooManyRequestsError
+		if errors.As(lastError.Err, &tooManyRequestsErr) {
+			c.Header(retryAfterHeader, strconv.Itoa(tooManyRequestsErr.RetryAfterSeconds()))
+		}
+
 		errorResponse := types.ErrorResponse{
 			Error:  errorMessage,
 			Reason: errorReason,
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"errors"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"net/http/httptest"
@@ -57,6 +60,10 @@ func Test_ErrorHandlingMiddleware(t *testing.T) {
 		})
 	})
 
+	router.GET("/too-many-requests", func(c *gin.Context) {
+		_ = c.Error(customerrors.NewTooManyRequestsError("too many requests", 1500*time.Millisecond))
+	})
+
 	router.GET("/unknown-error", func(c *gin.Context) {
 		_ = c.Error(errors.New("unknown error message"))
 	})
@@ -65,8 +72,9 @@ func Test_ErrorHandlingMiddleware(t *testing.T) {
 		path string
 	}
 	type want struct {
-		expectedStatus int
-		expectedBody   string
+		expectedStatus     int
+		expectedBody       string
+		expectedRetryAfter string
 	}
 	cases := map[string]struct {
 		args args
@@ -93,6 +101,14 @@ func Test_ErrorHandlingMiddleware(t *testing.T) {
 				expectedBody:   `{"error":"k8s error message","reason":"NotFound"}`,
 			},
 		},
+		"ShouldReturnTooManyRequestsErrorWithRetryAfter": {
+			args: args{path: "/too-many-requests"},
+			want: want{
+				expectedStatus:     http.StatusTooManyRequests,
+				expectedBody:       `{"error":"too many requests","reason":"TooManyRequests"}`,
+				expectedRetryAfter: "2",
+			},
+		},
 		"ShouldReturnUnknownError": {
 			args: args{path: "/unknown-error"},
 			want: want{
@@ -115,6 +131,10 @@ func Test_ErrorHandlingMiddleware(t *testing.T) {
 			if w.Body.String() != tc.want.expectedBody {
 				t.Errorf("Expected body %s; got %s", tc.want.expectedBody, w.Body.String())
 			}
+
+			if retryAfter := w.Header().Get(retryAfterHeader); retryAfter != tc.want.expectedRetryAfter {
+				t.Errorf("Expected Retry-After header %q; got %q", tc.want.expectedRetryAfter, retryAfter)
+			}
 		})
 	}
 }
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+const (
+	envSessionEnabled       = "SESSION_ENABLED"
+	envSessionEncryptionKey = "SESSION_ENCRYPTION_KEY"
+	envSessionSameSite      = "SESSION_SAME_SITE"
+	envSessionMaxAge        = "SESSION_MAX_AGE"
+)
+
+const (
+	SessionCookieName = "platform-session"
+	CSRFCookieName    = "platform-csrf"
+	CSRFHeader        = "X-CSRF-Token"
+)
+
+const (
+	sessionKeyLength     = 32
+	csrfTokenLength      = 32
+	defaultSessionMaxAge = 24 * time.Hour
+)
+
+var (
+	ErrSessionNotFound = errors.New("session cookie not provided")
+	ErrInvalidSession  = errors.New("invalid session cookie")
+	ErrInvalidCSRF     = errors.New("missing or invalid CSRF token")
+	ErrSessionExpired  = errors.New("the token of the session expired, refresh it")
+)
+
+// session is the payload encrypted into the session cookie. The CSRF token is kept
+// in the payload, so that a CSRF cookie planted by another site does not match it.
+// The expiry of the access token is kept in the payload as well, since the cookie outlives it.
+type session struct {
+	Token        string    `json:"token"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	CSRFToken    string    `json:"csrfToken"`
+}
+
+// SessionManager stores tokens in encrypted HttpOnly cookies, for browser clients which should not
+// keep tokens in JS-accessible storage. Requests authenticated by the cookie which mutate resources
+// must send the CSRF token of the session in the X-CSRF-Token header (double-submit).
+type SessionManager struct {
+	aead     cipher.AEAD
+	sameSite http.SameSite
+	maxAge   time.Duration
+}
+
+// NewSessionManagerFromEnv returns a new SessionManager configured by the SESSION_* environment variables,
+// or nil if the session mode is disabled. Sessions last at most SESSION_MAX_AGE.
+func NewSessionManagerFromEnv() (*SessionManager, error) {
+	enabled, err := utils.GetEnvBool(envSessionEnabled, false)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(os.Getenv(envSessionEncryptionKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", envSessionEncryptionKey, err)
+	}
+
+	sameSite, err := parseSameSite(os.Getenv(envSessionSameSite))
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge, err := utils.GetEnvDuration(envSessionMaxAge, defaultSessionMaxAge)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("session max age must be positive, got %v", maxAge)
+	}
+
+	sessionManager, err := NewSessionManager(key, sameSite)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionManager.maxAge = maxAge
+	return sessionManager, nil
+}
+
+// NewSessionManager returns a new SessionManager which encrypts sessions with AES-256-GCM using the given key.
+func NewSessionManager(key []byte, sameSite http.SameSite) (*SessionManager, error) {
+	if len(key) != sessionKeyLength {
+		return nil, fmt.Errorf("session encryption key must be %d bytes long, got %d", sessionKeyLength, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionManager{aead: aead, sameSite: sameSite, maxAge: defaultSessionMaxAge}, nil
+}
+
+// SetSession sets the session cookie holding the access and refresh tokens, along with a cookie holding
+// a new CSRF token, which is returned. The cookies last for the max age of the session, so that the access
+// token can be refreshed once it expires, unless there is no refresh token and the access token expires sooner.
+func (s *SessionManager) SetSession(c *gin.Context, token *oauth2.Token) (string, error) {
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	value, err := s.encrypt(session{Token: token.AccessToken, Expiry: token.Expiry, RefreshToken: token.RefreshToken, CSRFToken: csrfToken})
+	if err != nil {
+		return "", err
+	}
+
+	expiry := time.Now().Add(s.maxAge)
+	if token.RefreshToken == "" && !token.Expiry.IsZero() && token.Expiry.Before(expiry) {
+		expiry = token.Expiry
+	}
+
+	s.setCookie(c, SessionCookieName, value, true, expiry)
+	s.setCookie(c, CSRFCookieName, csrfToken, false, expiry)
+
+	return csrfToken, nil
+}
+
+// ClearSession expires the session and CSRF cookies.
+func (s *SessionManager) ClearSession(c *gin.Context) {
+	for _, name := range []string{SessionCookieName, CSRFCookieName} {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     name,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: name == SessionCookieName,
+			Secure:   true,
+			SameSite: s.sameSite,
+		})
+	}
+}
+
+// TokenFromSession returns the token held by the session cookie of the request, unless it expired. Requests
+// with unsafe methods must carry the CSRF token of the session in the X-CSRF-Token header.
+func (s *SessionManager) TokenFromSession(c *gin.Context) (string, error) {
+	sess, err := s.readSession(c, !isSafeMethod(c.Request.Method))
+	if err != nil {
+		return "", err
+	}
+
+	if !sess.Expiry.IsZero() && !time.Now().Before(sess.Expiry) {
+		return "", ErrSessionExpired
+	}
+
+	return sess.Token, nil
+}
+
+// RefreshTokenFromSession returns the refresh token held by the session cookie of the request, if any.
+// The request must carry the CSRF token of the session in the X-CSRF-Token header, whatever its method.
+func (s *SessionManager) RefreshTokenFromSession(c *gin.Context) (string, error) {
+	sess, err := s.readSession(c, true)
+	if err != nil {
+		return "", err
+	}
+
+	return sess.RefreshToken, nil
+}
+
+// readSession decrypts the session cookie of the request, and checks its CSRF token if required.
+func (s *SessionManager) readSession(c *gin.Context, checkCSRF bool) (session, error) {
+	cookie, err := c.Cookie(SessionCookieName)
+	if err != nil || cookie == "" {
+		return session{}, ErrSessionNotFound
+	}
+
+	sess, err := s.decrypt(cookie)
+	if err != nil {
+		return session{}, err
+	}
+
+	if checkCSRF {
+		csrfToken := c.GetHeader(CSRFHeader)
+		if csrfToken == "" || subtle.ConstantTimeCompare([]byte(csrfToken), []byte(sess.CSRFToken)) != 1 {
+			return session{}, ErrInvalidCSRF
+		}
+	}
+
+	return sess, nil
+}
+
+// setCookie sets a Secure cookie on the response, which expires at the given time.
+func (s *SessionManager) setCookie(c *gin.Context, name, value string, httpOnly bool, expiry time.Time) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expiry,
+		HttpOnly: httpOnly,
+		Secure:   true,
+		SameSite: s.sameSite,
+	})
+}
+
+// encrypt seals the session and returns it as a base64 cookie value, prefixed by the nonce.
+func (s *SessionManager) encrypt(sess session) (string, error) {
+	plaintext, err := json.Marshal(sess)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// decrypt opens a cookie value created by encrypt.
+func (s *SessionManager) decrypt(value string) (session, error) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(ciphertext) < s.aead.NonceSize() {
+		return session{}, ErrInvalidSession
+	}
+
+	nonce, ciphertext := ciphertext[:s.aead.NonceSize()], ciphertext[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return session{}, ErrInvalidSession
+	}
+
+	var sess session
+	if err := json.Unmarshal(plaintext, &sess); err != nil || sess.Token == "" {
+		return session{}, ErrInvalidSession
+	}
+
+	return sess, nil
+}
+
+// generateCSRFToken returns a new random CSRF token.
+func generateCSRFToken() (string, error) {
+	token := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// isSafeMethod returns true for HTTP methods which do not mutate resources.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// parseSameSite parses a SameSite cookie attribute, which defaults to Strict.
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "strict":
+		return http.SameSiteStrictMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("invalid SameSite value %q, must be one of strict, lax or none", value)
+	}
+}
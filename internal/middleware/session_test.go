@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	sessionTestToken        = "session_token"
+	sessionTestRefreshToken = "session_refresh_token"
+	sessionTestUser         = "user"
+)
+
+var sessionTestKey = []byte("0123456789abcdef0123456789abcdef")
+
+// newSessionTestCookies logs in through the session manager with a token expiring in an hour,
+// and returns the cookies and CSRF token of the session.
+func newSessionTestCookies(t *testing.T, sessionManager *SessionManager) ([]*http.Cookie, string) {
+	return newSessionTestCookiesWithToken(t, sessionManager, &oauth2.Token{AccessToken: sessionTestToken, Expiry: time.Now().Add(time.Hour)})
+}
+
+// newSessionTestCookiesWithToken logs in through the session manager with the token, and returns the cookies and CSRF token of the session.
+func newSessionTestCookiesWithToken(t *testing.T, sessionManager *SessionManager, token *oauth2.Token) ([]*http.Cookie, string) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+
+	csrfToken, err := sessionManager.SetSession(c, token)
+	assert.NoError(t, err)
+
+	return w.Result().Cookies(), csrfToken
+}
+
+func TestSessionManagerSetSession(t *testing.T) {
+	sessionManager, err := NewSessionManager(sessionTestKey, http.SameSiteStrictMode)
+	assert.NoError(t, err)
+
+	type want struct {
+		expiry time.Time
+	}
+	cases := map[string]struct {
+		token *oauth2.Token
+		want  want
+	}{
+		"ShouldOutliveAccessTokenWithRefreshToken": {
+			token: &oauth2.Token{AccessToken: sessionTestToken, RefreshToken: sessionTestRefreshToken, Expiry: time.Now().Add(time.Hour)},
+			want:  want{expiry: time.Now().Add(defaultSessionMaxAge)},
+		},
+		"ShouldExpireWithAccessTokenWithoutRefreshToken": {
+			token: &oauth2.Token{AccessToken: sessionTestToken, Expiry: time.Now().Add(time.Hour)},
+			want:  want{expiry: time.Now().Add(time.Hour)},
+		},
+		"ShouldLastForMaxAgeWithoutAccessTokenExpiry": {
+			token: &oauth2.Token{AccessToken: sessionTestToken},
+			want:  want{expiry: time.Now().Add(defaultSessionMaxAge)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cookies, _ := newSessionTestCookiesWithToken(t, sessionManager, tc.token)
+			assert.Len(t, cookies, 2)
+			for _, cookie := range cookies {
+				assert.WithinDuration(t, tc.want.expiry, cookie.Expires, time.Minute)
+			}
+		})
+	}
+
+	cookies, csrfToken := newSessionTestCookies(t, sessionManager)
+	assert.Len(t, cookies, 2)
+	for _, cookie := range cookies {
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+		switch cookie.Name {
+		case SessionCookieName:
+			assert.True(t, cookie.HttpOnly)
+			assert.NotContains(t, cookie.Value, sessionTestToken)
+		case CSRFCookieName:
+			assert.False(t, cookie.HttpOnly)
+			assert.Equal(t, csrfToken, cookie.Value)
+		default:
+			t.Errorf("Unexpected cookie %q", cookie.Name)
+		}
+	}
+}
+
+func TestTokenAuthMiddlewareWithSession(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	sessionManager, err := NewSessionManager(sessionTestKey, http.SameSiteStrictMode)
+	assert.NoError(t, err)
+	otherSessionManager, err := NewSessionManager([]byte("fedcba9876543210fedcba9876543210"), http.SameSiteStrictMode)
+	assert.NoError(t, err)
+
+	cookies, csrfToken := newSessionTestCookies(t, sessionManager)
+	otherCookies, _ := newSessionTestCookies(t, otherSessionManager)
+	expiredCookies, _ := newSessionTestCookiesWithToken(t, sessionManager, &oauth2.Token{
+		AccessToken:  sessionTestToken,
+		RefreshToken: sessionTestRefreshToken,
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	assert.NoError(t, err)
+
+	logger, _ := zap.NewDevelopment()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", logger)
+		c.Next()
+	})
+	router.Use(ErrorHandlingMiddleware())
+	router.Use(TokenAuthMiddleware(MockTokenProvider{Username: sessionTestUser}, clientCache, sessionManager))
+	router.Any("/ping", func(c *gin.Context) {
+		token, err := GetToken(c)
+		assert.NoError(t, err)
+		c.JSON(http.StatusOK, gin.H{"token": token})
+	})
+
+	type args struct {
+		method     string
+		cookies    []*http.Cookie
+		csrfToken  string
+		authHeader string
+	}
+	type want struct {
+		expectedStatus int
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedReadingWithSessionCookie": {
+			args: args{method: http.MethodGet, cookies: cookies},
+			want: want{expectedStatus: http.StatusOK},
+		},
+		"ShouldSucceedMutatingWithSessionCookieAndCSRFToken": {
+			args: args{method: http.MethodPost, cookies: cookies, csrfToken: csrfToken},
+			want: want{expectedStatus: http.StatusOK},
+		},
+		"ShouldFailMutatingWithSessionCookieWithoutCSRFToken": {
+			args: args{method: http.MethodDelete, cookies: cookies},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldFailMutatingWithSessionCookieAndWrongCSRFToken": {
+			args: args{method: http.MethodPut, cookies: cookies, csrfToken: "wrong"},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldFailWithSessionCookieOfAnotherKey": {
+			args: args{method: http.MethodGet, cookies: otherCookies},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldFailWithSessionCookieOfExpiredToken": {
+			args: args{method: http.MethodGet, cookies: expiredCookies},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldSucceedMutatingWithBearerTokenWithoutCSRFToken": {
+			args: args{method: http.MethodPost, authHeader: httpBearerTokenPrefix + " " + sessionTestToken},
+			want: want{expectedStatus: http.StatusOK},
+		},
+		"ShouldSucceedWithWebSocketProtocolHeader": {
+			args: args{method: http.MethodGet},
+			want: want{expectedStatus: http.StatusOK},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.args.method, "/ping", nil)
+			for _, cookie := range tc.args.cookies {
+				req.AddCookie(cookie)
+			}
+			if tc.args.csrfToken != "" {
+				req.Header.Set(CSRFHeader, tc.args.csrfToken)
+			}
+			if tc.args.authHeader != "" {
+				req.Header.Set(httpAuthorizationHeader, tc.args.authHeader)
+			}
+			if len(tc.args.cookies) == 0 && tc.args.authHeader == "" {
+				req.Header.Set(WebsocketTokenHeader, sessionTestToken)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.want.expectedStatus, w.Code)
+			if tc.want.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, `{"token":"`+sessionTestToken+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessionManagerRefreshTokenFromSession(t *testing.T) {
+	sessionManager, err := NewSessionManager(sessionTestKey, http.SameSiteStrictMode)
+	assert.NoError(t, err)
+
+	cookies, csrfToken := newSessionTestCookiesWithToken(t, sessionManager, &oauth2.Token{
+		AccessToken:  sessionTestToken,
+		RefreshToken: sessionTestRefreshToken,
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	type args struct {
+		method    string
+		csrfToken string
+	}
+	type want struct {
+		refreshToken string
+		err          error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedWithCSRFTokenOnceAccessTokenExpired": {
+			args: args{method: http.MethodPost, csrfToken: csrfToken},
+			want: want{refreshToken: sessionTestRefreshToken},
+		},
+		"ShouldFailWithoutCSRFToken": {
+			args: args{method: http.MethodPost},
+			want: want{err: ErrInvalidCSRF},
+		},
+		"ShouldFailWithoutCSRFTokenForSafeMethod": {
+			args: args{method: http.MethodGet},
+			want: want{err: ErrInvalidCSRF},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(tc.args.method, "/login/refresh", nil)
+			for _, cookie := range cookies {
+				c.Request.AddCookie(cookie)
+			}
+			if tc.args.csrfToken != "" {
+				c.Request.Header.Set(CSRFHeader, tc.args.csrfToken)
+			}
+
+			refreshToken, err := sessionManager.RefreshTokenFromSession(c)
+			assert.ErrorIs(t, err, tc.want.err)
+			assert.Equal(t, tc.want.refreshToken, refreshToken)
+		})
+	}
+}
+
+func TestNewSessionManagerFromEnv(t *testing.T) {
+	_ = os.Setenv(envSessionEnabled, "false")
+	sessionManager, err := NewSessionManagerFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, sessionManager)
+
+	_ = os.Setenv(envSessionEnabled, "true")
+	_ = os.Setenv(envSessionEncryptionKey, "c2hvcnQ=")
+	_, err = NewSessionManagerFromEnv()
+	assert.Error(t, err)
+
+	_ = os.Setenv(envSessionEncryptionKey, "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	_ = os.Setenv(envSessionSameSite, "sideways")
+	_, err = NewSessionManagerFromEnv()
+	assert.Error(t, err)
+
+	_ = os.Setenv(envSessionSameSite, "lax")
+	sessionManager, err = NewSessionManagerFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, http.SameSiteLaxMode, sessionManager.sameSite)
+	assert.Equal(t, defaultSessionMaxAge, sessionManager.maxAge)
+
+	_ = os.Setenv(envSessionMaxAge, "-1h")
+	_, err = NewSessionManagerFromEnv()
+	assert.Error(t, err)
+
+	_ = os.Setenv(envSessionMaxAge, "8h")
+	sessionManager, err = NewSessionManagerFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 8*time.Hour, sessionManager.maxAge)
+
+	_ = os.Unsetenv(envSessionEnabled)
+	_ = os.Unsetenv(envSessionEncryptionKey)
+	_ = os.Unsetenv(envSessionSameSite)
+	_ = os.Unsetenv(envSessionMaxAge)
+}
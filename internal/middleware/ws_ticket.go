@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"k8s.io/utils/clock"
+)
+
+const (
+	envWSTicketTTL = "WS_TICKET_TTL"
+)
+
+const (
+	defaultWSTicketTTL = 30 * time.Second
+)
+
+const (
+	WSTicketQueryKey = "ticket"
+	wsTicketLength   = 32
+	wsRoutePrefix    = "/ws/"
+)
+
+var (
+	ErrInvalidWSTicket = errors.New("invalid or expired WebSocket ticket")
+	ErrInvalidWSPath   = fmt.Errorf("WebSocket ticket path must start with %q", wsRoutePrefix)
+)
+
+// wsTicket is a ticket held by a WSTicketStore.
+type wsTicket struct {
+	token     string
+	username  string
+	path      string
+	expiresAt time.Time
+}
+
+// WSTicketStore issues short-lived, single-use tickets which authenticate a single WebSocket route,
+// so that clients do not have to send their token in the Sec-Websocket-Protocol header.
+// Tickets are kept in memory, so a ticket must be redeemed by the replica which issued it.
+type WSTicketStore struct {
+	mu        sync.Mutex
+	tickets   map[string]wsTicket
+	ttl       time.Duration
+	clock     clock.PassiveClock
+	lastSweep time.Time
+}
+
+// NewWSTicketStoreFromEnv returns a new WSTicketStore whose tickets are valid for WS_TICKET_TTL.
+func NewWSTicketStoreFromEnv() (*WSTicketStore, error) {
+	ttl, err := utils.GetEnvDuration(envWSTicketTTL, defaultWSTicketTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWSTicketStore(ttl)
+}
+
+// NewWSTicketStore returns a new WSTicketStore whose tickets are valid for the given TTL.
+func NewWSTicketStore(ttl time.Duration) (*WSTicketStore, error) {
+	return newWSTicketStoreWithClock(ttl, clock.RealClock{})
+}
+
+// newWSTicketStoreWithClock returns a new WSTicketStore which uses the given clock to expire tickets.
+func newWSTicketStoreWithClock(ttl time.Duration, clock clock.PassiveClock) (*WSTicketStore, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("WebSocket ticket TTL must be positive, got %v", ttl)
+	}
+
+	return &WSTicketStore{
+		tickets:   map[string]wsTicket{},
+		ttl:       ttl,
+		clock:     clock,
+		lastSweep: clock.Now(),
+	}, nil
+}
+
+// Issue returns a new ticket which redeems the token of the user on the given WebSocket route, along with its expiry.
+func (s *WSTicketStore) Issue(token, username, path string) (string, time.Time, error) {
+	if !strings.HasPrefix(path, wsRoutePrefix) {
+		return "", time.Time{}, ErrInvalidWSPath
+	}
+
+	ticket, err := generateWSTicket()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.sweep(now)
+
+	expiresAt := now.Add(s.ttl)
+	s.tickets[hashToken(ticket)] = wsTicket{
+		token:     token,
+		username:  username,
+		path:      path,
+		expiresAt: expiresAt,
+	}
+
+	return ticket, expiresAt, nil
+}
+
+// Redeem consumes the ticket and returns the token and username it was issued for.
+// It fails if the ticket does not exist, has expired, or was issued for another route.
+func (s *WSTicketStore) Redeem(ticket, path string) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := hashToken(ticket)
+	entry, ok := s.tickets[key]
+	if !ok {
+		return "", "", ErrInvalidWSTicket
+	}
+
+	// The ticket is consumed even if it does not match, so that it cannot be guessed against several routes.
+	delete(s.tickets, key)
+	if !s.clock.Now().Before(entry.expiresAt) || entry.path != path {
+		return "", "", ErrInvalidWSTicket
+	}
+
+	return entry.token, entry.username, nil
+}
+
+// sweep removes the expired tickets, at most once per TTL, so that unredeemed tickets
+// do not grow the store forever. It must be called with the lock held.
+func (s *WSTicketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+
+	for key, entry := range s.tickets {
+		if !now.Before(entry.expiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// generateWSTicket returns a new random ticket.
+func generateWSTicket() (string, error) {
+	ticket := make([]byte, wsTicketLength)
+	if _, err := rand.Read(ticket); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(ticket), nil
+}
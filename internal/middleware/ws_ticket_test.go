@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dana-team/platform-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+const (
+	wsTicketTestToken = "ws_token"
+	wsTicketTestUser  = "user"
+	wsTicketTestPath  = "/ws/namespaces/test/pods/test/logs"
+)
+
+func TestWSTicketStore(t *testing.T) {
+	type args struct {
+		issuePath  string
+		redeemPath string
+		elapsed    time.Duration
+		redeems    int
+	}
+	type want struct {
+		issueError  error
+		redeemError error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedRedeemingTicket": {
+			args: args{issuePath: wsTicketTestPath, redeemPath: wsTicketTestPath, redeems: 1},
+		},
+		"ShouldFailRedeemingTicketTwice": {
+			args: args{issuePath: wsTicketTestPath, redeemPath: wsTicketTestPath, redeems: 2},
+			want: want{redeemError: ErrInvalidWSTicket},
+		},
+		"ShouldFailRedeemingTicketOnAnotherRoute": {
+			args: args{issuePath: wsTicketTestPath, redeemPath: "/ws/namespaces/test/pods/other/logs", redeems: 1},
+			want: want{redeemError: ErrInvalidWSTicket},
+		},
+		"ShouldFailRedeemingExpiredTicket": {
+			args: args{issuePath: wsTicketTestPath, redeemPath: wsTicketTestPath, elapsed: defaultWSTicketTTL, redeems: 1},
+			want: want{redeemError: ErrInvalidWSTicket},
+		},
+		"ShouldFailIssuingTicketForNonWebSocketRoute": {
+			args: args{issuePath: "/v1/namespaces"},
+			want: want{issueError: ErrInvalidWSPath},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fakeClock := testingclock.NewFakePassiveClock(time.Now())
+			store, err := newWSTicketStoreWithClock(defaultWSTicketTTL, fakeClock)
+			assert.NoError(t, err)
+
+			ticket, expiresAt, err := store.Issue(wsTicketTestToken, wsTicketTestUser, tc.args.issuePath)
+			if tc.want.issueError != nil {
+				assert.ErrorIs(t, err, tc.want.issueError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, fakeClock.Now().Add(defaultWSTicketTTL), expiresAt)
+
+			fakeClock.SetTime(fakeClock.Now().Add(tc.args.elapsed))
+			var token, username string
+			for i := 0; i < tc.args.redeems; i++ {
+				token, username, err = store.Redeem(ticket, tc.args.redeemPath)
+			}
+			if tc.want.redeemError != nil {
+				assert.ErrorIs(t, err, tc.want.redeemError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, wsTicketTestToken, token)
+			assert.Equal(t, wsTicketTestUser, username)
+		})
+	}
+}
+
+func TestWebSocketAuthMiddleware(t *testing.T) {
+	_ = os.Setenv(utils.EnvKubeAPIServer, "https://example.com/api")
+	_ = os.Setenv(utils.EnvInsecureSkipVerify, "true")
+
+	ticketStore, err := NewWSTicketStore(defaultWSTicketTTL)
+	assert.NoError(t, err)
+
+	clientCache, err := NewClientCache(newScheme(), defaultClientCacheSize, defaultClientCacheTTL)
+	assert.NoError(t, err)
+
+	logger, _ := zap.NewDevelopment()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", logger)
+		c.Next()
+	})
+	router.Use(ErrorHandlingMiddleware())
+	router.Use(WebSocketAuthMiddleware(MockTokenProvider{Username: wsTicketTestUser}, clientCache, nil, ticketStore))
+	router.GET(wsTicketTestPath, func(c *gin.Context) {
+		token, err := GetToken(c)
+		assert.NoError(t, err)
+		c.JSON(http.StatusOK, gin.H{"token": token})
+	})
+
+	issueTicket := func(username string) string {
+		ticket, _, err := ticketStore.Issue(wsTicketTestToken, username, wsTicketTestPath)
+		assert.NoError(t, err)
+		return ticket
+	}
+	usedTicket := issueTicket(wsTicketTestUser)
+
+	type args struct {
+		ticket           string
+		protocolHeader   string
+		redeemBeforehand bool
+	}
+	type want struct {
+		expectedStatus int
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedWithTicket": {
+			args: args{ticket: issueTicket(wsTicketTestUser)},
+			want: want{expectedStatus: http.StatusOK},
+		},
+		"ShouldFailWithUsedTicket": {
+			args: args{ticket: usedTicket, redeemBeforehand: true},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldFailWithTicketOfAnotherUser": {
+			args: args{ticket: issueTicket("other-user")},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldFailWithUnknownTicket": {
+			args: args{ticket: "unknown"},
+			want: want{expectedStatus: http.StatusUnauthorized},
+		},
+		"ShouldSucceedWithWebSocketProtocolHeader": {
+			args: args{protocolHeader: wsTicketTestToken},
+			want: want{expectedStatus: http.StatusOK},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tc.args.redeemBeforehand {
+				_, _, err := ticketStore.Redeem(tc.args.ticket, wsTicketTestPath)
+				assert.NoError(t, err)
+			}
+
+			target := wsTicketTestPath
+			if tc.args.ticket != "" {
+				target += "?" + WSTicketQueryKey + "=" + tc.args.ticket
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tc.args.protocolHeader != "" {
+				req.Header.Set(WebsocketTokenHeader, tc.args.protocolHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.want.expectedStatus, w.Code)
+			if tc.want.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, `{"token":"`+wsTicketTestToken+`"}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+	anyETag       = "*"
+)
+
+const (
+	ErrInvalidIfMatch = "Invalid If-Match header %q, must be a single ETag or %s"
+)
+
+// SetETag sets the ETag of the response to the resource version of the object it holds.
+func SetETag(c *gin.Context, resourceVersion string) {
+	if resourceVersion != "" {
+		c.Header(ETagHeader, strconv.Quote(resourceVersion))
+	}
+}
+
+// GetIfMatch returns the resource version which the If-Match header of the request requires the object to have,
+// or an empty string if the header is unset or matches any object. The header must hold a single ETag, as set by SetETag.
+func GetIfMatch(c *gin.Context) (string, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader(IfMatchHeader))
+	if ifMatch == "" || ifMatch == anyETag {
+		return "", nil
+	}
+
+	resourceVersion, err := strconv.Unquote(ifMatch)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) || resourceVersion == "" {
+		return "", fmt.Errorf(ErrInvalidIfMatch, ifMatch, anyETag)
+	}
+
+	return resourceVersion, nil
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	envTrustedProxies       = "TRUSTED_PROXIES"
+	trustedProxiesSeparator = ","
+)
+
+// SetTrustedProxiesFromEnv sets the proxies whose forwarding headers the engine trusts for the client IP of a request,
+// from a comma-separated list of IPs and CIDRs. No proxy is trusted by default, so the client IP is the remote address
+// of the request and can not be spoofed through the X-Forwarded-For header.
+func SetTrustedProxiesFromEnv(engine *gin.Engine) error {
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv(envTrustedProxies), trustedProxiesSeparator) {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return fmt.Errorf("failed to parse %q: %w", envTrustedProxies, err)
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"fmt"
+
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
 	"github.com/dana-team/platform-backend/internal/routes"
@@ -10,6 +12,7 @@ import (
 	"github.com/dana-team/platform-backend/internal/controllers"
 	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/gin-gonic/gin"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 )
 
 func cappHandler(handler func(controller controllers.CappController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
@@ -71,7 +74,9 @@ func GetCapp() gin.HandlerFunc {
 		}
 
 		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
-			return controller.GetCapp(cappUri.NamespaceName, cappUri.CappName)
+			capp, err := controller.GetCapp(cappUri.NamespaceName, cappUri.CappName)
+			routes.SetETag(c, capp.Metadata.ResourceVersion)
+			return capp, err
 		})(c)
 	}
 }
@@ -114,9 +119,61 @@ func UpdateCapp() gin.HandlerFunc {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		var dryRunQuery types.DryRunQuery
+		if err := c.BindQuery(&dryRunQuery); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+		resourceVersion, err := routes.GetIfMatch(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
+			updatedCapp, err := controller.UpdateCapp(cappUri.NamespaceName, cappUri.CappName, capp, resourceVersion, dryRunQuery.DryRun)
+			routes.SetETag(c, updatedCapp.Metadata.ResourceVersion)
+			return updatedCapp, err
+		})(c)
+	}
+}
+
+func PatchCapp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var cappUri types.CappUri
+		if err := c.BindUri(&cappUri); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		var dryRunQuery types.DryRunQuery
+		if err := c.BindQuery(&dryRunQuery); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		patchType := k8stypes.PatchType(c.ContentType())
+		if patchType != k8stypes.MergePatchType && patchType != k8stypes.JSONPatchType {
+			middleware.AddErrorToContext(c, customerrors.NewUnsupportedMediaTypeError(
+				fmt.Sprintf(controllers.ErrUnsupportedPatchType, patchType, k8stypes.MergePatchType, k8stypes.JSONPatchType)))
+			return
+		}
+
+		patch, err := c.GetRawData()
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+		resourceVersion, err := routes.GetIfMatch(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
 
 		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
-			return controller.UpdateCapp(cappUri.NamespaceName, cappUri.CappName, capp)
+			capp, err := controller.PatchCapp(cappUri.NamespaceName, cappUri.CappName, patchType, patch, resourceVersion, dryRunQuery.DryRun)
+			routes.SetETag(c, capp.Metadata.ResourceVersion)
+			return capp, err
 		})(c)
 	}
 }
@@ -133,9 +190,16 @@ func EditCappState() gin.HandlerFunc {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		resourceVersion, err := routes.GetIfMatch(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
 
 		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
-			return controller.EditCappState(cappUri.NamespaceName, cappUri.CappName, state.State)
+			cappState, err := controller.EditCappState(cappUri.NamespaceName, cappUri.CappName, state.State, resourceVersion)
+			routes.SetETag(c, cappState.ResourceVersion)
+			return cappState, err
 		})(c)
 	}
 }
@@ -149,7 +213,9 @@ func GetCappState() gin.HandlerFunc {
 		}
 
 		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
-			return controller.GetCappState(cappUri.NamespaceName, cappUri.CappName)
+			cappState, err := controller.GetCappState(cappUri.NamespaceName, cappUri.CappName)
+			routes.SetETag(c, cappState.ResourceVersion)
+			return cappState, err
 		})(c)
 	}
 }
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/routes"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	corev1 "k8s.io/api/core/v1"
@@ -209,7 +211,7 @@ func TestGetCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey, Value: testutils.LabelValue}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
@@ -290,6 +292,7 @@ func TestGetCappState(t *testing.T) {
 
 	type want struct {
 		statusCode int
+		eTag       string
 		response   map[string]interface{}
 	}
 
@@ -304,10 +307,12 @@ func TestGetCappState(t *testing.T) {
 			},
 			want: want{
 				statusCode: http.StatusOK,
+				eTag:       strconv.Quote(testutils.CreatedResourceVersion),
 				response: map[string]interface{}{
 					testutils.LastReadyRevision:   fmt.Sprintf("%s-%s-%s", testutils.CappName, testutils.EnabledState, "00001"),
 					testutils.LastCreatedRevision: fmt.Sprintf("%s-%s-%s", testutils.CappName, testutils.EnabledState, "00001"),
 					testutils.StateKey:            testutils.EnabledState,
+					testutils.ResourceVersionKey:  testutils.CreatedResourceVersion,
 				},
 			},
 		},
@@ -318,10 +323,12 @@ func TestGetCappState(t *testing.T) {
 			},
 			want: want{
 				statusCode: http.StatusOK,
+				eTag:       strconv.Quote(testutils.CreatedResourceVersion),
 				response: map[string]interface{}{
 					testutils.LastReadyRevision:   testutils.NoRevision,
 					testutils.LastCreatedRevision: testutils.NoRevision,
 					testutils.StateKey:            testutils.DisabledState,
+					testutils.ResourceVersionKey:  testutils.CreatedResourceVersion,
 				},
 			},
 		},
@@ -374,6 +381,7 @@ func TestGetCappState(t *testing.T) {
 			router.ServeHTTP(writer, request)
 
 			assert.Equal(t, test.want.statusCode, writer.Code)
+			assert.Equal(t, test.want.eTag, writer.Header().Get(routes.ETagHeader))
 
 			var response map[string]interface{}
 			err = json.Unmarshal(writer.Body.Bytes(), &response)
@@ -583,6 +591,11 @@ func TestCreateCapp(t *testing.T) {
 		response   map[string]interface{}
 	}
 
+	cappWithSecretEnv := mocks.PrepareCreateCappType(testutils.CappName+"-10", testutils.SiteName, nil, nil)
+	cappWithSecretEnv.Spec.ConfigurationSpec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: testutils.SecretDataKey, ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: testutils.SecretName}, Key: testutils.SecretDataKey},
+	}}}
+
 	cases := map[string]struct {
 		requestURI  requestURI
 		want        want
@@ -595,7 +608,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey, Value: testutils.LabelValue}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
@@ -612,7 +625,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-2", Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-2", Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-2", Value: testutils.LabelValue + "-2"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.PlacementName + "-1"),
@@ -629,7 +642,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-3", Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-3", Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-3", Value: testutils.LabelValue + "-3"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.PlacementName + "-2"),
@@ -646,7 +659,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-4", Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-4", Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-4", Value: testutils.LabelValue + "-4"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.PlacementName + "-3"),
@@ -663,7 +676,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-5", Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-5", Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-5", Value: testutils.LabelValue + "-5"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.PlacementName + "-4"),
@@ -680,7 +693,7 @@ func TestCreateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-6", Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-6", Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-6", Value: testutils.LabelValue + "-6"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
@@ -689,6 +702,37 @@ func TestCreateCapp(t *testing.T) {
 			},
 			requestData: mocks.PrepareCreateCappType(testutils.CappName+"-6", testutils.SiteName, []types.KeyValue{{Key: testutils.LabelKey + "-6", Value: testutils.LabelValue + "-6"}}, nil),
 		},
+		"ShouldSucceedDryRunCreatingCapp": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				query:     queryParams{keys: []string{testutils.DryRunKey}, values: []string{"true"}},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-9", Namespace: testNamespaceName},
+					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-9", Value: testutils.LabelValue + "-9"}},
+					testutils.AnnotationsKey: nil,
+					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
+					testutils.StatusKey:      cappv1alpha1.CappStatus{},
+				},
+			},
+			requestData: mocks.PrepareCreateCappType(testutils.CappName+"-9", testutils.SiteName, []types.KeyValue{{Key: testutils.LabelKey + "-9", Value: testutils.LabelValue + "-9"}}, nil),
+		},
+		"ShouldFailDryRunCreatingCappWithMissingSecret": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				query:     queryParams{keys: []string{testutils.DryRunKey}, values: []string{"true"}},
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrMissingReferencedSecrets, testutils.CappName+"-10", testNamespaceName, testutils.SecretName),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+			requestData: cappWithSecretEnv,
+		},
 		"ShouldFailCreatingCappWithoutSiteAndWithoutMatchingPlacement": {
 			requestURI: requestURI{
 				namespace: testNamespaceName,
@@ -815,7 +859,7 @@ func TestUpdateCapp(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName, ResourceVersion: testutils.UpdatedResourceVersion},
 					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey + "-updated", Value: testutils.LabelValue + "-updated"}},
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
@@ -890,6 +934,184 @@ func TestUpdateCapp(t *testing.T) {
 	}
 }
 
+func TestPatchCapp(t *testing.T) {
+	testNamespaceName := testutils.CappNamespace + "-patch"
+
+	type requestURI struct {
+		name      string
+		namespace string
+	}
+
+	type want struct {
+		statusCode int
+		eTag       string
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		requestURI  requestURI
+		contentType string
+		ifMatch     string
+		requestData string
+		want        want
+	}{
+		"ShouldSucceedMergePatchingCapp": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-1",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.MergePatchJson,
+			ifMatch:     strconv.Quote(testutils.CreatedResourceVersion),
+			requestData: `{"labels": {"` + testutils.LabelKey + `": "` + testutils.LabelValue + `-merged"}}`,
+			want: want{
+				statusCode: http.StatusOK,
+				eTag:       strconv.Quote(testutils.UpdatedResourceVersion),
+				response: map[string]interface{}{
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-1", Namespace: testNamespaceName, ResourceVersion: testutils.UpdatedResourceVersion},
+					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey, Value: testutils.LabelValue + "-merged"}},
+					testutils.AnnotationsKey: nil,
+					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
+					testutils.StatusKey:      mocks.PrepareCappStatus(testutils.CappName+"-1", testNamespaceName, testutils.Domain),
+				},
+			},
+		},
+		"ShouldSucceedJSONPatchingCapp": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-2",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.JsonPatchJson,
+			requestData: `[{"op": "replace", "path": "/labels/` + testutils.LabelKey + `", "value": "` + testutils.LabelValue + `-patched"}]`,
+			want: want{
+				statusCode: http.StatusOK,
+				eTag:       strconv.Quote(testutils.UpdatedResourceVersion),
+				response: map[string]interface{}{
+					testutils.MetadataKey:    types.Metadata{Name: testutils.CappName + "-2", Namespace: testNamespaceName, ResourceVersion: testutils.UpdatedResourceVersion},
+					testutils.LabelsKey:      []types.KeyValue{{Key: testutils.LabelKey, Value: testutils.LabelValue + "-patched"}},
+					testutils.AnnotationsKey: nil,
+					testutils.SpecKey:        mocks.PrepareCappSpec(testutils.SiteName),
+					testutils.StatusKey:      mocks.PrepareCappStatus(testutils.CappName+"-2", testNamespaceName, testutils.Domain),
+				},
+			},
+		},
+		"ShouldHandleStaleIfMatch": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-3",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.MergePatchJson,
+			ifMatch:     strconv.Quote(testutils.StaleResourceVersion),
+			requestData: `{"labels": {}}`,
+			want: want{
+				statusCode: http.StatusPreconditionFailed,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf(controllers.ErrResourceVersionMismatch,
+						testutils.CappName+"-3", testutils.CreatedResourceVersion, testutils.StaleResourceVersion),
+					testutils.ReasonKey: customerrors.StatusReasonPreconditionFailed,
+				},
+			},
+		},
+		"ShouldHandleInvalidIfMatch": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-3",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.MergePatchJson,
+			ifMatch:     testutils.CreatedResourceVersion,
+			requestData: `{"labels": {}}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(routes.ErrInvalidIfMatch, testutils.CreatedResourceVersion, "*"),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldHandleInvalidPatch": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-3",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.MergePatchJson,
+			requestData: `{"metadata": {"name": "` + testutils.CappName + `"}}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrInvalidCappPatch, testutils.CappName+"-3", testNamespaceName, `json: unknown field "metadata"`),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldHandleUnsupportedContentType": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-3",
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.ApplicationJson,
+			requestData: `{"labels": {}}`,
+			want: want{
+				statusCode: http.StatusUnsupportedMediaType,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrUnsupportedPatchType, testutils.ApplicationJson, testutils.MergePatchJson, testutils.JsonPatchJson),
+					testutils.ReasonKey: metav1.StatusReasonUnsupportedMediaType,
+				},
+			},
+		},
+		"ShouldHandleNotFoundCapp": {
+			requestURI: requestURI{
+				name:      testutils.CappName + testutils.NonExistentSuffix,
+				namespace: testNamespaceName,
+			},
+			contentType: testutils.MergePatchJson,
+			requestData: `{"labels": {}}`,
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%v, %v",
+						fmt.Sprintf(controllers.ErrCouldNotGetCapp, testutils.CappName+testutils.NonExistentSuffix, testNamespaceName),
+						fmt.Sprintf("%s.%s %q not found", testutils.CappsKey, cappv1alpha1.GroupVersion.Group, testutils.CappName+testutils.NonExistentSuffix)),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	for i := 1; i <= 3; i++ {
+		mocks.CreateTestCapp(dynClient, testutils.CappName+"-"+strconv.Itoa(i), testNamespaceName, testutils.Domain, testutils.SiteName,
+			map[string]string{testutils.LabelKey: testutils.LabelValue}, nil)
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/capps/%s", test.requestURI.namespace, test.requestURI.name)
+			request, err := http.NewRequest(http.MethodPatch, baseURI, bytes.NewBufferString(test.requestData))
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, test.contentType)
+			if test.ifMatch != "" {
+				request.Header.Set(routes.IfMatchHeader, test.ifMatch)
+			}
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+			assert.Equal(t, test.want.eTag, writer.Header().Get(routes.ETagHeader))
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
 func TestEditCappState(t *testing.T) {
 	testNamespaceName := testutils.CappNamespace + "-update"
 
@@ -900,11 +1122,13 @@ func TestEditCappState(t *testing.T) {
 
 	type want struct {
 		statusCode int
+		eTag       string
 		response   map[string]interface{}
 	}
 
 	cases := map[string]struct {
 		requestURI  requestURI
+		ifMatch     string
 		want        want
 		requestData interface{}
 	}{
@@ -913,15 +1137,34 @@ func TestEditCappState(t *testing.T) {
 				name:      testutils.CappName,
 				namespace: testNamespaceName,
 			},
+			ifMatch: strconv.Quote(testutils.CreatedResourceVersion),
 			want: want{
 				statusCode: http.StatusOK,
+				eTag:       strconv.Quote(testutils.UpdatedResourceVersion),
 				response: map[string]interface{}{
-					testutils.NameKey:  testutils.CappName,
-					testutils.StateKey: testutils.DisabledState,
+					testutils.NameKey:            testutils.CappName,
+					testutils.StateKey:           testutils.DisabledState,
+					testutils.ResourceVersionKey: testutils.UpdatedResourceVersion,
 				},
 			},
 			requestData: types.CappState{State: testutils.DisabledState},
 		},
+		"ShouldHandleStaleIfMatch": {
+			requestURI: requestURI{
+				name:      testutils.CappName + "-2",
+				namespace: testNamespaceName,
+			},
+			ifMatch: strconv.Quote(testutils.StaleResourceVersion),
+			want: want{
+				statusCode: http.StatusPreconditionFailed,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf(controllers.ErrResourceVersionMismatch,
+						testutils.CappName+"-2", testutils.CreatedResourceVersion, testutils.StaleResourceVersion),
+					testutils.ReasonKey: customerrors.StatusReasonPreconditionFailed,
+				},
+			},
+			requestData: types.CappState{State: testutils.EnabledState},
+		},
 		"ShouldHandleNotFoundCapp": {
 			requestURI: requestURI{
 				name:      testutils.CappName + testutils.NonExistentSuffix,
@@ -972,6 +1215,7 @@ func TestEditCappState(t *testing.T) {
 
 	setup()
 	mocks.CreateTestCapp(dynClient, testutils.CappName, testNamespaceName, testutils.Domain, testutils.SiteName, map[string]string{testutils.LabelKey: testutils.LabelValue}, nil)
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-2", testNamespaceName, testutils.Domain, testutils.SiteName, map[string]string{testutils.LabelKey: testutils.LabelValue}, nil)
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -982,11 +1226,15 @@ func TestEditCappState(t *testing.T) {
 			request, err := http.NewRequest(http.MethodPut, baseURI, bytes.NewBuffer(payload))
 			assert.NoError(t, err)
 			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+			if test.ifMatch != "" {
+				request.Header.Set(routes.IfMatchHeader, test.ifMatch)
+			}
 
 			writer := httptest.NewRecorder()
 			router.ServeHTTP(writer, request)
 
 			assert.Equal(t, test.want.statusCode, writer.Code)
+			assert.Equal(t, test.want.eTag, writer.Header().Get(routes.ETagHeader))
 
 			var response map[string]interface{}
 			err = json.Unmarshal(writer.Body.Bytes(), &response)
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"errors"
+	"io"
+
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
 	"github.com/dana-team/platform-backend/internal/routes"
@@ -69,3 +72,48 @@ func GetCappRevision() gin.HandlerFunc {
 		})(c)
 	}
 }
+
+// GetCappRevisionDiff returns a Gin handler function for diffing a CappRevision against another CappRevision or the live Capp.
+func GetCappRevisionDiff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var cappRevisionUri types.CappRevisionUri
+		if err := c.BindUri(&cappRevisionUri); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		var diffQuery types.CappRevisionDiffQuery
+		if err := c.BindQuery(&diffQuery); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		cappRevisionHandler(func(controller controllers.CappRevisionController, c *gin.Context) (interface{}, error) {
+			return controller.GetCappRevisionDiff(cappRevisionUri.NamespaceName, cappRevisionUri.CappName, cappRevisionUri.CappRevisionName, diffQuery)
+		})(c)
+	}
+}
+
+// RollbackCapp returns a Gin handler function for rolling a Capp back to one of its CappRevisions.
+func RollbackCapp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var cappRevisionUri types.CappRevisionUri
+		if err := c.BindUri(&cappRevisionUri); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		// The body is optional, so that Capps can be rolled back while keeping their Site.
+		var rollback types.CappRollback
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&rollback); err != nil && !errors.Is(err, io.EOF) {
+				middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+				return
+			}
+		}
+
+		cappHandler(func(controller controllers.CappController, c *gin.Context) (interface{}, error) {
+			return controller.RollbackCapp(cappRevisionUri.NamespaceName, cappRevisionUri.CappName, cappRevisionUri.CappRevisionName, rollback)
+		})(c)
+	}
+}
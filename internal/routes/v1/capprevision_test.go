@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
@@ -10,6 +12,7 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	"github.com/stretchr/testify/assert"
+	"io"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"net/http/httptest"
@@ -177,7 +180,7 @@ func TestGetCappRevision(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.MetadataKey:    types.Metadata{Name: cappRevisionName, Namespace: testNamespaceName},
+					testutils.MetadataKey:    types.Metadata{Name: cappRevisionName, Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
 					testutils.LabelsKey:      labels,
 					testutils.AnnotationsKey: nil,
 					testutils.SpecKey:        mocks.PrepareCappRevisionSpec(testutils.SiteName, mocks.ConvertKeyValueSliceToMap(labels), map[string]string{}),
@@ -245,3 +248,269 @@ func TestGetCappRevision(t *testing.T) {
 		})
 	}
 }
+
+func TestRollbackCapp(t *testing.T) {
+	testNamespaceName := cappRevisionNamespace + "-rollback"
+	revisionLabels := map[string]string{testutils.LabelKey + "-previous": testutils.LabelValue + "-previous"}
+	revision := mocks.PrepareCappRevisionOfCapp(cappRevisionName, testNamespaceName, testutils.CappName, testutils.SiteName, testutils.PreviousCappImage, revisionLabels, nil)
+
+	rolledBackSpec := mocks.PrepareCappSpec(testutils.SiteName + "-new")
+	rolledBackSpec.ConfigurationSpec.Template.Spec.Containers[0].Image = testutils.PreviousCappImage
+	placedStatus := mocks.PrepareCappStatus(testutils.CappName, testNamespaceName, testutils.Domain)
+	placedStatus.ApplicationLinks.Site = testutils.ManagedClusterName
+
+	type requestURI struct {
+		namespace string
+		cappName  string
+		name      string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		requestURI  requestURI
+		requestData interface{}
+		want        want
+	}{
+		"ShouldSucceedRollingBackCapp": {
+			requestURI:  requestURI{namespace: testNamespaceName, cappName: testutils.CappName, name: cappRevisionName},
+			requestData: types.CappRollback{Site: testutils.SiteName + "-new"},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					"capp": types.Capp{
+						Metadata: types.Metadata{Name: testutils.CappName, Namespace: testNamespaceName, ResourceVersion: testutils.UpdatedResourceVersion},
+						Labels:   []types.KeyValue{{Key: testutils.LabelKey + "-previous", Value: testutils.LabelValue + "-previous"}},
+						Spec:     rolledBackSpec,
+						Status:   placedStatus,
+					},
+					"revision": types.CappRevision{
+						Metadata: types.Metadata{Name: cappRevisionName, Namespace: testNamespaceName, ResourceVersion: testutils.CreatedResourceVersion},
+						Labels:   []types.KeyValue{{Key: testutils.LabelCappName, Value: testutils.CappName}},
+						Spec:     revision.Spec,
+					},
+				},
+			},
+		},
+		"ShouldFailRollingBackWithSiteAndRestoreSite": {
+			requestURI:  requestURI{namespace: testNamespaceName, cappName: testutils.CappName, name: cappRevisionName},
+			requestData: types.CappRollback{Site: testutils.SiteName + "-new", RestoreSite: true},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'CappRollback.RestoreSite' Error:Field validation for 'RestoreSite' failed on the 'excluded_with' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldHandleRevisionOfAnotherCapp": {
+			requestURI: requestURI{namespace: testNamespaceName, cappName: testutils.CappName + "-other", name: cappRevisionName},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrCappRevisionNotOfCapp, cappRevisionName, testNamespaceName, testutils.CappName+"-other"),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+		"ShouldHandleCappWhichIsNotPlaced": {
+			requestURI: requestURI{namespace: testNamespaceName, cappName: testutils.CappName + "-unplaced", name: cappRevisionName},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrCappNotPlaced, testutils.CappName+"-unplaced", testNamespaceName),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+		"ShouldHandleNotFoundCappRevision": {
+			requestURI: requestURI{namespace: testNamespaceName, cappName: testutils.CappName, name: cappRevisionName + testutils.NonExistentSuffix},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%v, %v",
+						fmt.Sprintf(controllers.ErrCouldNotGetCappRevision, cappRevisionName+testutils.NonExistentSuffix, testNamespaceName),
+						fmt.Sprintf("%s.%s %q not found", capprevisionsKey, cappv1alpha1.GroupVersion.Group, cappRevisionName+testutils.NonExistentSuffix)),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
+	// The Capps are placed on a managed cluster, which their CappRevisions are read from.
+	for cappName, labels := range map[string]map[string]string{
+		testutils.CappName:            {testutils.LabelKey: testutils.LabelValue},
+		testutils.CappName + "-other": nil,
+	} {
+		capp := mocks.PrepareCapp(cappName, testNamespaceName, testutils.Domain, testutils.SiteName, labels, nil)
+		capp.Status.ApplicationLinks.Site = testutils.ManagedClusterName
+		assert.NoError(t, dynClient.Create(context.TODO(), &capp))
+	}
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-unplaced", testNamespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, cappRevisionName, testNamespaceName, testutils.CappName, testutils.SiteName, testutils.PreviousCappImage, revisionLabels, nil)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			var body io.Reader = http.NoBody
+			if test.requestData != nil {
+				payload, err := json.Marshal(test.requestData)
+				assert.NoError(t, err)
+				body = bytes.NewBuffer(payload)
+			}
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/capps/%s/capprevisions/%s/rollback", test.requestURI.namespace, test.requestURI.cappName, test.requestURI.name)
+			request, err := http.NewRequest(http.MethodPost, baseURI, body)
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
+func TestGetCappRevisionDiff(t *testing.T) {
+	testNamespaceName := cappRevisionNamespace + "-diff"
+	imagePath := "cappSpec.configurationSpec.template.spec.containers[name=" + testutils.ContainerName + "].image"
+
+	type requestURI struct {
+		cappName string
+		name     string
+		against  string
+		format   string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+		unified    bool
+	}
+
+	cases := map[string]struct {
+		requestURI requestURI
+		want       want
+	}{
+		"ShouldSucceedDiffingAgainstLiveCapp": {
+			requestURI: requestURI{cappName: testutils.CappName, name: cappRevisionName + "-1"},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					"from":    cappRevisionName + "-1",
+					"to":      controllers.LiveCapp,
+					"changes": []types.FieldDiff{{Path: imagePath, OldValue: testutils.PreviousCappImage, NewValue: testutils.CappImage}},
+				},
+			},
+		},
+		"ShouldSucceedDiffingAgainstCappRevisionWithUnifiedDiff": {
+			requestURI: requestURI{cappName: testutils.CappName, name: cappRevisionName + "-1", against: cappRevisionName + "-2", format: controllers.UnifiedDiffFormat},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					"from":    cappRevisionName + "-1",
+					"to":      cappRevisionName + "-2",
+					"changes": []types.FieldDiff{{Path: imagePath, OldValue: testutils.PreviousCappImage, NewValue: testutils.CappImage}},
+				},
+				unified: true,
+			},
+		},
+		"ShouldFailWithInvalidFormat": {
+			requestURI: requestURI{cappName: testutils.CappName, name: cappRevisionName + "-1", format: "yaml"},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'CappRevisionDiffQuery.Format' Error:Field validation for 'Format' failed on the 'oneof' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldHandleNotFoundCappRevisionToDiffAgainst": {
+			requestURI: requestURI{cappName: testutils.CappName, name: cappRevisionName + "-1", against: cappRevisionName + testutils.NonExistentSuffix},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%v, %v",
+						fmt.Sprintf(controllers.ErrCouldNotGetCappRevision, cappRevisionName+testutils.NonExistentSuffix, testNamespaceName),
+						fmt.Sprintf("%s.%s %q not found", capprevisionsKey, cappv1alpha1.GroupVersion.Group, cappRevisionName+testutils.NonExistentSuffix)),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+		"ShouldHandleCappWhichIsNotPlaced": {
+			requestURI: requestURI{cappName: testutils.CappName + "-unplaced", name: cappRevisionName + "-3"},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrCappNotPlaced, testutils.CappName+"-unplaced", testNamespaceName),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
+	// The live Capp is read from the hub, and its CappRevisions from the managed cluster it is placed on.
+	capp := mocks.PrepareCapp(testutils.CappName, testNamespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	capp.Status.ApplicationLinks.Site = testutils.ManagedClusterName
+	assert.NoError(t, dynClient.Create(context.TODO(), &capp))
+	mocks.CreateTestCapp(dynClient, testutils.CappName+"-unplaced", testNamespaceName, testutils.Domain, testutils.SiteName, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, cappRevisionName+"-1", testNamespaceName, testutils.CappName, testutils.SiteName, testutils.PreviousCappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, cappRevisionName+"-2", testNamespaceName, testutils.CappName, testutils.SiteName, testutils.CappImage, nil, nil)
+	mocks.CreateTestCappRevisionOfCapp(dynClient, cappRevisionName+"-3", testNamespaceName, testutils.CappName+"-unplaced", testutils.SiteName, testutils.CappImage, nil, nil)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			params := url.Values{}
+			if test.requestURI.against != "" {
+				params.Add("against", test.requestURI.against)
+			}
+			if test.requestURI.format != "" {
+				params.Add("format", test.requestURI.format)
+			}
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/capps/%s/capprevisions/%s/diff", testNamespaceName, test.requestURI.cappName, test.requestURI.name)
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			unified, ok := response["unified"]
+			assert.Equal(t, test.want.unified, ok)
+			if ok {
+				assert.Contains(t, unified, "+        - image: "+testutils.CappImage)
+				delete(response, "unified")
+			}
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 const (
 	bearerKey    = "bearer"
 	basicAuthKey = "basic"
+	sessionKey   = "session"
 )
 
 // setupSecuritySchemes adds security schemes as components to the Huma config.
@@ -27,6 +28,13 @@ func setupSecuritySchemes(config huma.Config) {
 			Scheme:       "basic",
 			BearerFormat: "Basic Auth",
 		},
+		sessionKey: {
+			Type: "apiKey",
+			In:   "cookie",
+			Name: "platform-session",
+			Description: "Session cookie set by logging in with `?session=true` when the session mode is enabled. " +
+				"Requests other than GET, HEAD and OPTIONS must also send the CSRF token of the session in the `X-CSRF-Token` header",
+		},
 	}
 }
 
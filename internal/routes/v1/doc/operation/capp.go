@@ -90,7 +90,8 @@ func AddCreateCapp(api huma.API, registry huma.Registry) {
 		Tags:        []string{cappTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s", namespacesKey, namespaceNameKey, cappsKey),
 		Summary:     "Create a Capp in a namespace",
-		Description: "Creates a new Capp in a specific namespace",
+		Description: "Creates a new Capp in a specific namespace. If dryRun is set, the Capp is validated, the secrets it " +
+			"references are checked to exist, and it is returned without being created",
 		Parameters: []*huma.Param{
 			{
 				Name:    namespaceNameKey,
@@ -110,6 +111,12 @@ func AddCreateCapp(api huma.API, registry huma.Registry) {
 				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.CreateCappQuery{}.Region)),
 				Example: defaultExample,
 			},
+			{
+				Name:    dryRunKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.DryRunQuery{}.DryRun)),
+				Example: true,
+			},
 		},
 		RequestBody: &huma.RequestBody{
 			Content: map[string]*huma.MediaType{
@@ -190,6 +197,12 @@ func AddGetCapp(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the Capp, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.Capp{})),
@@ -224,7 +237,8 @@ func AddUpdateCapp(api huma.API, registry huma.Registry) {
 		Tags:        []string{cappTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, cappsKey, cappNameKey),
 		Summary:     "Update a Capp in a namespace",
-		Description: "Updates a specific Capp in a specific namespace",
+		Description: "Updates a specific Capp in a specific namespace. If If-Match is set to the ETag of the Capp, it is only updated if it was not changed since. " +
+			"If dryRun is set, the updated Capp is validated, the secrets it references are checked to exist, and it is returned without being stored",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -240,6 +254,18 @@ func AddUpdateCapp(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappUri{}.CappName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    ifMatchHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultETag,
+			},
+			{
+				Name:    dryRunKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.DryRunQuery{}.DryRun)),
+				Example: true,
+			},
 		},
 		RequestBody: &huma.RequestBody{
 			Content: map[string]*huma.MediaType{
@@ -254,6 +280,12 @@ func AddUpdateCapp(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the Capp, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.Capp{})),
@@ -267,6 +299,116 @@ func AddUpdateCapp(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusPreconditionFailed): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddPatchCapp adds the PatchCapp route to the OpenAPI scheme.
+func AddPatchCapp(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "patch-capp",
+		Method:      http.MethodPatch,
+		Tags:        []string{cappTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, cappsKey, cappNameKey),
+		Summary:     "Patch a Capp in a namespace",
+		Description: "Applies a JSON merge patch (RFC 7386) or a JSON patch (RFC 6902) to a specific Capp in a specific namespace, " +
+			"according to the Content-Type of the request. The patch is applied to a document of the annotations, labels and spec " +
+			"of the Capp, in which the annotations and labels are objects, such as {\"labels\": {\"app\": \"web\"}}. " +
+			"Fields which are not in the document and invalid results are rejected. " +
+			"If If-Match is set to the ETag of the Capp, it is only patched if it was not changed since. " +
+			"If dryRun is set, the patched Capp is validated, the secrets it references are checked to exist, and it is returned without being stored",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     cappNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappUri{}.CappName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:    ifMatchHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultETag,
+			},
+			{
+				Name:    dryRunKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.DryRunQuery{}.DryRun)),
+				Example: true,
+			},
+		},
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationMergePatchJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.PatchCapp{})),
+				},
+				applicationJSONPatchJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf([]map[string]interface{}{})),
+				},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the Capp, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.Capp{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusUnsupportedMediaType): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusPreconditionFailed): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -288,7 +430,7 @@ func AddEditCappState(api huma.API, registry huma.Registry) {
 		Tags:        []string{cappTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/state", namespacesKey, namespaceNameKey, cappsKey, cappNameKey),
 		Summary:     "Edit state of a Capp in a namespace",
-		Description: "Changes the state field a specific Capp in a specific namespace to disabled or enabled",
+		Description: "Changes the state field a specific Capp in a specific namespace to disabled or enabled. If If-Match is set to the ETag of the Capp, its state is only changed if the Capp was not changed since",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -304,6 +446,12 @@ func AddEditCappState(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappUri{}.CappName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    ifMatchHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultETag,
+			},
 		},
 		RequestBody: &huma.RequestBody{
 			Content: map[string]*huma.MediaType{
@@ -328,6 +476,12 @@ func AddEditCappState(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the Capp, which If-Match may be set to when its state is edited",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.CappStateResponse{})),
@@ -341,6 +495,13 @@ func AddEditCappState(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusPreconditionFailed): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -384,6 +545,12 @@ func AddGetCappState(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the Capp, which If-Match may be set to when its state is edited",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.GetCappStateResponse{})),
@@ -273,3 +273,170 @@ func AddClusterGetCappRevision(api huma.API, registry huma.Registry) {
 
 	api.OpenAPI().AddOperation(operation)
 }
+
+// AddGetCappRevisionDiff adds the GetCappRevisionDiff route to the OpenAPI scheme.
+func AddGetCappRevisionDiff(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-capp-revision-diff",
+		Method:      http.MethodGet,
+		Tags:        []string{cappRevisionTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s/{%s}/diff", namespacesKey, namespaceNameKey, cappsKey, cappNameKey, cappRevisionsKey, cappRevisionNameKey),
+		Summary:     "Diff a CappRevision of a Capp",
+		Description: "Retrieves the field-level changes from the template of a specific CappRevision of a specific Capp to the template of another of its CappRevisions, or to the live Capp, including its containers, images, environment variables, route and scale settings. The against query parameter is the name of another CappRevision, or live for the live Capp, which is the default. Objects in lists, such as containers, are matched by name. The Secrets referenced by environment variables are shown by name only. A unified diff of the templates is also rendered when the format query parameter is unified. The live Capp is read from the hub, and its CappRevisions from the cluster it is placed on, so a Capp which is not placed on a cluster yet has no CappRevisions to diff",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     cappNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.CappName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     cappRevisionNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.CappRevisionName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:    againstKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionDiffQuery{}.Against)),
+				Example: "live",
+			},
+			{
+				Name:    formatKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionDiffQuery{}.Format)),
+				Example: "unified",
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionDiff{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddRollbackCapp adds the RollbackCapp route to the OpenAPI scheme.
+func AddRollbackCapp(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "rollback-capp",
+		Method:      http.MethodPost,
+		Tags:        []string{cappRevisionTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s/{%s}/rollback", namespacesKey, namespaceNameKey, cappsKey, cappNameKey, cappRevisionsKey, cappRevisionNameKey),
+		Summary:     "Roll a Capp back to one of its CappRevisions",
+		Description: "Restores the spec, labels and annotations of a specific Capp in a specific namespace from one of its CappRevisions, and returns the updated Capp along with the CappRevision. The state of the Capp is kept, and so is its site unless the request body sets a site or restores the site of the CappRevision. A Capp which is not placed on a cluster yet has no CappRevisions to roll back to",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     cappNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.CappName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     cappRevisionNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.CappRevisionUri{}.CappRevisionName)),
+				Example:  defaultExample,
+			},
+		},
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.CappRollback{})),
+					Examples: map[string]*huma.Example{
+						"Keep the site": {
+							Value: types.CappRollback{},
+						},
+						"Restore the site of the revision": {
+							Value: types.CappRollback{RestoreSite: true},
+						},
+					},
+				},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.CappRollbackResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
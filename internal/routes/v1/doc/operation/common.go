@@ -4,6 +4,7 @@ const (
 	defaultExample = "default"
 	defaultToken   = "token"
 	defaultBase64  = "Bdpet54cdE82vE8upqjYcg=="
+	defaultETag    = `"1"`
 )
 
 const (
@@ -13,13 +14,17 @@ const (
 )
 
 const (
-	bearerKey          = "bearer"
-	basicAuthKey       = "basic"
-	paginationPageKey  = "page"
-	paginationLimitKey = "limit"
-	labelSelectorKey   = "labelSelector"
-	applicationJSONKey = "application/json"
-	previousKey        = "previous"
+	bearerKey                    = "bearer"
+	basicAuthKey                 = "basic"
+	paginationPageKey            = "page"
+	paginationLimitKey           = "limit"
+	labelSelectorKey             = "labelSelector"
+	applicationJSONKey           = "application/json"
+	applicationMergePatchJSONKey = "application/merge-patch+json"
+	applicationJSONPatchJSONKey  = "application/json-patch+json"
+	previousKey                  = "previous"
+	sessionQueryKey              = "session"
+	wsTicketKey                  = "ticket"
 )
 
 const (
@@ -31,8 +36,11 @@ const (
 
 	cappsKey            = "capps"
 	cappNameKey         = "cappName"
+	dryRunKey           = "dryRun"
 	cappRevisionsKey    = "capprevisions"
 	cappRevisionNameKey = "cappRevisionName"
+	againstKey          = "against"
+	formatKey           = "format"
 
 	podNameKey       = "podName"
 	podsKey          = "pods"
@@ -45,6 +53,7 @@ const (
 	serviceAccountName = "serviceAccountName"
 
 	userNameKey = "userName"
+	userTypeKey = "type"
 
 	logsKey     = "logs"
 	terminalKey = "terminal"
@@ -60,6 +69,9 @@ const (
 	secWebSocketKeyHeaderKey      = "Sec-WebSocket-Key"
 	secWebSocketVersionHeaderKey  = "Sec-WebSocket-Version"
 	secWebSocketVersionValue      = "13"
+	retryAfterHeaderKey           = "Retry-After"
+	eTagHeaderKey                 = "ETag"
+	ifMatchHeaderKey              = "If-Match"
 )
 
 const (
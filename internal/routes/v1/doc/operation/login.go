@@ -14,7 +14,15 @@ func AddLogin(api huma.API, registry huma.Registry) {
 		Method:      http.MethodPost,
 		Path:        "/v1/login",
 		Summary:     "Login",
-		Description: "Returns token from username and password",
+		Description: "Returns token from username and password. Attempts are throttled per username and per client IP, which are locked out temporarily after repeated failed logins",
+		Parameters: []*huma.Param{
+			{
+				Name:        sessionQueryKey,
+				In:          queryKey,
+				Description: "Set the token in an encrypted HttpOnly session cookie and return a CSRF token instead of the token. Requires the session mode to be enabled",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(types.LoginQuery{}.Session)),
+			},
+		},
 		Security: []map[string][]string{
 			{basicAuthKey: {}},
 		},
@@ -40,6 +48,123 @@ func AddLogin(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusTooManyRequests): {
+				Description: "Too many login attempts for the username or from the client IP",
+				Headers: map[string]*huma.Param{
+					retryAfterHeaderKey: {
+						Description: "Number of seconds to wait before retrying",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(0)),
+					},
+				},
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
+
+func AddLoginRefresh(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "login-refresh",
+		Method:      http.MethodPost,
+		Path:        "/v1/login/refresh",
+		Summary:     "Refresh login",
+		Description: "Returns a new token in exchange for a refresh token. In session mode, the refresh token is taken from the session cookie",
+		Parameters: []*huma.Param{
+			{
+				Name: sessionQueryKey,
+				In:   queryKey,
+				Description: "Take the refresh token from the session cookie and set the new token in it. The CSRF token of the session " +
+					"must be sent in the X-CSRF-Token header. Requires the session mode to be enabled",
+				Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.LoginQuery{}.Session)),
+			},
+		},
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.LoginRefreshInput{})),
+				},
+			},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.LoginOutput{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusUnauthorized): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
+
+func AddLogout(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "logout",
+		Method:      http.MethodPost,
+		Path:        "/v1/logout",
+		Summary:     "Logout",
+		Description: "Revokes the token of the caller",
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.MessageResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusUnauthorized): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -65,11 +65,17 @@ func AddGetPodLogs(api huma.API, registry huma.Registry) {
 				Example:  webSocketValue,
 			},
 			{
-				Name:     secWebSocketProtocolHeaderKey,
-				In:       headerKey,
-				Required: true,
-				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
-				Example:  defaultToken,
+				Name:        wsTicketKey,
+				In:          queryKey,
+				Description: "A ticket issued for the route by POST /v1/ws-tickets, used instead of the Sec-Websocket-Protocol header",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example:     defaultExample,
+			},
+			{
+				Name:    secWebSocketProtocolHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultToken,
 			},
 			{
 				Name:     secWebSocketKeyHeaderKey,
@@ -170,11 +176,17 @@ func AddClusterGetPodLogs(api huma.API, registry huma.Registry) {
 				Example:  webSocketValue,
 			},
 			{
-				Name:     secWebSocketProtocolHeaderKey,
-				In:       headerKey,
-				Required: true,
-				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
-				Example:  defaultToken,
+				Name:        wsTicketKey,
+				In:          queryKey,
+				Description: "A ticket issued for the route by POST /v1/ws-tickets, used instead of the Sec-Websocket-Protocol header",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example:     defaultExample,
+			},
+			{
+				Name:    secWebSocketProtocolHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultToken,
 			},
 			{
 				Name:     secWebSocketKeyHeaderKey,
@@ -275,11 +287,17 @@ func AddGetCappLogs(api huma.API, registry huma.Registry) {
 				Example:  webSocketValue,
 			},
 			{
-				Name:     secWebSocketProtocolHeaderKey,
-				In:       headerKey,
-				Required: true,
-				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
-				Example:  defaultToken,
+				Name:        wsTicketKey,
+				In:          queryKey,
+				Description: "A ticket issued for the route by POST /v1/ws-tickets, used instead of the Sec-Websocket-Protocol header",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example:     defaultExample,
+			},
+			{
+				Name:    secWebSocketProtocolHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultToken,
 			},
 			{
 				Name:     secWebSocketKeyHeaderKey,
@@ -380,11 +398,17 @@ func AddClusterGetCappLogs(api huma.API, registry huma.Registry) {
 				Example:  webSocketValue,
 			},
 			{
-				Name:     secWebSocketProtocolHeaderKey,
-				In:       headerKey,
-				Required: true,
-				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
-				Example:  defaultToken,
+				Name:        wsTicketKey,
+				In:          queryKey,
+				Description: "A ticket issued for the route by POST /v1/ws-tickets, used instead of the Sec-Websocket-Protocol header",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example:     defaultExample,
+			},
+			{
+				Name:    secWebSocketProtocolHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultToken,
 			},
 			{
 				Name:     secWebSocketKeyHeaderKey,
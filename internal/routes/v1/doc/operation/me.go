@@ -0,0 +1,67 @@
+package operation
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const meTag = "Me"
+
+// AddGetMe adds the GetMe route to the OpenAPI scheme.
+func AddGetMe(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-me",
+		Method:      http.MethodGet,
+		Tags:        []string{meTag},
+		Path:        "/v1/me",
+		Summary:     "Get the logged in user",
+		Description: "Retrieves the username and groups of the logged in user, and the namespaces the user can access with the platform role of the user in each. Namespaces in which the user has no platform role are left out",
+		Parameters: []*huma.Param{
+			{
+				Name:    paginationPageKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.PaginationParams{}.Page)),
+				Example: 1,
+			},
+			{
+				Name:    paginationLimitKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.PaginationParams{}.Limit)),
+				Example: 1,
+			},
+		},
+
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.Me{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
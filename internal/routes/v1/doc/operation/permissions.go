@@ -0,0 +1,86 @@
+package operation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const (
+	permissionsCheckKey = "permissions:check"
+	permissionsTag      = "Permissions"
+)
+
+// AddCheckPermissions adds the CheckPermissions route to the OpenAPI scheme.
+func AddCheckPermissions(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "check-permissions",
+		Method:      http.MethodPost,
+		Tags:        []string{permissionsTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s", namespacesKey, namespaceNameKey, permissionsCheckKey),
+		Summary:     "Check permissions of the logged in user in a namespace",
+		Description: "Checks whether the logged in user may perform each of a batch of actions in a specific namespace. " +
+			"Each check either sets a verb, group, resource, subresource and name, or a preset for an action offered by the API: " +
+			"capps:create, capps:update, capps:delete, pods:exec, pods:logs, secrets:create, secrets:update, secrets:delete, " +
+			"users:manage and serviceaccounts:create. The results are returned in the order of the checks",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.NamespaceUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+		},
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Examples: map[string]*huma.Example{
+						"Full scheme": {
+							Value: huma.SchemaFromType(registry, reflect.TypeOf(types.PermissionsCheckInput{})),
+						},
+						"Terminal and capp state": {
+							Value: types.PermissionsCheckInput{Checks: []types.PermissionCheck{
+								{Preset: "pods:exec"},
+								{Preset: "capps:update", Name: defaultExample},
+								{Verb: "delete", Resource: "secrets", Name: defaultExample},
+							}},
+						},
+					},
+				},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.PermissionsCheckOutput{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
@@ -0,0 +1,45 @@
+package operation
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const rolesTag = "Roles"
+
+// AddGetRoles adds the GetRoles route to the OpenAPI scheme.
+func AddGetRoles(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-roles",
+		Method:      http.MethodGet,
+		Tags:        []string{rolesTag},
+		Path:        "/v1/roles",
+		Summary:     "Get platform roles",
+		Description: "Retrieves the platform roles which may be granted to users, groups and serviceaccounts in namespaces, from the least to the most privileged, along with the ClusterRole each role binds",
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.RoleCatalog{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusUnauthorized): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
@@ -118,6 +118,12 @@ func AddGetSecret(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the secret, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.GetSecretResponse{})),
@@ -213,7 +219,7 @@ func AddUpdateSecret(api huma.API, registry huma.Registry) {
 		Tags:        []string{secretTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, secretsName, secretNameKey),
 		Summary:     "Update a secret in a namespace",
-		Description: "Updates a specific secret in a specific namespace",
+		Description: "Updates a specific secret in a specific namespace. If If-Match is set to the ETag of the secret, it is only updated if it was not changed since",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -229,6 +235,12 @@ func AddUpdateSecret(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.SecretUriRequest{}.SecretName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    ifMatchHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultETag,
+			},
 		},
 		RequestBody: &huma.RequestBody{
 			Description: "secret content",
@@ -243,6 +255,12 @@ func AddUpdateSecret(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the secret, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.UpdateSecretResponse{})),
@@ -256,6 +274,13 @@ func AddUpdateSecret(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusPreconditionFailed): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -13,6 +13,7 @@ import (
 const (
 	serviceAccountTag  = "ServiceAccounts"
 	serviceAccountsKey = "serviceaccounts"
+	roleKey            = "role"
 )
 
 // AddGetServiceAccount adds the GetServiceAccount route to the OpenAPI scheme.
@@ -23,7 +24,7 @@ func AddGetServiceAccount(api huma.API, registry huma.Registry) {
 		Tags:        []string{serviceAccountTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName),
 		Summary:     "Get a specific ServiceAccount in a namespace",
-		Description: "Retrieves a specific ServiceAccount in a namespace",
+		Description: "Retrieves the details of a specific ServiceAccount in a namespace, including its creation time, labels, annotations, description, platform role, image pull secrets and the metadata of its named tokens",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -79,7 +80,8 @@ func AddCreateServiceAccount(api huma.API, registry huma.Registry) {
 		Tags:        []string{serviceAccountTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName),
 		Summary:     "Create a ServiceAccount in a namespace",
-		Description: "Creates a new ServiceAccount in a specific namespace",
+		Description: "Creates a new ServiceAccount in a specific namespace, with an optional description and labels. " +
+			"A token request secret is created along with it, which the tokens issued by get-token are bound to",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -96,6 +98,14 @@ func AddCreateServiceAccount(api huma.API, registry huma.Registry) {
 				Example:  defaultExample,
 			},
 		},
+		RequestBody: &huma.RequestBody{
+			Required: false,
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.CreateServiceAccountRequest{})),
+				},
+			},
+		},
 		Security: []map[string][]string{
 			{bearerKey: {}},
 		},
@@ -189,7 +199,7 @@ func AddGetServiceAccounts(api huma.API, registry huma.Registry) {
 		Tags:        []string{serviceAccountTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey),
 		Summary:     "Get all ServiceAccounts in a namespace",
-		Description: "Retrieves all ServiceAccounts in a specific namespace",
+		Description: "Retrieves all ServiceAccounts in a specific namespace, along with their descriptions, creation times and platform roles",
 		Parameters: []*huma.Param{
 			{
 				Name:    paginationPageKey,
@@ -241,3 +251,189 @@ func AddGetServiceAccounts(api huma.API, registry huma.Registry) {
 
 	api.OpenAPI().AddOperation(operation)
 }
+
+// AddGetServiceAccountRole adds the GetServiceAccountRole route to the OpenAPI scheme.
+func AddGetServiceAccountRole(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-serviceaccount-role",
+		Method:      http.MethodGet,
+		Tags:        []string{serviceAccountTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, roleKey),
+		Summary:     "Get the role of a ServiceAccount",
+		Description: "Retrieves the platform role of a specific ServiceAccount in a namespace",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRole{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddSetServiceAccountRole adds the SetServiceAccountRole route to the OpenAPI scheme.
+func AddSetServiceAccountRole(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "set-serviceaccount-role",
+		Method:      http.MethodPut,
+		Tags:        []string{serviceAccountTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, roleKey),
+		Summary:     "Set the role of a ServiceAccount",
+		Description: "Binds a specific ServiceAccount in a namespace to the cluster role of a platform role, replacing its previous role while keeping the ServiceAccount bound. A conflict is returned if its role is changed concurrently",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+		},
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRole{})),
+				},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRole{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusConflict): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddDeleteServiceAccountRole adds the DeleteServiceAccountRole route to the OpenAPI scheme.
+func AddDeleteServiceAccountRole(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "delete-serviceaccount-role",
+		Method:      http.MethodDelete,
+		Tags:        []string{serviceAccountTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, roleKey),
+		Summary:     "Remove the role of a ServiceAccount",
+		Description: "Removes the platform role of a specific ServiceAccount in a namespace",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.MessageResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+	api.OpenAPI().AddOperation(operation)
+}
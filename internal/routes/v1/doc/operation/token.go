@@ -11,12 +11,17 @@ import (
 )
 
 const (
-	tokenTag  = "Tokens"
-	tokensKey = "token"
+	tokenTag           = "Tokens"
+	tokensKey          = "token"
+	namedTokensKey     = "tokens"
+	tokenNameKey       = "tokenName"
+	nameKey            = "name"
+	descriptionKey     = "description"
+	kubeconfigKey      = "kubeconfig"
+	applicationYAMLKey = "application/yaml"
 )
 
 // AddGetToken adds the GetToken route to the OpenAPI scheme.
-// TODO: figure out if this function is necessary. It is sort of confusing since this is relating to dockercfgTokens, not auth tokens.
 func AddGetToken(api huma.API, registry huma.Registry) {
 	operation := &huma.Operation{
 		OperationID: "get-token",
@@ -24,7 +29,12 @@ func AddGetToken(api huma.API, registry huma.Registry) {
 		Tags:        []string{tokenTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, tokensKey),
 		Summary:     "Get token of a ServiceAccount in a namespace",
-		Description: "Retrieves the token of a specific ServiceAccount in a namespace",
+		Description: "Retrieves the token of a specific ServiceAccount in a namespace. The token is read from the legacy token secret " +
+			"of the dockercfg secret of the ServiceAccount, or from a kubernetes.io/service-account-token secret of the ServiceAccount. " +
+			"If it has neither, as is the case on Kubernetes 1.24+, a token expiring after the default expiration of the token " +
+			"expiration policy is issued through a TokenRequest. It is bound to the token request secret which is created along with " +
+			"the ServiceAccount, and is revoked along with the other tokens of the ServiceAccount. No object is created. " +
+			"The mechanism which supplied the token is returned, along with the expiration of tokens issued by a TokenRequest",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -59,6 +69,13 @@ func AddGetToken(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -80,7 +97,11 @@ func AddCreateToken(api huma.API, registry huma.Registry) {
 		Tags:        []string{tokenTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, tokensKey),
 		Summary:     "Creates an auth token for a service account",
-		Description: "Creates an auth token for a service account in a namespace",
+		Description: "Creates a named auth token for a service account in a namespace. Each token is bound to a secret of its own, " +
+			"so that it can be revoked without revoking the other tokens of the service account. A name is generated if none is given. " +
+			"The expiration must not exceed the maximum of the token expiration policy, which a namespace may override " +
+			"with the rcs.dana.io/token-expiration-policy annotation. The global policy applies to callers who may not get the namespace. Shorter expirations are raised to the minimum of the policy, " +
+			"and the effective expiration is returned",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -97,11 +118,24 @@ func AddCreateToken(api huma.API, registry huma.Registry) {
 				Example:  defaultExample,
 			},
 			{
-				Name:     expirationSecondsKey,
-				In:       queryKey,
-				Required: false,
-				Schema:   huma.SchemaFromType(registry, reflect.TypeOf("")),
-				Example:  defaultExampleSeconds,
+				Name:        expirationSecondsKey,
+				In:          queryKey,
+				Description: "Expiration of the token in seconds. The default expiration of the token expiration policy is used if it is not set",
+				Required:    false,
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf("")),
+				Example:     defaultExampleSeconds,
+			},
+			{
+				Name:    nameKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.CreateTokenQuery{}.Name)),
+				Example: "ci",
+			},
+			{
+				Name:    descriptionKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.CreateTokenQuery{}.Description)),
+				Example: "Token of the CI pipeline",
 			},
 		},
 		Security: []map[string][]string{
@@ -122,6 +156,13 @@ func AddCreateToken(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusConflict): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -143,7 +184,62 @@ func AddRevokeToken(api huma.API, registry huma.Registry) {
 		Tags:        []string{tokenTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, tokensKey),
 		Summary:     "Revokes the tokens for a ServiceAccount",
-		Description: "Revokes all tokens for a specific ServiceAccount in a namespace",
+		Description: "Revokes all tokens, named or not, for a specific ServiceAccount in a namespace",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.MessageResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddGetTokens adds the GetTokens route to the OpenAPI scheme.
+func AddGetTokens(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-tokens",
+		Method:      http.MethodGet,
+		Tags:        []string{tokenTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, namedTokensKey),
+		Summary:     "Get the tokens of a ServiceAccount",
+		Description: "Lists the name, description, creator, creation and expiration of the named tokens of a specific ServiceAccount in a namespace. Token values are never returned",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -163,6 +259,69 @@ func AddRevokeToken(api huma.API, registry huma.Registry) {
 		Security: []map[string][]string{
 			{bearerKey: {}},
 		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.TokensOutput{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddRevokeNamedToken adds the RevokeNamedToken route to the OpenAPI scheme.
+func AddRevokeNamedToken(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "revoke-named-token",
+		Method:      http.MethodDelete,
+		Tags:        []string{tokenTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, namedTokensKey, tokenNameKey),
+		Summary:     "Revokes a token of a ServiceAccount",
+		Description: "Revokes a single named token of a specific ServiceAccount in a namespace, leaving its other tokens valid",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.TokenRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.TokenRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     tokenNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.TokenRequestUri{}.TokenName)),
+				Example:  defaultExample,
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
 				Content: map[string]*huma.MediaType{
@@ -171,6 +330,89 @@ func AddRevokeToken(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+	api.OpenAPI().AddOperation(operation)
+}
+
+// AddGetKubeconfig adds the GetKubeconfig route to the OpenAPI scheme.
+func AddGetKubeconfig(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "get-kubeconfig",
+		Method:      http.MethodGet,
+		Tags:        []string{tokenTag},
+		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}/%s", namespacesKey, namespaceNameKey, serviceAccountsKey, serviceAccountName, kubeconfigKey),
+		Summary:     "Download a kubeconfig for a ServiceAccount",
+		Description: "Creates a named token for a ServiceAccount, under the same expiration policy as create-token, and returns a kubeconfig " +
+			"which authenticates with it. The current context targets the namespace on the hub cluster, and a context is added " +
+			"for each of the given managed clusters, through the proxy of the cluster-gateway. The token can be listed and revoked like any other named token",
+		Parameters: []*huma.Param{
+			{
+				Name:     namespaceNameKey,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.NamespaceName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:     serviceAccountName,
+				In:       pathKey,
+				Required: true,
+				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.ServiceAccountRequestUri{}.ServiceAccountName)),
+				Example:  defaultExample,
+			},
+			{
+				Name:        expirationSecondsKey,
+				In:          queryKey,
+				Description: "Expiration of the token in seconds. The default expiration of the token expiration policy is used if it is not set",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf("")),
+				Example:     defaultExampleSeconds,
+			},
+			{
+				Name:    nameKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.KubeconfigQuery{}.Name)),
+				Example: "ci",
+			},
+			{
+				Name:    descriptionKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.KubeconfigQuery{}.Description)),
+				Example: "Token of the CI pipeline",
+			},
+			{
+				Name:        clustersKey,
+				In:          queryKey,
+				Description: "Managed clusters to add a context for. May be repeated",
+				Schema:      huma.SchemaFromType(registry, reflect.TypeOf(types.KubeconfigQuery{}.Clusters)),
+				Example:     []string{"cluster-a"},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationYAMLKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf("")),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusBadRequest): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -178,6 +420,13 @@ func AddRevokeToken(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusNotFound): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -23,7 +23,7 @@ func AddGetUsers(api huma.API, registry huma.Registry) {
 		Tags:        []string{userTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s", namespacesKey, namespaceNameKey, usersKey),
 		Summary:     "Get all users in a namespace",
-		Description: "Retrieves all users in a namespace",
+		Description: "Retrieves all users and groups which are members of a namespace, along with the time left until the access of expiring members is removed",
 		Parameters: []*huma.Param{
 			{
 				Name:    paginationPageKey,
@@ -84,7 +84,7 @@ func AddCreateUser(api huma.API, registry huma.Registry) {
 		Tags:        []string{userTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s", namespacesKey, namespaceNameKey, usersKey),
 		Summary:     "Create a user in a namespace",
-		Description: "Creates a new user in a specific namespace",
+		Description: "Creates a new user or group member in a specific namespace. The member is a user unless its type is Group, and its access is removed at expiresAt if it is set",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -140,7 +140,7 @@ func AddGetUser(api huma.API, registry huma.Registry) {
 		Tags:        []string{userTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, usersKey, userNameKey),
 		Summary:     "Get a user in a namespace",
-		Description: "Retrieves a specific user in a specific namespace",
+		Description: "Retrieves a specific user or group in a specific namespace. The users of a group are listed as its members",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -156,12 +156,24 @@ func AddGetUser(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.UserIdentifier{}.UserName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    userTypeKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.UserQuery{}.Type)),
+				Example: "Group",
+			},
 		},
 		Security: []map[string][]string{
 			{bearerKey: {}},
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the member, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.User{})),
@@ -196,7 +208,7 @@ func AddUpdateUser(api huma.API, registry huma.Registry) {
 		Tags:        []string{userTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, usersKey, userNameKey),
 		Summary:     "Update a user in a namespace",
-		Description: "Updates a specific user in a specific namespace",
+		Description: "Updates the role and expiration of a specific user or group in a specific namespace. The access of the member no longer expires if expiresAt is not set. The member keeps its original role if the update fails, unless the original role cannot be restored, in which case the member keeps the new role until it is updated again a minute later. A conflict is returned if its role is changed concurrently. If If-Match is set to the ETag of the member, it is only updated if it was not changed since, and a failed precondition is returned instead of a conflict",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -212,6 +224,18 @@ func AddUpdateUser(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.UserIdentifier{}.UserName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    userTypeKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.UserQuery{}.Type)),
+				Example: "Group",
+			},
+			{
+				Name:    ifMatchHeaderKey,
+				In:      headerKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+				Example: defaultETag,
+			},
 		},
 		Security: []map[string][]string{
 			{bearerKey: {}},
@@ -225,6 +249,12 @@ func AddUpdateUser(api huma.API, registry huma.Registry) {
 		},
 		Responses: map[string]*huma.Response{
 			strconv.Itoa(http.StatusOK): {
+				Headers: map[string]*huma.Param{
+					eTagHeaderKey: {
+						Description: "Resource version of the member, which If-Match may be set to when it is updated",
+						Schema:      huma.SchemaFromType(registry, reflect.TypeOf(huma.TypeString)),
+					},
+				},
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
 						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.User{})),
@@ -238,6 +268,20 @@ func AddUpdateUser(api huma.API, registry huma.Registry) {
 					},
 				},
 			},
+			strconv.Itoa(http.StatusConflict): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusPreconditionFailed): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
 			strconv.Itoa(http.StatusInternalServerError): {
 				Content: map[string]*huma.MediaType{
 					applicationJSONKey: {
@@ -259,7 +303,7 @@ func AddDeleteUser(api huma.API, registry huma.Registry) {
 		Tags:        []string{userTag},
 		Path:        fmt.Sprintf("/v1/%s/{%s}/%s/{%s}", namespacesKey, namespaceNameKey, usersKey, userNameKey),
 		Summary:     "Delete a user in a namespace",
-		Description: "Deletes a specific user in a specific namespace",
+		Description: "Deletes a specific user or group from a specific namespace, including the role it may be left with by a failed update",
 		Parameters: []*huma.Param{
 			{
 				Name:     namespaceNameKey,
@@ -275,6 +319,12 @@ func AddDeleteUser(api huma.API, registry huma.Registry) {
 				Schema:   huma.SchemaFromType(registry, reflect.TypeOf(types.UserIdentifier{}.UserName)),
 				Example:  defaultExample,
 			},
+			{
+				Name:    userTypeKey,
+				In:      queryKey,
+				Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.UserQuery{}.Type)),
+				Example: "Group",
+			},
 		},
 		Security: []map[string][]string{
 			{bearerKey: {}},
@@ -0,0 +1,66 @@
+package operation
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// AddCreateWSTicket adds the CreateWSTicket route to the OpenAPI scheme.
+func AddCreateWSTicket(api huma.API, registry huma.Registry) {
+	operation := &huma.Operation{
+		OperationID: "create-ws-ticket",
+		Method:      http.MethodPost,
+		Tags:        []string{logsTag},
+		Path:        "/v1/ws-tickets",
+		Summary:     "Create a WebSocket ticket",
+		Description: "Issues a single-use ticket which authenticates the logged in user on the WebSocket route of the given path " +
+			"through the ticket query parameter, instead of the token in the Sec-Websocket-Protocol header. The ticket expires after about 30 seconds",
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				applicationJSONKey: {
+					Schema:  huma.SchemaFromType(registry, reflect.TypeOf(types.WSTicketInput{})),
+					Example: types.WSTicketInput{Path: "/ws/namespaces/default/pods/default/logs"},
+				},
+			},
+		},
+		Security: []map[string][]string{
+			{bearerKey: {}},
+		},
+		Responses: map[string]*huma.Response{
+			strconv.Itoa(http.StatusOK): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.WSTicketOutput{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusBadRequest): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusUnauthorized): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+			strconv.Itoa(http.StatusInternalServerError): {
+				Content: map[string]*huma.MediaType{
+					applicationJSONKey: {
+						Schema: huma.SchemaFromType(registry, reflect.TypeOf(types.ErrorResponse{})),
+					},
+				},
+			},
+		},
+	}
+
+	api.OpenAPI().AddOperation(operation)
+}
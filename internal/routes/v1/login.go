@@ -2,26 +2,41 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 	"github.com/dana-team/platform-backend/internal/auth"
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
 	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 	"net/http"
 )
 
 const (
 	errAuthorizationHeaderNotFound = "Authorization header not found"
+	errCouldNotRevokeToken         = "Could not revoke token"
+	errTooManyLoginAttempts        = "Too many login attempts, retry in %d seconds"
+	errCouldNotLimitLogin          = "Could not check login attempts"
+	errSessionModeDisabled         = "Session mode is not enabled"
+	errCouldNotSetSession          = "Could not set session cookie"
+	msgLoggedOut                   = "Logged out successfully"
 )
 
 // Login handles user authentication and issues a token on successful login.
 // If there's an error during authentication, it responds with an appropriate error message.
-func Login(tokenProvider auth.TokenProvider) gin.HandlerFunc {
+// Attempts are throttled by the login limiter, if one is set. With ?session=true, the token
+// is set in a session cookie instead of being returned.
+func Login(tokenProvider auth.TokenProvider, loginLimiter *auth.LoginLimiter, sessionManager *middleware.SessionManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctxLogger, _ := c.Get("logger")
 		logger := ctxLogger.(*zap.Logger)
 
+		query, err := bindLoginQuery(c, sessionManager)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
 		username, password, hasAuth := c.Request.BasicAuth()
 		if !hasAuth {
 			logger.Error(errAuthorizationHeaderNotFound)
@@ -30,10 +45,25 @@ func Login(tokenProvider auth.TokenProvider) gin.HandlerFunc {
 		}
 
 		logger = logger.With(zap.String("user", username))
+		if loginLimiter != nil {
+			retryAfter, err := loginLimiter.Allow(c.Request.Context(), username, c.ClientIP())
+			if err != nil {
+				logger.Error(fmt.Sprintf("%v with error: %v", errCouldNotLimitLogin, err))
+				middleware.AddErrorToContext(c, customerrors.NewInternalServerError(errCouldNotLimitLogin))
+				return
+			}
+			if retryAfter > 0 {
+				logger.Warn("Login attempt throttled", zap.String("clientIP", c.ClientIP()), zap.Duration("retryAfter", retryAfter))
+				middleware.AddErrorToContext(c, customerrors.NewTooManyRequestsError(fmt.Sprintf(errTooManyLoginAttempts, int(retryAfter.Seconds())), retryAfter))
+				return
+			}
+		}
+
 		token, err := tokenProvider.ObtainToken(username, password, logger, c)
 		if err != nil {
 			if errors.Is(err, auth.ErrInvalidCredentials) {
 				logger.Warn("Invalid credentials provided", zap.Error(err))
+				recordLoginFailure(c, loginLimiter, username, logger)
 				middleware.AddErrorToContext(c, customerrors.NewUnauthorizedError(err.Error()))
 			} else {
 				logger.Error("Failed to obtain OpenShift token", zap.Error(err))
@@ -42,10 +72,156 @@ func Login(tokenProvider auth.TokenProvider) gin.HandlerFunc {
 			return
 		}
 
-		result := types.LoginOutput{
-			Token: token,
+		if loginLimiter != nil {
+			if err := loginLimiter.RecordSuccess(c.Request.Context(), username); err != nil {
+				logger.Warn("Failed to clear failed logins", zap.Error(err))
+			}
+		}
+
+		respondWithToken(c, token, query.Session, sessionManager, logger)
+	}
+}
+
+// recordLoginFailure records a failed login in the login limiter, if one is set.
+// A failure to record it is only logged, as the login already failed.
+func recordLoginFailure(c *gin.Context, loginLimiter *auth.LoginLimiter, username string, logger *zap.Logger) {
+	if loginLimiter == nil {
+		return
+	}
+
+	if err := loginLimiter.RecordFailure(c.Request.Context(), username, c.ClientIP()); err != nil {
+		logger.Warn("Failed to record failed login", zap.Error(err))
+	}
+}
+
+// LoginRefresh exchanges a refresh token for a new token, so that sessions can be extended without asking for the password again.
+// With ?session=true, the refresh token is taken from the session cookie, which requires the CSRF token of the session,
+// and the new token is set in it instead of being returned.
+func LoginRefresh(tokenProvider auth.TokenProvider, sessionManager *middleware.SessionManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctxLogger, _ := c.Get("logger")
+		logger := ctxLogger.(*zap.Logger)
+
+		query, err := bindLoginQuery(c, sessionManager)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		var request types.LoginRefreshInput
+		if query.Session {
+			request.RefreshToken, err = sessionManager.RefreshTokenFromSession(c)
+			if err != nil {
+				logger.Warn("Invalid session provided", zap.Error(err))
+				middleware.AddErrorToContext(c, customerrors.NewUnauthorizedError(err.Error()))
+				return
+			}
+		} else if err := c.BindJSON(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		token, err := auth.RefreshToken(tokenProvider, request.RefreshToken, logger, c)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidRefreshToken) {
+				logger.Warn("Invalid refresh token provided", zap.Error(err))
+				middleware.AddErrorToContext(c, customerrors.NewUnauthorizedError(err.Error()))
+			} else if errors.Is(err, auth.ErrRefreshNotSupported) {
+				logger.Warn("Refreshing tokens is not supported", zap.Error(err))
+				middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			} else {
+				logger.Error("Failed to refresh token", zap.Error(err))
+				middleware.AddErrorToContext(c, customerrors.NewInternalServerError(err.Error()))
+			}
+			return
+		}
+
+		respondWithToken(c, token, query.Session, sessionManager, logger)
+	}
+}
+
+// Logout revokes the token of the caller through the client of the caller, and clears the cached
+// identity and clients of the token, along with the session cookies. The token can no longer be used
+// once the logout succeeds.
+func Logout(tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger, err := middleware.GetLogger(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		token, err := middleware.GetToken(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
 		}
 
-		c.JSON(http.StatusOK, result)
+		client, err := middleware.GetDynClient(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		if err := auth.RevokeToken(c.Request.Context(), tokenProvider, client, token, logger); err != nil {
+			if errors.Is(err, auth.ErrRevokeNotSupported) {
+				logger.Warn("Revoking tokens is not supported", zap.Error(err))
+				middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			} else {
+				logger.Error(fmt.Sprintf("%v with error: %v", errCouldNotRevokeToken, err))
+				middleware.AddErrorToContext(c, customerrors.NewAPIError(errCouldNotRevokeToken, err))
+			}
+			return
+		}
+		clientCache.Remove(token)
+		if sessionManager != nil {
+			sessionManager.ClearSession(c)
+		}
+
+		c.JSON(http.StatusOK, types.MessageResponse{Message: msgLoggedOut})
 	}
 }
+
+// bindLoginQuery binds the query of a login request, and makes sure a session is only requested in session mode.
+func bindLoginQuery(c *gin.Context, sessionManager *middleware.SessionManager) (types.LoginQuery, error) {
+	var query types.LoginQuery
+	if err := c.BindQuery(&query); err != nil {
+		return types.LoginQuery{}, customerrors.NewValidationError(err.Error())
+	}
+
+	if query.Session && sessionManager == nil {
+		return types.LoginQuery{}, customerrors.NewValidationError(errSessionModeDisabled)
+	}
+
+	return query, nil
+}
+
+// respondWithToken responds with the token, or sets it in a session cookie and responds with the CSRF token of the session.
+func respondWithToken(c *gin.Context, token *oauth2.Token, session bool, sessionManager *middleware.SessionManager, logger *zap.Logger) {
+	output := convertTokenToLoginOutput(token)
+	if session {
+		csrfToken, err := sessionManager.SetSession(c, token)
+		if err != nil {
+			logger.Error(fmt.Sprintf("%v with error: %v", errCouldNotSetSession, err))
+			middleware.AddErrorToContext(c, customerrors.NewInternalServerError(errCouldNotSetSession))
+			return
+		}
+		output.Token = ""
+		output.RefreshToken = ""
+		output.CSRFToken = csrfToken
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// convertTokenToLoginOutput converts an OAuth2 token to a LoginOutput. The expiry and refresh token
+// are only set when the identity provider issued them.
+func convertTokenToLoginOutput(token *oauth2.Token) types.LoginOutput {
+	result := types.LoginOutput{
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+
+	if !token.Expiry.IsZero() {
+		expiry := token.Expiry.UTC()
+		result.ExpirationTimestamp = &expiry
+	}
+
+	return result
+}
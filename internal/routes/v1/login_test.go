@@ -1,16 +1,32 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/dana-team/platform-backend/internal/auth"
 	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/routes"
+	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+	runtimeFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -29,10 +45,11 @@ const (
 	invalidUser     = "invalid_user"
 	validPassword   = "valid_password"
 	invalidPassword = "invalid_password"
+	logoutToken     = "sha256~logout_token"
 )
 
-func (m MockTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (string, error) {
-	return m.token, m.err
+func (m MockTokenProvider) ObtainToken(username, password string, logger *zap.Logger, ctx *gin.Context) (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: m.token}, m.err
 }
 
 func (m MockTokenProvider) ObtainUsername(token string, logger *zap.Logger) (string, error) {
@@ -41,6 +58,16 @@ func (m MockTokenProvider) ObtainUsername(token string, logger *zap.Logger) (str
 
 // setupLogin sets up a router for the Login routes
 func setupLogin(tokenProvider auth.TokenProvider) (*gin.Engine, error) {
+	return setupLoginWithLimiter(tokenProvider, nil)
+}
+
+// setupLoginWithLimiter sets up a router for the Login routes which throttles logins with the given limiter
+func setupLoginWithLimiter(tokenProvider auth.TokenProvider, loginLimiter *auth.LoginLimiter) (*gin.Engine, error) {
+	return setupLoginWithSession(tokenProvider, loginLimiter, nil)
+}
+
+// setupLoginWithSession sets up a router for the Login routes which stores tokens in sessions of the given manager
+func setupLoginWithSession(tokenProvider auth.TokenProvider, loginLimiter *auth.LoginLimiter, sessionManager *middleware.SessionManager) (*gin.Engine, error) {
 	r := gin.New()
 
 	mockLogger, err := zap.NewDevelopment()
@@ -49,7 +76,8 @@ func setupLogin(tokenProvider auth.TokenProvider) (*gin.Engine, error) {
 	}
 	r.Use(middleware.LoggerMiddleware(mockLogger))
 	r.Use(middleware.ErrorHandlingMiddleware())
-	r.POST("/login", Login(tokenProvider))
+	r.POST("/login", Login(tokenProvider, loginLimiter, sessionManager))
+	r.POST("/login/refresh", LoginRefresh(tokenProvider, sessionManager))
 
 	return r, nil
 }
@@ -156,3 +184,510 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginLockout(t *testing.T) {
+	loginLimiter, err := auth.NewLoginLimiter(auth.NewMemoryLoginAttemptStore(), auth.LoginLimiterConfig{
+		UserRateLimit:   10,
+		IPRateLimit:     10,
+		RateWindow:      time.Minute,
+		UserMaxFailures: 2,
+		LockoutDuration: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	type want struct {
+		statusCode int
+		retryAfter string
+		response   map[string]interface{}
+	}
+
+	steps := []struct {
+		tokenProvider auth.TokenProvider
+		username      string
+		want          want
+	}{
+		{
+			tokenProvider: MockTokenProvider{err: auth.ErrInvalidCredentials},
+			username:      validUser,
+			want:          want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			tokenProvider: MockTokenProvider{err: auth.ErrInvalidCredentials},
+			username:      validUser,
+			want:          want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			tokenProvider: MockTokenProvider{token: validTokenKey},
+			username:      validUser,
+			want: want{
+				statusCode: http.StatusTooManyRequests,
+				retryAfter: "3600",
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Too many login attempts, retry in 3600 seconds",
+					testutils.ReasonKey: metav1.StatusReasonTooManyRequests,
+				},
+			},
+		},
+		{
+			tokenProvider: MockTokenProvider{token: validTokenKey},
+			username:      invalidUser,
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					tokenKey: validTokenKey,
+				},
+			},
+		},
+	}
+
+	for i, step := range steps {
+		router, err := setupLoginWithLimiter(step.tokenProvider, loginLimiter)
+		assert.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/login", nil)
+		assert.NoError(t, err)
+		request.SetBasicAuth(step.username, validPassword)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		assert.Equal(t, step.want.statusCode, writer.Code, "step %d", i)
+		assert.Equal(t, step.want.retryAfter, writer.Header().Get("Retry-After"), "step %d", i)
+		if step.want.response == nil {
+			continue
+		}
+
+		var response map[string]interface{}
+		err = json.Unmarshal(writer.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		wantResponseJSON, err := json.Marshal(step.want.response)
+		assert.NoError(t, err)
+		var wantResponseNormalized map[string]interface{}
+		err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+		assert.NoError(t, err)
+		assert.Equal(t, wantResponseNormalized, response, "step %d", i)
+	}
+}
+
+func TestLoginSession(t *testing.T) {
+	sessionManager, err := middleware.NewSessionManager([]byte("0123456789abcdef0123456789abcdef"), http.SameSiteStrictMode)
+	assert.NoError(t, err)
+
+	type args struct {
+		sessionManager *middleware.SessionManager
+	}
+	type want struct {
+		statusCode int
+		cookies    []string
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedSettingSessionCookies": {
+			args: args{sessionManager: sessionManager},
+			want: want{
+				statusCode: http.StatusOK,
+				cookies:    []string{middleware.SessionCookieName, middleware.CSRFCookieName},
+			},
+		},
+		"ShouldFailWhenSessionModeIsDisabled": {
+			args: args{},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			router, err := setupLoginWithSession(MockTokenProvider{token: validTokenKey}, nil, test.args.sessionManager)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/login?session=true", nil)
+			assert.NoError(t, err)
+			request.SetBasicAuth(validUser, validPassword)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, test.want.statusCode, writer.Code)
+			if test.want.statusCode != http.StatusOK {
+				return
+			}
+
+			var cookies []string
+			for _, cookie := range writer.Result().Cookies() {
+				cookies = append(cookies, cookie.Name)
+			}
+			assert.ElementsMatch(t, test.want.cookies, cookies)
+
+			var response types.LoginOutput
+			assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+			assert.Empty(t, response.Token)
+			assert.NotEmpty(t, response.CSRFToken)
+		})
+	}
+}
+
+// setupOAuthServer starts a fake OAuth server and points the OpenShift token provider at it.
+func setupOAuthServer() func() {
+	server := mocks.NewOAuthServer()
+	_ = os.Setenv("INSECURE_SKIP_VERIFY", "true")
+	_ = os.Setenv("KUBE_CLIENT_ID", "clientid")
+	_ = os.Setenv("KUBE_AUTH_URL", server.URL+"/auth")
+	_ = os.Setenv("KUBE_TOKEN_URL", server.URL+"/token")
+
+	return server.Close
+}
+
+func TestLoginWithExpiry(t *testing.T) {
+	defer setupOAuthServer()()
+
+	router, err := setupLogin(auth.DefaultTokenProvider{})
+	assert.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/login", nil)
+	assert.NoError(t, err)
+	request.SetBasicAuth(validUser, validPassword)
+
+	writer := httptest.NewRecorder()
+	before := time.Now()
+	router.ServeHTTP(writer, request)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	var response types.LoginOutput
+	err = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, testutils.OAuthAccessToken, response.Token)
+	assert.Equal(t, testutils.OAuthRefreshToken, response.RefreshToken)
+	if assert.NotNil(t, response.ExpirationTimestamp) {
+		assert.WithinDuration(t, before.Add(testutils.OAuthExpiresIn*time.Second), *response.ExpirationTimestamp, time.Minute)
+	}
+}
+
+func TestLoginRefresh(t *testing.T) {
+	defer setupOAuthServer()()
+
+	type args struct {
+		tokenProvider auth.TokenProvider
+		body          string
+	}
+	type want struct {
+		statusCode int
+		token      string
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedRefreshingToken": {
+			args: args{
+				tokenProvider: auth.DefaultTokenProvider{},
+				body:          `{"refreshToken": "` + testutils.OAuthRefreshToken + `"}`,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				token:      testutils.OAuthRefreshedAccessToken,
+			},
+		},
+		"ShouldFailWithInvalidRefreshToken": {
+			args: args{
+				tokenProvider: auth.DefaultTokenProvider{},
+				body:          `{"refreshToken": "invalid_refresh_token"}`,
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  auth.ErrInvalidRefreshToken.Error(),
+					testutils.ReasonKey: metav1.StatusReasonUnauthorized,
+				},
+			},
+		},
+		"ShouldFailWithMissingRefreshToken": {
+			args: args{
+				tokenProvider: auth.DefaultTokenProvider{},
+				body:          `{}`,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'LoginRefreshInput.RefreshToken' Error:Field validation for 'RefreshToken' failed on the 'required' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldFailWithUnsupportedTokenProvider": {
+			args: args{
+				tokenProvider: MockTokenProvider{},
+				body:          `{"refreshToken": "` + testutils.OAuthRefreshToken + `"}`,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  auth.ErrRefreshNotSupported.Error(),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			router, err := setupLogin(test.args.tokenProvider)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/login/refresh", strings.NewReader(test.args.body))
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			if test.want.statusCode == http.StatusOK {
+				var response types.LoginOutput
+				err = json.Unmarshal(writer.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, test.want.token, response.Token)
+				assert.Equal(t, testutils.OAuthRefreshToken, response.RefreshToken)
+				assert.NotNil(t, response.ExpirationTimestamp)
+				return
+			}
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
+func TestLoginRefreshSession(t *testing.T) {
+	defer setupOAuthServer()()
+
+	sessionManager, err := middleware.NewSessionManager([]byte("0123456789abcdef0123456789abcdef"), http.SameSiteStrictMode)
+	assert.NoError(t, err)
+	router, err := setupLoginWithSession(auth.DefaultTokenProvider{}, nil, sessionManager)
+	assert.NoError(t, err)
+
+	loginRequest, err := http.NewRequest(http.MethodPost, "/login?session=true", nil)
+	assert.NoError(t, err)
+	loginRequest.SetBasicAuth(validUser, validPassword)
+	loginWriter := httptest.NewRecorder()
+	router.ServeHTTP(loginWriter, loginRequest)
+	assert.Equal(t, http.StatusOK, loginWriter.Code)
+
+	var loginResponse types.LoginOutput
+	assert.NoError(t, json.Unmarshal(loginWriter.Body.Bytes(), &loginResponse))
+
+	type want struct {
+		statusCode int
+		cookies    []string
+	}
+	cases := map[string]struct {
+		csrfToken string
+		want      want
+	}{
+		"ShouldSucceedRefreshingSessionWithCSRFToken": {
+			csrfToken: loginResponse.CSRFToken,
+			want: want{
+				statusCode: http.StatusOK,
+				cookies:    []string{middleware.SessionCookieName, middleware.CSRFCookieName},
+			},
+		},
+		"ShouldFailRefreshingSessionWithoutCSRFToken": {
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		"ShouldFailRefreshingSessionWithWrongCSRFToken": {
+			csrfToken: "wrong",
+			want:      want{statusCode: http.StatusUnauthorized},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodPost, "/login/refresh?session=true", nil)
+			assert.NoError(t, err)
+			for _, cookie := range loginWriter.Result().Cookies() {
+				request.AddCookie(cookie)
+			}
+			if test.csrfToken != "" {
+				request.Header.Set(middleware.CSRFHeader, test.csrfToken)
+			}
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var cookies []string
+			for _, cookie := range writer.Result().Cookies() {
+				cookies = append(cookies, cookie.Name)
+			}
+			assert.ElementsMatch(t, test.want.cookies, cookies)
+		})
+	}
+}
+
+// setupLogout sets up a router for the Logout route, with the clients of the caller set in the context
+func setupLogout(tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, dynClient runtimeClient.Client) (*gin.Engine, error) {
+	r := gin.New()
+
+	mockLogger, err := zap.NewDevelopment()
+	if err != nil {
+		return nil, err
+	}
+	r.Use(middleware.LoggerMiddleware(mockLogger))
+	r.Use(middleware.ErrorHandlingMiddleware())
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.DynamicClientCtxKey, dynClient)
+		c.Set(middleware.TokenCtxKey, logoutToken)
+		c.Next()
+	})
+	r.POST("/logout", Logout(tokenProvider, clientCache, nil))
+
+	return r, nil
+}
+
+func TestLogout(t *testing.T) {
+	type args struct {
+		tokenProvider auth.TokenProvider
+		tokenExists   bool
+	}
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedRevokingToken": {
+			args: args{
+				tokenProvider: auth.DefaultTokenProvider{},
+				tokenExists:   true,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.MessageKey: msgLoggedOut,
+				},
+			},
+		},
+		"ShouldSucceedWithAlreadyRevokedToken": {
+			args: args{
+				tokenProvider: auth.DefaultTokenProvider{},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.MessageKey: msgLoggedOut,
+				},
+			},
+		},
+		"ShouldFailWithUnsupportedTokenProvider": {
+			args: args{
+				tokenProvider: MockTokenProvider{},
+				tokenExists:   true,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  auth.ErrRevokeNotSupported.Error(),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(oauthv1.AddToScheme(scheme))
+			builder := runtimeFake.NewClientBuilder().WithScheme(scheme)
+			if test.args.tokenExists {
+				builder.WithObjects(mocks.PrepareOAuthAccessToken(auth.OAuthAccessTokenName(logoutToken)))
+			}
+			logoutDynClient := builder.Build()
+
+			clientCache, err := middleware.NewClientCache(scheme, 1, time.Minute)
+			assert.NoError(t, err)
+			clients, err := clientCache.NewClients(logoutToken, auth.Identity{Username: validUser})
+			assert.NoError(t, err)
+			clientCache.Add(logoutToken, clients)
+
+			router, err := setupLogout(test.args.tokenProvider, clientCache, logoutDynClient)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/logout", nil)
+			assert.NoError(t, err)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+
+			_, cached := clientCache.Get(logoutToken)
+			if test.want.statusCode == http.StatusOK {
+				assert.False(t, cached)
+				err = logoutDynClient.Get(context.Background(), runtimeClient.ObjectKey{Name: auth.OAuthAccessTokenName(logoutToken)}, &oauthv1.OAuthAccessToken{})
+				assert.True(t, k8serrors.IsNotFound(err))
+			} else {
+				assert.True(t, cached)
+			}
+		})
+	}
+}
+
+func TestLoginSpoofedForwardedFor(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+
+	loginLimiter, err := auth.NewLoginLimiter(auth.NewMemoryLoginAttemptStore(), auth.LoginLimiterConfig{
+		UserRateLimit:   10,
+		IPRateLimit:     2,
+		RateWindow:      time.Minute,
+		UserMaxFailures: 10,
+		IPMaxFailures:   10,
+		LockoutDuration: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	router, err := setupLoginWithLimiter(MockTokenProvider{token: validTokenKey}, loginLimiter)
+	assert.NoError(t, err)
+	assert.NoError(t, routes.SetTrustedProxiesFromEnv(router))
+
+	forwardedFor := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	wantStatusCodes := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+
+	for i, ip := range forwardedFor {
+		request, err := http.NewRequest(http.MethodPost, "/login", nil)
+		assert.NoError(t, err)
+		request.RemoteAddr = "192.0.2.1:1234"
+		request.Header.Set("X-Forwarded-For", ip)
+		request.SetBasicAuth(fmt.Sprintf("%s-%d", validUser, i), validPassword)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		assert.Equal(t, wantStatusCodes[i], writer.Code, "step %d", i)
+	}
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/utils/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// meHandler handles the request of the client to the Kubernetes cluster.
+func meHandler(roleCatalog *controllers.RoleCatalog, handler func(controller controllers.MeController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kubeClient, err := middleware.GetKubeClient(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		logger, err := middleware.GetLogger(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		context := c.Request.Context()
+		meController := controllers.NewMeController(kubeClient, context, logger, roleCatalog)
+
+		result, err := handler(meController, c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// GetMe returns the logged in user, along with the namespaces the user can access.
+func GetMe(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, page, err := pagination.ExtractPaginationParamsFromCtx(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		identity, err := middleware.GetIdentity(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		meHandler(roleCatalog, func(controller controllers.MeController, c *gin.Context) (interface{}, error) {
+			return controller.GetMe(identity.Username, identity.Groups, limit, page)
+		})(c)
+	}
+}
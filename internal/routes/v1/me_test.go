@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMe(t *testing.T) {
+	testNamespaceName := testutils.TestNamespace + "-me"
+
+	type pagination struct {
+		limit string
+		page  string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	type args struct {
+		paginationParams pagination
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedGettingMe": {
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.UsernameKey: identity.Username,
+					testutils.GroupsKey:   identity.Groups,
+					testutils.CountKey:    2,
+					testutils.NamespaceKey: []types.NamespaceRole{
+						{Name: testNamespaceName + "-1", Role: testutils.AdminKey},
+						{Name: testNamespaceName + "-2", Role: testutils.ContributorKey},
+					},
+				},
+			},
+		},
+		"ShouldSucceedGettingMeWithLimitOf2": {
+			args: args{
+				paginationParams: pagination{limit: "2", page: "1"},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.UsernameKey: identity.Username,
+					testutils.GroupsKey:   identity.Groups,
+					testutils.CountKey:    2,
+					testutils.NamespaceKey: []types.NamespaceRole{
+						{Name: testNamespaceName + "-1", Role: testutils.AdminKey},
+						{Name: testNamespaceName + "-2", Role: testutils.ContributorKey},
+					},
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName+"-1")
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName+"-2")
+	mocks.CreateTestRoleBinding(fakeClient, identity.Username, testNamespaceName+"-1", testutils.AdminKey)
+	mocks.CreateTestGroupRoleBinding(fakeClient, testutils.GroupName, testNamespaceName+"-2", testutils.GroupName, testutils.ContributorKey)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			params := url.Values{}
+			if test.args.paginationParams.limit != "" {
+				params.Add(middleware.LimitCtxKey, test.args.paginationParams.limit)
+			}
+
+			if test.args.paginationParams.page != "" {
+				params.Add(middleware.PageCtxKey, test.args.paginationParams.page)
+			}
+
+			baseURI := "/v1/me"
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
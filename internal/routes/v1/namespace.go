@@ -94,3 +94,23 @@ func DeleteNamespace() gin.HandlerFunc {
 		})(c)
 	}
 }
+
+// namespaceActions maps the custom actions which are sent as POST /v1/namespaces/:namespaceName/<action>
+// to their handlers. Actions contain a colon, which gin cannot match as part of a static route.
+var namespaceActions = map[string]gin.HandlerFunc{
+	"permissions:check": CheckPermissions(),
+}
+
+// NamespaceAction dispatches a custom action on a specific namespace to its handler.
+func NamespaceAction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action := c.Param("namespaceAction")
+		handler, ok := namespaceActions[action]
+		if !ok {
+			middleware.AddErrorToContext(c, customerrors.NewNotFoundError(fmt.Sprintf("Unknown namespace action %q", action)))
+			return
+		}
+
+		handler(c)
+	}
+}
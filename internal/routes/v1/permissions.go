@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// permissionsHandler handles the request of the client to the Kubernetes cluster.
+func permissionsHandler(handler func(controller controllers.PermissionController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kubeClient, err := middleware.GetKubeClient(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		logger, err := middleware.GetLogger(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		context := c.Request.Context()
+		permissionController := controllers.NewPermissionController(kubeClient, context, logger)
+
+		result, err := handler(permissionController, c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// CheckPermissions checks whether the logged in user may perform a batch of actions in a specific namespace.
+func CheckPermissions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var uriRequest types.NamespaceUri
+		if err := c.BindUri(&uriRequest); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		var request types.PermissionsCheckInput
+		if err := c.BindJSON(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		permissionsHandler(func(controller controllers.PermissionController, c *gin.Context) (interface{}, error) {
+			return controller.CheckPermissions(uriRequest.NamespaceName, request.Checks)
+		})(c)
+	}
+}
@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	testNamespaceName := testutils.TestNamespace + "-permissions"
+
+	type requestURI struct {
+		namespace string
+		action    string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		requestURI  requestURI
+		requestData interface{}
+		want        want
+	}{
+		"ShouldSucceedCheckingPermissions": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				action:    "permissions:check",
+			},
+			requestData: types.PermissionsCheckInput{Checks: []types.PermissionCheck{
+				{Preset: "pods:exec"},
+				{Verb: "delete", Group: "rcs.dana.io", Resource: "capps", Name: testutils.CappName},
+			}},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.ResultsKey: []types.PermissionCheckResult{
+						{
+							PermissionCheck: types.PermissionCheck{Preset: "pods:exec", Verb: "create", Resource: "pods", Subresource: "exec"},
+							Allowed:         true,
+							Reason:          testutils.AccessAllowedReason,
+						},
+						{
+							PermissionCheck: types.PermissionCheck{Verb: "delete", Group: "rcs.dana.io", Resource: "capps", Name: testutils.CappName},
+							Allowed:         false,
+						},
+					},
+				},
+			},
+		},
+		"ShouldFailWithoutChecks": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				action:    "permissions:check",
+			},
+			requestData: types.PermissionsCheckInput{},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'PermissionsCheckInput.Checks' Error:Field validation for 'Checks' failed on the 'required' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldFailWithUnknownPreset": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				action:    "permissions:check",
+			},
+			requestData: types.PermissionsCheckInput{Checks: []types.PermissionCheck{{Preset: "capps:explode"}}},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf(controllers.ErrUnknownPermissionPreset, "capps:explode",
+						"capps:create, capps:delete, capps:update, pods:exec, pods:logs, secrets:create, secrets:delete, secrets:update, serviceaccounts:create, users:manage"),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldFailWithUnknownAction": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+				action:    "permissions:grant",
+			},
+			requestData: types.PermissionsCheckInput{Checks: []types.PermissionCheck{{Preset: "pods:exec"}}},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf("Unknown namespace action %q", "permissions:grant"),
+					testutils.ReasonKey: metav1.StatusReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.AddTestAccessReviewReactor(fakeClient, "create pods/exec")
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := json.Marshal(test.requestData)
+			assert.NoError(t, err)
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/%s", test.requestURI.namespace, test.requestURI.action)
+			request, err := http.NewRequest(http.MethodPost, baseURI, bytes.NewBuffer(payload))
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
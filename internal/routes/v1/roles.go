@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoles returns the platform roles which may be granted to members of namespaces, from the least to the most privileged.
+func GetRoles(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, types.RoleCatalog{Roles: roleCatalog.Roles()})
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestGetRoles(t *testing.T) {
+	maintainer := types.Role{Name: "maintainer", Description: "Maintains the Capps of the namespace", ClusterRole: "capp-user-maintainer"}
+
+	type want struct {
+		statusCode int
+		response   types.RoleCatalog
+	}
+
+	cases := map[string]struct {
+		roles []types.Role
+		want  want
+	}{
+		"ShouldSucceedGettingDefaultRoles": {
+			want: want{
+				statusCode: http.StatusOK,
+				response: types.RoleCatalog{Roles: []types.Role{
+					{
+						Name: controllers.ViewerPlatformRole, Description: "Views the resources of the namespace", ClusterRole: controllers.ViewerClusterRole,
+						Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{"rcs.dana.io"}, Resources: []string{testutils.CappsKey}}},
+					},
+					{
+						Name: controllers.ContributorPlatformRole, Description: "Manages the Capps and secrets of the namespace", ClusterRole: controllers.ContributorClusterRole,
+						Rules: []rbacv1.PolicyRule{{Verbs: []string{"update"}, APIGroups: []string{"rcs.dana.io"}, Resources: []string{testutils.CappsKey}}},
+					},
+					{
+						Name: controllers.AdminPlatformRole, Description: "Manages the namespace, including its members", ClusterRole: controllers.AdminClusterRole,
+						Rules: []rbacv1.PolicyRule{{Verbs: []string{"create"}, APIGroups: []string{rbacv1.GroupName}, Resources: []string{testutils.RoleBindingsKey}}},
+					},
+				}},
+			},
+		},
+		"ShouldSucceedGettingConfiguredRoles": {
+			roles: []types.Role{maintainer},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   types.RoleCatalog{Roles: []types.Role{maintainer}},
+			},
+		},
+	}
+
+	setup()
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			// The shared router serves the default catalog, so configured catalogs are served by their own router.
+			router := router
+			if test.roles != nil {
+				catalog, err := controllers.NewRoleCatalog(test.roles)
+				assert.NoError(t, err)
+				router = gin.New()
+				router.GET("/v1/roles", GetRoles(catalog))
+			}
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/roles", nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response types.RoleCatalog
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.response, response)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/routes"
 	"github.com/dana-team/platform-backend/internal/utils/pagination"
 	"net/http"
 
@@ -87,7 +88,9 @@ func GetSecret() gin.HandlerFunc {
 		}
 
 		secretHandler(func(controller controllers.SecretController, c *gin.Context) (interface{}, error) {
-			return controller.GetSecret(request.NamespaceName, request.SecretName)
+			secret, err := controller.GetSecret(request.NamespaceName, request.SecretName)
+			routes.SetETag(c, secret.ResourceVersion)
+			return secret, err
 		})(c)
 	}
 }
@@ -105,9 +108,16 @@ func UpdateSecret() gin.HandlerFunc {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		resourceVersion, err := routes.GetIfMatch(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
 
 		secretHandler(func(controller controllers.SecretController, c *gin.Context) (interface{}, error) {
-			return controller.UpdateSecret(uriRequest.NamespaceName, uriRequest.SecretName, request)
+			secret, err := controller.UpdateSecret(uriRequest.NamespaceName, uriRequest.SecretName, request, resourceVersion)
+			routes.SetETag(c, secret.ResourceVersion)
+			return secret, err
 		})(c)
 	}
 }
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/dana-team/platform-backend/internal/utils/pagination"
@@ -14,7 +16,7 @@ import (
 )
 
 // serviceAccountHandler wraps a handler function with context setup for serviceAccountController.
-func serviceAccountHandler(handler func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+func serviceAccountHandler(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog, handler func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		kubeClient, err := middleware.GetKubeClient(c)
 		if middleware.AddErrorToContext(c, err) {
@@ -27,7 +29,7 @@ func serviceAccountHandler(handler func(controller controllers.ServiceAccountCon
 		}
 
 		context := c.Request.Context()
-		serviceAccountController := controllers.NewServiceAccountController(kubeClient, context, logger)
+		serviceAccountController := controllers.NewServiceAccountController(kubeClient, context, logger, tokenPolicy, roleCatalog)
 
 		result, err := handler(serviceAccountController, c)
 		if middleware.AddErrorToContext(c, err) {
@@ -39,7 +41,7 @@ func serviceAccountHandler(handler func(controller controllers.ServiceAccountCon
 }
 
 // GetToken returns a Gin handler function for retrieving token of a specific service account.
-func GetToken() gin.HandlerFunc {
+func GetToken(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -47,14 +49,14 @@ func GetToken() gin.HandlerFunc {
 			return
 		}
 
-		serviceAccountHandler(func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
 			return controller.GetServiceAccountToken(request.ServiceAccountName, request.NamespaceName)
 		})(c)
 	}
 }
 
 // CreateServiceAccount returns a Gin handler function for creating a service account.
-func CreateServiceAccount() gin.HandlerFunc {
+func CreateServiceAccount(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -62,14 +64,23 @@ func CreateServiceAccount() gin.HandlerFunc {
 			return
 		}
 
-		serviceAccountHandler(func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
-			return controller.CreateServiceAccount(request.ServiceAccountName, request.NamespaceName)
+		// The body is optional, so that ServiceAccounts can still be created by name only.
+		var body types.CreateServiceAccountRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+				middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+				return
+			}
+		}
+
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+			return controller.CreateServiceAccount(request.ServiceAccountName, request.NamespaceName, body)
 		})(c)
 	}
 }
 
 // DeleteServiceAccount returns a Gin handler function for deleting a service account.
-func DeleteServiceAccount() gin.HandlerFunc {
+func DeleteServiceAccount(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -77,7 +88,7 @@ func DeleteServiceAccount() gin.HandlerFunc {
 			return
 		}
 
-		serviceAccountHandler(func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
 			name := request.ServiceAccountName
 			message := fmt.Sprintf("Deleted serviceAccount successfully %q", name)
 			return types.MessageResponse{Message: message}, controller.DeleteServiceAccount(request.ServiceAccountName, request.NamespaceName)
@@ -86,7 +97,7 @@ func DeleteServiceAccount() gin.HandlerFunc {
 }
 
 // GetServiceAccounts returns a Gin handler function for retrieving service accounts in a namespace.
-func GetServiceAccounts() gin.HandlerFunc {
+func GetServiceAccounts(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var namespace types.NamespaceUri
 		if err := c.BindUri(&namespace); err != nil {
@@ -100,14 +111,14 @@ func GetServiceAccounts() gin.HandlerFunc {
 			return
 		}
 
-		serviceAccountHandler(func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
 			return controller.GetServiceAccounts(namespace.NamespaceName, limit, page)
 		})(c)
 	}
 }
 
 // GetServiceAccount returns a Gin handler function for retrieving a specific service account from a namespace.
-func GetServiceAccount() gin.HandlerFunc {
+func GetServiceAccount(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -115,8 +126,60 @@ func GetServiceAccount() gin.HandlerFunc {
 			return
 		}
 
-		serviceAccountHandler(func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
 			return controller.GetServiceAccount(request.ServiceAccountName, request.NamespaceName)
 		})(c)
 	}
 }
+
+// GetServiceAccountRole returns a Gin handler function for retrieving the platform role of a service account.
+func GetServiceAccountRole(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.ServiceAccountRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+			return controller.GetServiceAccountRole(request.ServiceAccountName, request.NamespaceName)
+		})(c)
+	}
+}
+
+// SetServiceAccountRole returns a Gin handler function for setting the platform role of a service account.
+func SetServiceAccountRole(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.ServiceAccountRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		var role types.ServiceAccountRole
+		if err := c.BindJSON(&role); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+			return controller.SetServiceAccountRole(request.ServiceAccountName, request.NamespaceName, role)
+		})(c)
+	}
+}
+
+// DeleteServiceAccountRole returns a Gin handler function for removing the platform role of a service account.
+func DeleteServiceAccountRole(tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.ServiceAccountRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		serviceAccountHandler(tokenPolicy, roleCatalog, func(controller controllers.ServiceAccountController, c *gin.Context) (interface{}, error) {
+			message := fmt.Sprintf("Removed role of ServiceAccount %q", request.ServiceAccountName)
+			return types.MessageResponse{Message: message}, controller.DeleteServiceAccountRole(request.ServiceAccountName, request.NamespaceName)
+		})(c)
+	}
+}
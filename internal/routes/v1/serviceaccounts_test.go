@@ -1,8 +1,10 @@
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +13,8 @@ import (
 	"github.com/dana-team/platform-backend/internal/middleware"
 
 	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 
@@ -46,7 +50,21 @@ func TestGetServiceAccountToken(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.TokenKey: testutils.Value,
+					testutils.TokenKey:     testutils.Value,
+					testutils.MechanismKey: controllers.TokenMechanismDockercfg,
+				},
+			},
+		},
+		"ShouldNotSucceedGettingTokenWithoutTokenRequestSecret": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName + "-unbound",
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrTokenRequestSecretNotFound, testutils.ServiceAccountName+"-unbound", testNamespaceName),
+					testutils.ReasonKey: testutils.ReasonNotFound,
 				},
 			},
 		},
@@ -87,6 +105,7 @@ func TestGetServiceAccountToken(t *testing.T) {
 	setup()
 	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
 	mocks.CreateTestServiceAccountWithToken(fakeClient, testNamespaceName, testutils.ServiceAccountName, "token-secret", "value", "docker-cfg")
+	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, testutils.ServiceAccountName+"-unbound", "")
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -121,6 +140,7 @@ func TestCreateServiceAccountToken(t *testing.T) {
 		serviceAccountName         string
 		namespace                  string
 		existingServiceAccountName string
+		requestData                interface{}
 	}
 
 	type want struct {
@@ -141,7 +161,44 @@ func TestCreateServiceAccountToken(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.NameKey: testutils.ServiceAccountName,
+					testutils.NameKey:   testutils.ServiceAccountName,
+					testutils.LabelsKey: map[string]string{testutils.ManagedLabel: testutils.ManagedLabelValue},
+				},
+			},
+		},
+		"ShouldSucceedCreatingServiceAccountWithDescriptionAndLabels": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName + "-described",
+				requestData: types.CreateServiceAccountRequest{
+					Description: testutils.ServiceAccountDescription,
+					Labels:      map[string]string{testutils.LabelKey: testutils.LabelValue},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.NameKey:        testutils.ServiceAccountName + "-described",
+					"description":            testutils.ServiceAccountDescription,
+					testutils.LabelsKey:      map[string]string{testutils.LabelKey: testutils.LabelValue, testutils.ManagedLabel: testutils.ManagedLabelValue},
+					testutils.AnnotationsKey: map[string]string{utils.ServiceAccountDescriptionAnnotation: testutils.ServiceAccountDescription},
+				},
+			},
+		},
+		"ShouldNotSucceedCreatingServiceAccountWithInvalidLabels": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName + "-invalid",
+				requestData: types.CreateServiceAccountRequest{
+					Labels: map[string]string{"invalid key!": testutils.LabelValue},
+				},
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf(controllers.ErrInvalidServiceAccountLabels, testutils.ServiceAccountName+"-invalid",
+						`labels: Invalid value: "invalid key!": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`),
+					testutils.ReasonKey: testutils.ReasonBadRequest,
 				},
 			},
 		},
@@ -174,9 +231,17 @@ func TestCreateServiceAccountToken(t *testing.T) {
 			}
 			params := url.Values{}
 
+			var body io.Reader
+			if test.args.requestData != nil {
+				payload, err := json.Marshal(test.args.requestData)
+				assert.NoError(t, err)
+				body = bytes.NewBuffer(payload)
+			}
+
 			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s", test.args.namespace, test.args.serviceAccountName)
-			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s?%s", baseURI, params.Encode()), body)
 			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
 			writer := httptest.NewRecorder()
 			router.ServeHTTP(writer, request)
 
@@ -317,8 +382,11 @@ func TestGetServiceAccounts(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.CountKey:           2,
-					testutils.ServiceAccountsKey: []string{fmt.Sprintf("%s-1", testutils.ServiceAccountName), fmt.Sprintf("%s-2", testutils.ServiceAccountName)},
+					testutils.CountKey: 2,
+					testutils.ServiceAccountsKey: []types.ServiceAccount{
+						{Name: fmt.Sprintf("%s-1", testutils.ServiceAccountName), Role: testutils.AdminKey},
+						{Name: fmt.Sprintf("%s-2", testutils.ServiceAccountName)},
+					},
 				},
 			},
 		},
@@ -330,8 +398,11 @@ func TestGetServiceAccounts(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.CountKey:           2,
-					testutils.ServiceAccountsKey: []string{fmt.Sprintf("%s-1", testutils.ServiceAccountName), fmt.Sprintf("%s-2", testutils.ServiceAccountName)},
+					testutils.CountKey: 2,
+					testutils.ServiceAccountsKey: []types.ServiceAccount{
+						{Name: fmt.Sprintf("%s-1", testutils.ServiceAccountName), Role: testutils.AdminKey},
+						{Name: fmt.Sprintf("%s-2", testutils.ServiceAccountName)},
+					},
 				},
 			},
 		},
@@ -341,6 +412,7 @@ func TestGetServiceAccounts(t *testing.T) {
 	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
 	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, fmt.Sprintf("%s-1", testutils.ServiceAccountName), "")
 	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, fmt.Sprintf("%s-2", testutils.ServiceAccountName), "")
+	mocks.CreateTestServiceAccountRoleBinding(fakeClient, testNamespaceName, fmt.Sprintf("%s-1", testutils.ServiceAccountName), testutils.AdminKey)
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -402,7 +474,19 @@ func TestGetServiceAccount(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
-					testutils.NameKey: testutils.ServiceAccountName,
+					testutils.NameKey:   testutils.ServiceAccountName,
+					testutils.RoleKey:   testutils.ContributorKey,
+					testutils.LabelsKey: map[string]string{testutils.ManagedLabel: testutils.ManagedLabelValue},
+					"imagePullSecrets":  []string{testutils.DockerCfgSecretName},
+					"tokens": []map[string]interface{}{
+						{
+							"name":                testutils.NamedTokenName,
+							"description":         testutils.NamedTokenDescription,
+							"creator":             testutils.NamedTokenCreator,
+							"creationTimestamp":   testutils.NamedTokenCreatedAt,
+							"expirationTimestamp": testutils.NamedTokenExpiresAt,
+						},
+					},
 				},
 			},
 		},
@@ -429,7 +513,9 @@ func TestGetServiceAccount(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			params := url.Values{}
 			if test.args.existingServiceAccountName != "" {
-				mocks.CreateTestServiceAccount(fakeClient, test.args.namespace, test.args.existingServiceAccountName, "")
+				mocks.CreateTestServiceAccount(fakeClient, test.args.namespace, test.args.existingServiceAccountName, testutils.DockerCfgSecretName)
+				mocks.CreateTestServiceAccountRoleBinding(fakeClient, test.args.namespace, test.args.existingServiceAccountName, testutils.ContributorKey)
+				mocks.CreateTestNamedTokenSecret(fakeClient, test.args.existingServiceAccountName, test.args.namespace, testutils.NamedTokenName)
 			}
 			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s", test.args.namespace, test.args.serviceAccountName)
 			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
@@ -452,3 +538,175 @@ func TestGetServiceAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestSetServiceAccountRole(t *testing.T) {
+	testNamespaceName := serviceAccountNamespace + "-set-role"
+
+	type args struct {
+		serviceAccountName string
+		namespace          string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		args        args
+		requestData interface{}
+		want        want
+	}{
+		"ShouldSucceedSettingServiceAccountRole": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName,
+			},
+			requestData: types.ServiceAccountRole{Role: testutils.ContributorKey},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.RoleKey: testutils.ContributorKey,
+				},
+			},
+		},
+		"ShouldHandleNotExistentRole": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName,
+			},
+			requestData: types.ServiceAccountRole{Role: testutils.ViewerKey + testutils.NonExistentSuffix},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'ServiceAccountRole.Role' Error:Field validation for 'Role' failed on the 'platformrole' tag",
+					testutils.ReasonKey: testutils.ReasonBadRequest,
+				},
+			},
+		},
+		"ShouldHandleNotFoundServiceAccount": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: testutils.ServiceAccountName + testutils.NonExistentSuffix,
+			},
+			requestData: types.ServiceAccountRole{Role: testutils.ViewerKey},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%s, %s",
+						fmt.Sprintf(controllers.ErrCouldNotGetServiceAccount, testutils.ServiceAccountName+testutils.NonExistentSuffix, testNamespaceName),
+						fmt.Sprintf("serviceaccounts %q not found", testutils.ServiceAccountName+testutils.NonExistentSuffix),
+					),
+					testutils.ReasonKey: testutils.ReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
+	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, testutils.ServiceAccountName, "")
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := json.Marshal(test.requestData)
+			assert.NoError(t, err)
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s/role", test.args.namespace, test.args.serviceAccountName)
+			request, err := http.NewRequest(http.MethodPut, baseURI, bytes.NewBuffer(payload))
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
+func TestGetServiceAccountRole(t *testing.T) {
+	testNamespaceName := serviceAccountNamespace + "-get-role"
+
+	type args struct {
+		serviceAccountName string
+		namespace          string
+	}
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldSucceedGettingServiceAccountRole": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: fmt.Sprintf("%s-1", testutils.ServiceAccountName),
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.RoleKey: testutils.ContributorKey,
+				},
+			},
+		},
+		"ShouldHandleServiceAccountWithoutRole": {
+			args: args{
+				namespace:          testNamespaceName,
+				serviceAccountName: fmt.Sprintf("%s-2", testutils.ServiceAccountName),
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrServiceAccountRoleNotFound, fmt.Sprintf("%s-2", testutils.ServiceAccountName), testNamespaceName),
+					testutils.ReasonKey: testutils.ReasonNotFound,
+				},
+			},
+		},
+	}
+
+	setup()
+	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
+	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, fmt.Sprintf("%s-1", testutils.ServiceAccountName), "")
+	mocks.CreateTestServiceAccount(fakeClient, testNamespaceName, fmt.Sprintf("%s-2", testutils.ServiceAccountName), "")
+	mocks.CreateTestServiceAccountRoleBinding(fakeClient, testNamespaceName, fmt.Sprintf("%s-1", testutils.ServiceAccountName), testutils.ContributorKey)
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s/role", test.args.namespace, test.args.serviceAccountName)
+			request, err := http.NewRequest(http.MethodGet, baseURI, nil)
+			assert.NoError(t, err)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
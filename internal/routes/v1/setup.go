@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 
+	"github.com/dana-team/platform-backend/internal/controllers"
 	"github.com/dana-team/platform-backend/internal/routes/v1/doc"
 	"github.com/dana-team/platform-backend/internal/routes/v1/doc/operation"
 	"github.com/dana-team/platform-backend/internal/types"
@@ -11,12 +12,12 @@ import (
 	"github.com/dana-team/platform-backend/internal/middleware"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/gin-gonic/gin"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // SetupRoutes initializes the API routes for version 1.
-func SetupRoutes(engine *gin.Engine, tokenProvider auth.TokenProvider, scheme *runtime.Scheme) {
+func SetupRoutes(engine *gin.Engine, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, loginLimiter *auth.LoginLimiter, ticketStore *middleware.WSTicketStore, tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) {
 	engine.Use(middleware.ErrorHandlingMiddleware())
+	registerValidations(roleCatalog)
 	v1 := engine.Group("/v1")
 	ws := engine.Group("/ws")
 
@@ -27,31 +28,86 @@ func SetupRoutes(engine *gin.Engine, tokenProvider auth.TokenProvider, scheme *r
 	})
 	operation.AddHealthz(api, r)
 
-	setupWSRoutes(api, r, ws, tokenProvider, scheme)
-	setupAuthRoutes(api, r, v1, tokenProvider)
-	setupNamespaceRoutes(api, r, v1, tokenProvider, scheme)
-	setupClustersRoutes(api, r, v1, tokenProvider, scheme)
+	setupWSRoutes(api, r, ws, tokenProvider, clientCache, sessionManager, ticketStore)
+	setupAuthRoutes(api, r, v1, tokenProvider, clientCache, sessionManager, loginLimiter)
+	setupWSTicketRoutes(api, r, v1, tokenProvider, clientCache, sessionManager, ticketStore)
+	setupMeRoutes(api, r, v1, tokenProvider, clientCache, sessionManager, roleCatalog)
+	setupRoleRoutes(api, r, v1, tokenProvider, clientCache, sessionManager, roleCatalog)
+	setupNamespaceRoutes(api, r, v1, tokenProvider, clientCache, sessionManager, tokenPolicy, roleCatalog)
+	setupClustersRoutes(api, r, v1, tokenProvider, clientCache, sessionManager)
 }
 
 // setupAuthRoutes defines routes related to authentication.
-func setupAuthRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider) {
+func setupAuthRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, loginLimiter *auth.LoginLimiter) {
 	authGroup := v1.Group("/login")
 	{
-		authGroup.POST("", Login(tokenProvider))
+		authGroup.POST("", Login(tokenProvider, loginLimiter, sessionManager))
 		operation.AddLogin(api, r)
+
+		authGroup.POST("/refresh", LoginRefresh(tokenProvider, sessionManager))
+		operation.AddLoginRefresh(api, r)
+	}
+
+	logoutGroup := v1.Group("/logout")
+	if tokenProvider != nil {
+		logoutGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
+	}
+	{
+		logoutGroup.POST("", Logout(tokenProvider, clientCache, sessionManager))
+		operation.AddLogout(api, r)
 	}
 }
 
-// setupWSRoutes defines routes related to websockets.
-func setupWSRoutes(api huma.API, r huma.Registry, ws *gin.RouterGroup, tokenProvider auth.TokenProvider, scheme *runtime.Scheme) {
+// setupMeRoutes defines routes related to the logged in user.
+func setupMeRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, roleCatalog *controllers.RoleCatalog) {
+	meGroup := v1.Group("/me")
+	if tokenProvider != nil {
+		meGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
+	}
+
+	{
+		meGroup.Use(middleware.PaginationMiddleware())
+		meGroup.GET("", GetMe(roleCatalog))
+		operation.AddGetMe(api, r)
+	}
+}
+
+// setupRoleRoutes defines routes related to the platform roles which may be granted to members of namespaces.
+func setupRoleRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, roleCatalog *controllers.RoleCatalog) {
+	rolesGroup := v1.Group("/roles")
+	if tokenProvider != nil {
+		rolesGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
+	}
+
+	{
+		rolesGroup.GET("", GetRoles(roleCatalog))
+		operation.AddGetRoles(api, r)
+	}
+}
+
+// setupWSTicketRoutes defines routes related to the tickets which authenticate websockets.
+func setupWSTicketRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, ticketStore *middleware.WSTicketStore) {
+	ticketsGroup := v1.Group("/ws-tickets")
+	if tokenProvider != nil {
+		ticketsGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
+	}
+
+	{
+		ticketsGroup.POST("", CreateWSTicket(ticketStore))
+		operation.AddCreateWSTicket(api, r)
+	}
+}
+
+// setupWSRoutes defines routes related to websockets. Besides the token, they accept a ticket issued by the ticket store.
+func setupWSRoutes(api huma.API, r huma.Registry, ws *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, ticketStore *middleware.WSTicketStore) {
 	ws.GET("/terminal", ServeTerminal())
 	operation.AddServeTerminal(api, r)
 
 	namespacesGroup := ws.Group("/namespaces")
 	clustersGroup := ws.Group("/clusters/:clusterName")
 	if tokenProvider != nil {
-		namespacesGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, scheme))
-		clustersGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, scheme))
+		namespacesGroup.Use(middleware.WebSocketAuthMiddleware(tokenProvider, clientCache, sessionManager, ticketStore))
+		clustersGroup.Use(middleware.WebSocketAuthMiddleware(tokenProvider, clientCache, sessionManager, ticketStore))
 	}
 
 	logsGroup := namespacesGroup.Group("/:namespaceName")
@@ -78,11 +134,11 @@ func setupWSRoutes(api huma.API, r huma.Registry, ws *gin.RouterGroup, tokenProv
 }
 
 // setupNamespaceRoutes defines routes related to namespaces and their resources.
-func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, scheme *runtime.Scheme) {
+func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager, tokenPolicy controllers.TokenExpirationPolicy, roleCatalog *controllers.RoleCatalog) {
 	namespacesGroup := v1.Group("/namespaces")
 
 	if tokenProvider != nil {
-		namespacesGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, scheme))
+		namespacesGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
 	}
 
 	{
@@ -100,6 +156,9 @@ func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, to
 
 		namespacesGroup.DELETE("/:namespaceName", DeleteNamespace())
 		operation.AddDeleteNamespace(api, r)
+
+		namespacesGroup.POST("/:namespaceName/:namespaceAction", NamespaceAction())
+		operation.AddCheckPermissions(api, r)
 	}
 
 	secretsGroup := namespacesGroup.Group("/:namespaceName/secrets")
@@ -139,6 +198,9 @@ func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, to
 		cappGroup.PUT("/:cappName", UpdateCapp())
 		operation.AddUpdateCapp(api, r)
 
+		cappGroup.PATCH("/:cappName", PatchCapp())
+		operation.AddPatchCapp(api, r)
+
 		cappGroup.PUT("/:cappName/state", EditCappState())
 		operation.AddEditCappState(api, r)
 
@@ -148,6 +210,14 @@ func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, to
 		cappGroup.DELETE("/:cappName", DeleteCapp())
 		operation.AddDeleteCapp(api, r)
 
+		// The Capp is rolled back in the hub, so only the CappRevision is read from the managed cluster of the Capp.
+		cappGroup.POST("/:cappName/capprevisions/:cappRevisionName/rollback", RollbackCapp())
+		operation.AddRollbackCapp(api, r)
+
+		// The live Capp is read from the hub, so only the CappRevisions are read from the managed cluster of the Capp.
+		cappGroup.GET("/:cappName/capprevisions/:cappRevisionName/diff", GetCappRevisionDiff())
+		operation.AddGetCappRevisionDiff(api, r)
+
 		getDns := cappGroup.Group("")
 		getDns.Use(middleware.ClusterMiddleware())
 		getDns.GET("/:cappName/dns", GetCappDNS())
@@ -172,19 +242,19 @@ func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, to
 		getUsers := usersGroup.Group("")
 		getUsers.Use(middleware.PaginationMiddleware())
 
-		getUsers.GET("", GetUsers())
+		getUsers.GET("", GetUsers(roleCatalog))
 		operation.AddGetUsers(api, r)
 
-		usersGroup.POST("", CreateUser())
+		usersGroup.POST("", CreateUser(roleCatalog))
 		operation.AddCreateUser(api, r)
 
-		usersGroup.GET("/:userName", GetUser())
+		usersGroup.GET("/:userName", GetUser(roleCatalog))
 		operation.AddGetUser(api, r)
 
-		usersGroup.PUT("/:userName", UpdateUser())
+		usersGroup.PUT("/:userName", UpdateUser(roleCatalog))
 		operation.AddUpdateUser(api, r)
 
-		usersGroup.DELETE("/:userName", DeleteUser())
+		usersGroup.DELETE("/:userName", DeleteUser(roleCatalog))
 		operation.AddDeleteUser(api, r)
 	}
 
@@ -211,35 +281,53 @@ func setupNamespaceRoutes(api huma.API, r huma.Registry, v1 *gin.RouterGroup, to
 	{
 		serviceAccountsGroup.Use(middleware.PaginationMiddleware())
 
-		serviceAccountsGroup.GET("/:serviceAccountName", GetServiceAccount())
+		serviceAccountsGroup.GET("/:serviceAccountName", GetServiceAccount(tokenPolicy, roleCatalog))
 		operation.AddGetServiceAccount(api, r)
 
-		serviceAccountsGroup.GET("/:serviceAccountName/token", GetToken())
+		serviceAccountsGroup.GET("/:serviceAccountName/token", GetToken(tokenPolicy, roleCatalog))
 		operation.AddGetToken(api, r)
 
-		serviceAccountsGroup.GET("", GetServiceAccounts())
+		serviceAccountsGroup.GET("", GetServiceAccounts(tokenPolicy, roleCatalog))
 		operation.AddGetServiceAccounts(api, r)
 
-		serviceAccountsGroup.POST("/:serviceAccountName", CreateServiceAccount())
+		serviceAccountsGroup.POST("/:serviceAccountName", CreateServiceAccount(tokenPolicy, roleCatalog))
 		operation.AddCreateServiceAccount(api, r)
 
-		serviceAccountsGroup.DELETE("/:serviceAccountName", DeleteServiceAccount())
+		serviceAccountsGroup.DELETE("/:serviceAccountName", DeleteServiceAccount(tokenPolicy, roleCatalog))
 		operation.AddDeleteServiceAccount(api, r)
 
-		serviceAccountsGroup.POST("/:serviceAccountName/token", CreateToken())
+		serviceAccountsGroup.POST("/:serviceAccountName/token", CreateToken(tokenPolicy))
 		operation.AddCreateToken(api, r)
 
-		serviceAccountsGroup.DELETE("/:serviceAccountName/token", RevokeToken())
+		serviceAccountsGroup.DELETE("/:serviceAccountName/token", RevokeToken(tokenPolicy))
 		operation.AddRevokeToken(api, r)
+
+		serviceAccountsGroup.GET("/:serviceAccountName/tokens", GetTokens(tokenPolicy))
+		operation.AddGetTokens(api, r)
+
+		serviceAccountsGroup.DELETE("/:serviceAccountName/tokens/:tokenName", RevokeNamedToken(tokenPolicy))
+		operation.AddRevokeNamedToken(api, r)
+
+		serviceAccountsGroup.GET("/:serviceAccountName/kubeconfig", GetKubeconfig(tokenPolicy))
+		operation.AddGetKubeconfig(api, r)
+
+		serviceAccountsGroup.GET("/:serviceAccountName/role", GetServiceAccountRole(tokenPolicy, roleCatalog))
+		operation.AddGetServiceAccountRole(api, r)
+
+		serviceAccountsGroup.PUT("/:serviceAccountName/role", SetServiceAccountRole(tokenPolicy, roleCatalog))
+		operation.AddSetServiceAccountRole(api, r)
+
+		serviceAccountsGroup.DELETE("/:serviceAccountName/role", DeleteServiceAccountRole(tokenPolicy, roleCatalog))
+		operation.AddDeleteServiceAccountRole(api, r)
 	}
 }
 
 // setupClustersRoutes defines routes related to clusters and their namespaces.
-func setupClustersRoutes(api huma.API, registry huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, scheme *runtime.Scheme) {
+func setupClustersRoutes(api huma.API, registry huma.Registry, v1 *gin.RouterGroup, tokenProvider auth.TokenProvider, clientCache *middleware.ClientCache, sessionManager *middleware.SessionManager) {
 	clustersGroup := v1.Group("/clusters/:clusterName")
 
 	if tokenProvider != nil {
-		clustersGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, scheme))
+		clustersGroup.Use(middleware.TokenAuthMiddleware(tokenProvider, clientCache, sessionManager))
 	}
 
 	clustersGroup.Use(middleware.ClusterMiddleware())
@@ -3,12 +3,17 @@ package v1
 import (
 	"os"
 	"testing"
+	"time"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/platform-backend/internal/auth"
+	"github.com/dana-team/platform-backend/internal/controllers"
 	"github.com/dana-team/platform-backend/internal/middleware"
 	"github.com/dana-team/platform-backend/internal/routes/v1/doc"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	dnsrecordv1alpha1 "github.com/dana-team/provider-dns/apis/record/v1alpha1"
 	"github.com/gin-gonic/gin"
+	userv1 "github.com/openshift/api/user/v1"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -25,10 +30,15 @@ const (
 )
 
 var (
-	router     *gin.Engine
-	fakeClient *fake.Clientset
-	dynClient  runtimeClient.WithWatch
-	token      string
+	identity = auth.Identity{Username: testutils.TestName + "-user", Groups: []string{testutils.GroupName}}
+)
+
+var (
+	router      *gin.Engine
+	fakeClient  *fake.Clientset
+	dynClient   runtimeClient.WithWatch
+	token       string
+	ticketStore *middleware.WSTicketStore
 )
 
 func TestMain(m *testing.M) {
@@ -38,6 +48,7 @@ func TestMain(m *testing.M) {
 func setup() {
 	fakeClient = fake.NewClientset()
 	dynClient = runtimeFake.NewClientBuilder().WithScheme(setupScheme()).Build()
+	ticketStore, _ = middleware.NewWSTicketStore(time.Minute)
 	logger, _ := zap.NewProduction()
 	router = setupRouter(logger)
 }
@@ -45,6 +56,7 @@ func setup() {
 func setupRouter(logger *zap.Logger) *gin.Engine {
 	engine := gin.Default()
 	engine.Use(middleware.ErrorHandlingMiddleware())
+	registerValidations(controllers.DefaultRoleCatalog())
 
 	engine.Use(func(c *gin.Context) {
 		c.Set(middleware.LoggerCtxKey, logger)
@@ -52,6 +64,7 @@ func setupRouter(logger *zap.Logger) *gin.Engine {
 		c.Set(middleware.DynamicClientCtxKey, dynClient)
 		c.Set(middleware.TokenCtxKey, token)
 		c.Set(middleware.ClusterCtxKey, cluster)
+		c.Set(middleware.IdentityCtxKey, identity)
 		c.Next()
 	})
 
@@ -59,9 +72,12 @@ func setupRouter(logger *zap.Logger) *gin.Engine {
 	ws := engine.Group("/ws")
 	api, r := doc.SetupAPIRegistry(engine)
 
-	setupNamespaceRoutes(api, r, v1, nil, nil)
-	setupClustersRoutes(api, r, v1, nil, nil)
-	setupWSRoutes(api, r, ws, nil, nil)
+	setupMeRoutes(api, r, v1, nil, nil, nil, controllers.DefaultRoleCatalog())
+	setupRoleRoutes(api, r, v1, nil, nil, nil, controllers.DefaultRoleCatalog())
+	setupNamespaceRoutes(api, r, v1, nil, nil, nil, controllers.DefaultTokenExpirationPolicy(), controllers.DefaultRoleCatalog())
+	setupClustersRoutes(api, r, v1, nil, nil, nil)
+	setupWSRoutes(api, r, ws, nil, nil, nil, ticketStore)
+	setupWSTicketRoutes(api, r, v1, nil, nil, nil, ticketStore)
 
 	return engine
 }
@@ -71,5 +87,6 @@ func setupScheme() *runtime.Scheme {
 	utilruntime.Must(cappv1alpha1.AddToScheme(schema))
 	utilruntime.Must(dnsrecordv1alpha1.AddToScheme(schema))
 	utilruntime.Must(clusterv1beta1.AddToScheme(schema))
+	utilruntime.Must(userv1.AddToScheme(schema))
 	return schema
 }
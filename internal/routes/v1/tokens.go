@@ -12,8 +12,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	contentDispositionHeader = "Content-Disposition"
+	kubeconfigContentType    = "application/yaml"
+)
+
 // tokenHandler wraps a handler function with context setup for tokenController.
-func tokenHandler(handler func(controller controllers.TokenController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+func tokenHandler(tokenPolicy controllers.TokenExpirationPolicy, handler func(controller controllers.TokenController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		kubeClient, err := middleware.GetKubeClient(c)
 		if middleware.AddErrorToContext(c, err) {
@@ -26,7 +31,7 @@ func tokenHandler(handler func(controller controllers.TokenController, c *gin.Co
 		}
 
 		context := c.Request.Context()
-		tokenController := controllers.NewTokenController(kubeClient, context, logger)
+		tokenController := controllers.NewTokenController(kubeClient, context, logger, tokenPolicy)
 
 		result, err := handler(tokenController, c)
 		if middleware.AddErrorToContext(c, err) {
@@ -37,8 +42,8 @@ func tokenHandler(handler func(controller controllers.TokenController, c *gin.Co
 	}
 }
 
-// CreateToken returns a Gin handler function for creating a service account token.
-func CreateToken() gin.HandlerFunc {
+// CreateToken returns a Gin handler function for creating a named service account token.
+func CreateToken(tokenPolicy controllers.TokenExpirationPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -52,14 +57,19 @@ func CreateToken() gin.HandlerFunc {
 			return
 		}
 
-		tokenHandler(func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
-			return controller.CreateToken(request.ServiceAccountName, request.NamespaceName, query.ExpirationSeconds)
+		identity, err := middleware.GetIdentity(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		tokenHandler(tokenPolicy, func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
+			return controller.CreateToken(request.ServiceAccountName, request.NamespaceName, identity.Username, query)
 		})(c)
 	}
 }
 
 // RevokeToken returns a Gin handler function for revoking the tokens for a service account.
-func RevokeToken() gin.HandlerFunc {
+func RevokeToken(tokenPolicy controllers.TokenExpirationPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request types.ServiceAccountRequestUri
 		if err := c.BindUri(&request); err != nil {
@@ -67,10 +77,82 @@ func RevokeToken() gin.HandlerFunc {
 			return
 		}
 
-		tokenHandler(func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
+		tokenHandler(tokenPolicy, func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
 			name := request.ServiceAccountName
 			message := fmt.Sprintf("Revoked tokens for ServiceAccount %q", name)
 			return types.MessageResponse{Message: message}, controller.RevokeToken(request.ServiceAccountName, request.NamespaceName)
 		})(c)
 	}
 }
+
+// GetTokens returns a Gin handler function for listing the named tokens of a service account.
+func GetTokens(tokenPolicy controllers.TokenExpirationPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.ServiceAccountRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		tokenHandler(tokenPolicy, func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
+			return controller.GetTokens(request.ServiceAccountName, request.NamespaceName)
+		})(c)
+	}
+}
+
+// RevokeNamedToken returns a Gin handler function for revoking a single named token of a service account.
+func RevokeNamedToken(tokenPolicy controllers.TokenExpirationPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.TokenRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		tokenHandler(tokenPolicy, func(controller controllers.TokenController, c *gin.Context) (interface{}, error) {
+			message := fmt.Sprintf("Revoked token %q for ServiceAccount %q", request.TokenName, request.ServiceAccountName)
+			return types.MessageResponse{Message: message}, controller.RevokeNamedToken(request.ServiceAccountName, request.NamespaceName, request.TokenName)
+		})(c)
+	}
+}
+
+// GetKubeconfig returns a Gin handler function for downloading a kubeconfig authenticated by a new named service account token.
+func GetKubeconfig(tokenPolicy controllers.TokenExpirationPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request types.ServiceAccountRequestUri
+		if err := c.BindUri(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		var query types.KubeconfigQuery
+		if err := c.BindQuery(&query); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		identity, err := middleware.GetIdentity(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		kubeClient, err := middleware.GetKubeClient(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		logger, err := middleware.GetLogger(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		tokenController := controllers.NewTokenController(kubeClient, c.Request.Context(), logger, tokenPolicy)
+		kubeconfig, err := tokenController.GetKubeconfig(request.ServiceAccountName, request.NamespaceName, identity.Username, query)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		c.Header(contentDispositionHeader, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-kubeconfig.yaml", request.ServiceAccountName)))
+		c.Data(http.StatusOK, kubeconfigContentType, kubeconfig)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils/mocks"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestCreateToken(t *testing.T) {
@@ -40,8 +42,10 @@ func TestCreateToken(t *testing.T) {
 			want: want{
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
+					"name":                testutils.NamedTokenName,
 					"token":               "",
 					"expirationTimestamp": time.Time{},
+					"expirationSeconds":   36000,
 				},
 			},
 		},
@@ -69,6 +73,7 @@ func TestCreateToken(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			params := url.Values{}
+			params.Add("name", testutils.NamedTokenName)
 
 			if tc.args.createServiceAccount {
 				mocks.CreateTestServiceAccount(fakeClient, tc.args.namespaceName, tc.args.serviceAccountName, "")
@@ -197,3 +202,232 @@ func TestRevokeToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTokens(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-get-tokens"
+	type args struct {
+		serviceAccountName string
+	}
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+	tests := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldGetTokens": {
+			args: args{
+				serviceAccountName: testutils.ServiceAccountName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					"tokens": []map[string]interface{}{
+						{
+							"name":                testutils.NamedTokenName,
+							"description":         testutils.NamedTokenDescription,
+							"creator":             testutils.NamedTokenCreator,
+							"creationTimestamp":   testutils.NamedTokenCreatedAt,
+							"expirationTimestamp": testutils.NamedTokenExpiresAt,
+						},
+					},
+					"count": 1,
+				},
+			},
+		},
+		"ShouldNotGetTokensWhenServiceAccountDoesNotExist": {
+			args: args{
+				serviceAccountName: testutils.ServiceAccountName + testutils.NonExistentSuffix,
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%s, %s",
+						fmt.Sprintf(controllers.ErrCouldNotGetServiceAccount, testutils.ServiceAccountName+testutils.NonExistentSuffix, namespaceName),
+						fmt.Sprintf("serviceaccounts %q not found", testutils.ServiceAccountName+testutils.NonExistentSuffix),
+					),
+					testutils.ReasonKey: testutils.ReasonNotFound,
+				},
+			},
+		},
+	}
+	setup()
+	mocks.CreateTestNamespace(fakeClient, namespaceName)
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName, "")
+	mocks.CreateTestNamedTokenSecret(fakeClient, testutils.ServiceAccountName, namespaceName, testutils.NamedTokenName)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s/tokens", namespaceName, tc.args.serviceAccountName)
+			request, err := http.NewRequest(http.MethodGet, baseURI, nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(tc.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
+func TestRevokeNamedToken(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-revoke-named-token"
+	type args struct {
+		tokenName string
+	}
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+	tests := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldRevokeNamedToken": {
+			args: args{
+				tokenName: testutils.NamedTokenName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					"message": fmt.Sprintf("Revoked token %q for ServiceAccount %q", testutils.NamedTokenName, testutils.ServiceAccountName),
+				},
+			},
+		},
+		"ShouldNotRevokeNamedTokenWhenTokenDoesNotExist": {
+			args: args{
+				tokenName: testutils.NamedTokenName + testutils.NonExistentSuffix,
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%s, %s",
+						fmt.Sprintf(controllers.ErrCouldNotGetToken, testutils.NamedTokenName+testutils.NonExistentSuffix, testutils.ServiceAccountName, namespaceName),
+						fmt.Sprintf("secrets %q not found", fmt.Sprintf("%s-%s-%s", testutils.ServiceAccountName, testutils.NamedTokenInfix, testutils.NamedTokenName+testutils.NonExistentSuffix)),
+					),
+					testutils.ReasonKey: testutils.ReasonNotFound,
+				},
+			},
+		},
+	}
+	setup()
+	mocks.CreateTestNamespace(fakeClient, namespaceName)
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName, "")
+	mocks.CreateTestNamedTokenSecret(fakeClient, testutils.ServiceAccountName, namespaceName, testutils.NamedTokenName)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s/tokens/%s", namespaceName, testutils.ServiceAccountName, tc.args.tokenName)
+			request, err := http.NewRequest(http.MethodDelete, baseURI, nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.want.statusCode, writer.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(tc.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
+
+func TestGetKubeconfig(t *testing.T) {
+	namespaceName := testutils.TestNamespace + "-kubeconfig"
+	type args struct {
+		serviceAccountName string
+		namespaceName      string
+	}
+	type want struct {
+		statusCode int
+		context    string
+		response   map[string]interface{}
+	}
+	tests := map[string]struct {
+		args args
+		want want
+	}{
+		"ShouldGetKubeconfig": {
+			args: args{
+				serviceAccountName: testutils.ServiceAccountName,
+				namespaceName:      namespaceName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				context:    namespaceName + "/" + testutils.ServiceAccountName,
+			},
+		},
+		"ShouldNotGetKubeconfigWhenServiceAccountDoesNotExist": {
+			args: args{
+				serviceAccountName: testutils.ServiceAccountName + testutils.NonExistentSuffix,
+				namespaceName:      namespaceName,
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response: map[string]interface{}{
+					testutils.ErrorKey: fmt.Sprintf("%s, %s",
+						fmt.Sprintf(controllers.ErrCouldNotGetServiceAccount, testutils.ServiceAccountName+testutils.NonExistentSuffix, namespaceName),
+						fmt.Sprintf("serviceaccounts %q not found", testutils.ServiceAccountName+testutils.NonExistentSuffix),
+					),
+					testutils.ReasonKey: testutils.ReasonNotFound,
+				},
+			},
+		},
+	}
+	setup()
+	mocks.CreateTestNamespace(fakeClient, namespaceName)
+	mocks.CreateTestServiceAccount(fakeClient, namespaceName, testutils.ServiceAccountName, "")
+	_ = os.Setenv("KUBE_API_SERVER", testutils.KubeAPIServer)
+	_ = os.Setenv("INSECURE_SKIP_VERIFY", "true")
+	defer func() {
+		_ = os.Unsetenv("KUBE_API_SERVER")
+		_ = os.Unsetenv("INSECURE_SKIP_VERIFY")
+	}()
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := url.Values{}
+			params.Add("clusters", testutils.ManagedClusterName)
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/serviceaccounts/%s/kubeconfig", tc.args.namespaceName, tc.args.serviceAccountName)
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
+			assert.NoError(t, err)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.want.statusCode, writer.Code)
+			if tc.want.statusCode != http.StatusOK {
+				var response map[string]interface{}
+				err = json.Unmarshal(writer.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.response, response)
+				return
+			}
+
+			assert.Equal(t, "application/yaml", writer.Header().Get("Content-Type"))
+			kubeconfig, err := clientcmd.Load(writer.Body.Bytes())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.context, kubeconfig.CurrentContext)
+			assert.Equal(t, testutils.KubeAPIServer, kubeconfig.Clusters[kubeconfig.Contexts[tc.want.context].Cluster].Server)
+			assert.Len(t, kubeconfig.Contexts, 2)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"github.com/dana-team/platform-backend/internal/customerrors"
 	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/routes"
 
 	"github.com/dana-team/platform-backend/internal/controllers"
 	"github.com/dana-team/platform-backend/internal/types"
@@ -12,20 +13,25 @@ import (
 )
 
 // usersHandler handles the request of the client to the Kubernetes cluster.
-func usersHandler(handler func(controller controllers.UserController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+func usersHandler(roleCatalog *controllers.RoleCatalog, handler func(controller controllers.UserController, c *gin.Context) (interface{}, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		kubeClient, err := middleware.GetKubeClient(c)
 		if middleware.AddErrorToContext(c, err) {
 			return
 		}
 
+		dynClient, err := middleware.GetDynClient(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
 		logger, err := middleware.GetLogger(c)
 		if middleware.AddErrorToContext(c, err) {
 			return
 		}
 
 		context := c.Request.Context()
-		userController := controllers.NewUserController(kubeClient, context, logger)
+		userController := controllers.NewUserController(kubeClient, dynClient, context, logger, roleCatalog)
 
 		result, err := handler(userController, c)
 		if middleware.AddErrorToContext(c, err) {
@@ -37,7 +43,7 @@ func usersHandler(handler func(controller controllers.UserController, c *gin.Con
 }
 
 // CreateUser creates a specific user in a specific namespace.
-func CreateUser() gin.HandlerFunc {
+func CreateUser(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var namespace types.NamespaceUri
 		if err := c.BindUri(&namespace); err != nil {
@@ -50,36 +56,48 @@ func CreateUser() gin.HandlerFunc {
 			return
 		}
 
-		usersHandler(func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
+		usersHandler(roleCatalog, func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
 			return controller.AddUser(types.UserInput{Namespace: namespace.NamespaceName,
-				User: types.User{Name: user.Name, Role: user.Role}})
+				User: types.User{Name: user.Name, Type: user.Type, Role: user.Role, ExpiresAt: user.ExpiresAt}})
 		})(c)
 	}
 }
 
 // UpdateUser updates a specific user in a specific namespace.
-func UpdateUser() gin.HandlerFunc {
+func UpdateUser(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var userIdentifier types.UserIdentifier
 		if err := c.BindUri(&userIdentifier); err != nil {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		var query types.UserQuery
+		if err := c.BindQuery(&query); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
 		var userRole types.UpdateUserData
 		if err := c.BindJSON(&userRole); err != nil {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		resourceVersion, err := routes.GetIfMatch(c)
+		if err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
 
-		usersHandler(func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
-			return controller.UpdateUser(types.UserInput{Namespace: userIdentifier.NamespaceName,
-				User: types.User{Name: userIdentifier.UserName, Role: userRole.Role}})
+		usersHandler(roleCatalog, func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
+			user, err := controller.UpdateUser(types.UserInput{Namespace: userIdentifier.NamespaceName,
+				User: types.User{Name: userIdentifier.UserName, Type: query.Type, Role: userRole.Role, ExpiresAt: userRole.ExpiresAt}}, resourceVersion)
+			routes.SetETag(c, user.ResourceVersion)
+			return user, err
 		})(c)
 	}
 }
 
 // GetUsers fetches all users from namespace.
-func GetUsers() gin.HandlerFunc {
+func GetUsers(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var namespace types.NamespaceUri
 		if err := c.BindUri(&namespace); err != nil {
@@ -93,37 +111,51 @@ func GetUsers() gin.HandlerFunc {
 			return
 		}
 
-		usersHandler(func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
+		usersHandler(roleCatalog, func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
 			return controller.GetUsers(namespace.NamespaceName, limit, page)
 		})(c)
 	}
 }
 
 // GetUser fetches a specific user from namespace.
-func GetUser() gin.HandlerFunc {
+func GetUser(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var userIdentifier types.UserIdentifier
 		if err := c.BindUri(&userIdentifier); err != nil {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		var query types.UserQuery
+		if err := c.BindQuery(&query); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+		userIdentifier.Type = query.Type
 
-		usersHandler(func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
-			return controller.GetUser(userIdentifier)
+		usersHandler(roleCatalog, func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
+			user, err := controller.GetUser(userIdentifier)
+			routes.SetETag(c, user.ResourceVersion)
+			return user, err
 		})(c)
 	}
 }
 
 // DeleteUser deletes a specific user from namespace.
-func DeleteUser() gin.HandlerFunc {
+func DeleteUser(roleCatalog *controllers.RoleCatalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var userIdentifier types.UserIdentifier
 		if err := c.BindUri(&userIdentifier); err != nil {
 			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
 			return
 		}
+		var query types.UserQuery
+		if err := c.BindQuery(&query); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+		userIdentifier.Type = query.Type
 
-		usersHandler(func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
+		usersHandler(roleCatalog, func(controller controllers.UserController, c *gin.Context) (interface{}, error) {
 			return controller.DeleteUser(userIdentifier)
 		})(c)
 	}
@@ -52,8 +52,8 @@ func TestGetUsers(t *testing.T) {
 				response: map[string]interface{}{
 					testutils.CountKey: 2,
 					testutils.UsersKey: []types.User{
-						{Name: userName + "-1", Role: testutils.AdminKey},
-						{Name: userName + "-2", Role: testutils.AdminKey},
+						{Name: userName + "-1", Type: testutils.UserKind, Role: testutils.AdminKey},
+						{Name: userName + "-2", Type: testutils.UserKind, Role: testutils.AdminKey},
 					},
 				},
 			},
@@ -68,8 +68,8 @@ func TestGetUsers(t *testing.T) {
 				response: map[string]interface{}{
 					testutils.CountKey: 2,
 					testutils.UsersKey: []types.User{
-						{Name: userName + "-1", Role: testutils.AdminKey},
-						{Name: userName + "-2", Role: testutils.AdminKey},
+						{Name: userName + "-1", Type: testutils.UserKind, Role: testutils.AdminKey},
+						{Name: userName + "-2", Type: testutils.UserKind, Role: testutils.AdminKey},
 					},
 				},
 			},
@@ -80,6 +80,7 @@ func TestGetUsers(t *testing.T) {
 	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
 	mocks.CreateTestRoleBinding(fakeClient, userName+"-1", testNamespaceName, testutils.AdminKey)
 	mocks.CreateTestRoleBinding(fakeClient, userName+"-2", testNamespaceName, testutils.AdminKey)
+	mocks.CreateTestServiceAccountRoleBinding(fakeClient, testNamespaceName, testutils.ServiceAccountName, testutils.AdminKey)
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -120,6 +121,7 @@ func TestGetUser(t *testing.T) {
 	type requestURI struct {
 		namespace string
 		username  string
+		userType  string
 	}
 
 	type want struct {
@@ -140,10 +142,56 @@ func TestGetUser(t *testing.T) {
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
 					testutils.NameKey: userName,
+					testutils.TypeKey: testutils.UserKind,
 					testutils.RoleKey: testutils.AdminKey,
 				},
 			},
 		},
+		"ShouldSucceedGettingGroupWithMembers": {
+			requestURI: requestURI{
+				username:  testutils.GroupName,
+				userType:  testutils.GroupKind,
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.NameKey:    testutils.GroupName,
+					testutils.TypeKey:    testutils.GroupKind,
+					testutils.RoleKey:    testutils.ContributorKey,
+					testutils.MembersKey: []string{testutils.GroupMemberName},
+				},
+			},
+		},
+		"ShouldSucceedGettingGroupWithoutOpenShiftGroup": {
+			requestURI: requestURI{
+				username:  testutils.LDAPGroupName,
+				userType:  testutils.GroupKind,
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.NameKey: testutils.LDAPGroupName,
+					testutils.TypeKey: testutils.GroupKind,
+					testutils.RoleKey: testutils.ContributorKey,
+				},
+			},
+		},
+		"ShouldHandleInvalidType": {
+			requestURI: requestURI{
+				username:  userName,
+				userType:  testutils.UserKind + testutils.NonExistentSuffix,
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'UserQuery.Type' Error:Field validation for 'Type' failed on the 'oneof' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
 		"ShouldHandleNotFoundUser": {
 			requestURI: requestURI{
 				username:  userName + testutils.NonExistentSuffix,
@@ -180,11 +228,19 @@ func TestGetUser(t *testing.T) {
 	setup()
 	mocks.CreateTestNamespace(fakeClient, testNamespaceName)
 	mocks.CreateTestRoleBinding(fakeClient, userName, testNamespaceName, testutils.AdminKey)
+	mocks.CreateTestGroupRoleBinding(fakeClient, testutils.GroupName, testNamespaceName, testutils.GroupName, testutils.ContributorKey)
+	mocks.CreateTestGroupRoleBinding(fakeClient, testutils.GroupName+"-ldap", testNamespaceName, testutils.LDAPGroupName, testutils.ContributorKey)
+	mocks.CreateTestGroup(dynClient, testutils.GroupName, []string{testutils.GroupMemberName})
 
 	for name, test := range cases {
 		t.Run(name, func(t *testing.T) {
-			baseURI := fmt.Sprintf("/v1/namespaces/%s/users/%s", test.requestURI.namespace, test.requestURI.username)
-			request, err := http.NewRequest(http.MethodGet, baseURI, nil)
+			params := url.Values{}
+			if test.requestURI.userType != "" {
+				params.Add(testutils.TypeKey, test.requestURI.userType)
+			}
+
+			baseURI := fmt.Sprintf("/v1/namespaces/%s/users/%s", test.requestURI.namespace, url.PathEscape(test.requestURI.username))
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", baseURI, params.Encode()), nil)
 			assert.NoError(t, err)
 
 			writer := httptest.NewRecorder()
@@ -230,10 +286,74 @@ func TestCreateUser(t *testing.T) {
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
 					testutils.NameKey: userName,
-					testutils.RoleKey: testutils.ViewerKey,
+					testutils.TypeKey: testutils.UserKind,
+					testutils.RoleKey: testutils.ContributorKey,
+				},
+			},
+			requestData: mocks.PrepareUserType(userName, testutils.ContributorKey),
+		},
+		"ShouldSucceedCreateGroup": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.NameKey: testutils.LDAPGroupName,
+					testutils.TypeKey: testutils.GroupKind,
+					testutils.RoleKey: testutils.AdminKey,
+				},
+			},
+			requestData: types.User{Name: testutils.LDAPGroupName, Type: testutils.GroupKind, Role: testutils.AdminKey},
+		},
+		"ShouldHandleNonExistentType": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'User.Type' Error:Field validation for 'Type' failed on the 'oneof' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
 				},
 			},
-			requestData: mocks.PrepareUserType(userName, testutils.ViewerKey),
+			requestData: types.User{Name: userName, Type: testutils.GroupKind + testutils.NonExistentSuffix, Role: testutils.AdminKey},
+		},
+		"ShouldSucceedCreateUserWithExpiration": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: map[string]interface{}{
+					testutils.NameKey:      userName + "-expiring",
+					testutils.TypeKey:      testutils.UserKind,
+					testutils.RoleKey:      testutils.ContributorKey,
+					testutils.ExpiresAtKey: testutils.UserExpiresAt,
+				},
+			},
+			requestData: map[string]interface{}{
+				testutils.NameKey:      userName + "-expiring",
+				testutils.RoleKey:      testutils.ContributorKey,
+				testutils.ExpiresAtKey: testutils.UserExpiresAt,
+			},
+		},
+		"ShouldHandleExpirationInThePast": {
+			requestURI: requestURI{
+				namespace: testNamespaceName,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  fmt.Sprintf(controllers.ErrExpirationNotInFuture, testutils.UserExpiredAt, userName+"-expired"),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+			requestData: map[string]interface{}{
+				testutils.NameKey:      userName + "-expired",
+				testutils.RoleKey:      testutils.ViewerKey,
+				testutils.ExpiresAtKey: testutils.UserExpiredAt,
+			},
 		},
 		"ShouldHandleAlreadyExists": {
 			requestURI: requestURI{
@@ -255,7 +375,7 @@ func TestCreateUser(t *testing.T) {
 			want: want{
 				statusCode: http.StatusBadRequest,
 				response: map[string]interface{}{
-					testutils.ErrorKey:  "Key: 'User.Role' Error:Field validation for 'Role' failed on the 'oneof' tag",
+					testutils.ErrorKey:  "Key: 'User.Role' Error:Field validation for 'Role' failed on the 'platformrole' tag",
 					testutils.ReasonKey: metav1.StatusReasonBadRequest,
 				},
 			},
@@ -286,6 +406,12 @@ func TestCreateUser(t *testing.T) {
 			err = json.Unmarshal(writer.Body.Bytes(), &response)
 			assert.NoError(t, err)
 
+			// The time left until the access expires depends on when the request is handled.
+			if expiresIn, ok := response[testutils.ExpiresInSecondsKey]; ok {
+				assert.Greater(t, expiresIn, float64(0))
+				delete(response, testutils.ExpiresInSecondsKey)
+			}
+
 			wantResponseJSON, err := json.Marshal(test.want.response)
 			assert.NoError(t, err)
 			var wantResponseNormalized map[string]interface{}
@@ -323,10 +449,11 @@ func TestUpdateUser(t *testing.T) {
 				statusCode: http.StatusOK,
 				response: map[string]interface{}{
 					testutils.NameKey: userName,
-					testutils.RoleKey: testutils.ViewerKey,
+					testutils.TypeKey: testutils.UserKind,
+					testutils.RoleKey: testutils.ContributorKey,
 				},
 			},
-			requestData: mocks.PrepareUserType(userName, testutils.ViewerKey),
+			requestData: mocks.PrepareUserType(userName, testutils.ContributorKey),
 		},
 		"ShouldHandleNotFoundUser": {
 			requestURI: requestURI{
@@ -337,7 +464,7 @@ func TestUpdateUser(t *testing.T) {
 				statusCode: http.StatusNotFound,
 				response: map[string]interface{}{
 					testutils.ErrorKey: fmt.Sprintf("%v, %v",
-						fmt.Sprintf(controllers.ErrCouldNotDeleteRolebinding, userName+testutils.NonExistentSuffix),
+						fmt.Sprintf(controllers.ErrCouldNotUpdateRolebinding, userName+testutils.NonExistentSuffix),
 						fmt.Sprintf("%s.%s %q not found", testutils.RoleBindingsKey, testutils.RoleBindingsGroupKey, userName+testutils.NonExistentSuffix)),
 					testutils.ReasonKey: metav1.StatusReasonNotFound,
 				},
@@ -352,7 +479,7 @@ func TestUpdateUser(t *testing.T) {
 			want: want{
 				statusCode: http.StatusBadRequest,
 				response: map[string]interface{}{
-					testutils.ErrorKey:  "Key: 'UpdateUserData.Role' Error:Field validation for 'Role' failed on the 'oneof' tag",
+					testutils.ErrorKey:  "Key: 'UpdateUserData.Role' Error:Field validation for 'Role' failed on the 'platformrole' tag",
 					testutils.ReasonKey: metav1.StatusReasonBadRequest,
 				},
 			},
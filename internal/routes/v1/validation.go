@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const platformRoleTag = "platformrole"
+
+// registerValidations registers the custom validation tags used by the request types with the validator of gin.
+// Platform roles are validated against the role catalog.
+func registerValidations(roleCatalog *controllers.RoleCatalog) {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	_ = validate.RegisterValidation(platformRoleTag, validatePlatformRole(roleCatalog))
+}
+
+// validatePlatformRole returns a validation which makes sure the field is a platform role of the role catalog.
+func validatePlatformRole(roleCatalog *controllers.RoleCatalog) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return roleCatalog.IsRole(fl.Field().String())
+	}
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dana-team/platform-backend/internal/customerrors"
+	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	errCouldNotIssueWSTicket = "Could not issue WebSocket ticket"
+)
+
+// CreateWSTicket issues a short-lived, single-use ticket which authenticates the caller
+// on the WebSocket route of the given path, through the ticket query parameter.
+func CreateWSTicket(ticketStore *middleware.WSTicketStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger, err := middleware.GetLogger(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		var request types.WSTicketInput
+		if err := c.BindJSON(&request); err != nil {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		}
+
+		token, err := middleware.GetToken(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		identity, err := middleware.GetIdentity(c)
+		if middleware.AddErrorToContext(c, err) {
+			return
+		}
+
+		ticket, expiresAt, err := ticketStore.Issue(token, identity.Username, request.Path)
+		if errors.Is(err, middleware.ErrInvalidWSPath) {
+			middleware.AddErrorToContext(c, customerrors.NewValidationError(err.Error()))
+			return
+		} else if err != nil {
+			logger.Error(fmt.Sprintf("%v with error: %v", errCouldNotIssueWSTicket, err))
+			middleware.AddErrorToContext(c, customerrors.NewInternalServerError(errCouldNotIssueWSTicket))
+			return
+		}
+
+		logger.Debug("Issued WebSocket ticket", zap.String("path", request.Path))
+		c.JSON(http.StatusOK, types.WSTicketOutput{
+			Ticket:              ticket,
+			Path:                request.Path,
+			ExpirationTimestamp: expiresAt,
+		})
+	}
+}
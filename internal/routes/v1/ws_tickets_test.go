@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dana-team/platform-backend/internal/middleware"
+	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateWSTicket(t *testing.T) {
+	logsPath := "/ws/namespaces/" + testutils.TestNamespace + "/pods/" + testutils.PodName + "/logs"
+
+	type want struct {
+		statusCode int
+		response   map[string]interface{}
+	}
+
+	cases := map[string]struct {
+		requestData interface{}
+		want        want
+	}{
+		"ShouldSucceedCreatingTicket": {
+			requestData: types.WSTicketInput{Path: logsPath},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+		"ShouldFailWithNonWebSocketPath": {
+			requestData: types.WSTicketInput{Path: "/v1/namespaces"},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  middleware.ErrInvalidWSPath.Error(),
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+		"ShouldFailWithoutPath": {
+			requestData: types.WSTicketInput{},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response: map[string]interface{}{
+					testutils.ErrorKey:  "Key: 'WSTicketInput.Path' Error:Field validation for 'Path' failed on the 'required' tag",
+					testutils.ReasonKey: metav1.StatusReasonBadRequest,
+				},
+			},
+		},
+	}
+
+	setup()
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := json.Marshal(test.requestData)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/ws-tickets", bytes.NewBuffer(payload))
+			assert.NoError(t, err)
+			request.Header.Set(testutils.ContentType, testutils.ApplicationJson)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.want.statusCode, writer.Code)
+			if test.want.statusCode == http.StatusOK {
+				var response types.WSTicketOutput
+				assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+				assert.Equal(t, logsPath, response.Path)
+
+				_, username, err := ticketStore.Redeem(response.Ticket, logsPath)
+				assert.NoError(t, err)
+				assert.Equal(t, identity.Username, username)
+				return
+			}
+
+			var response map[string]interface{}
+			err = json.Unmarshal(writer.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			wantResponseJSON, err := json.Marshal(test.want.response)
+			assert.NoError(t, err)
+			var wantResponseNormalized map[string]interface{}
+			err = json.Unmarshal(wantResponseJSON, &wantResponseNormalized)
+			assert.NoError(t, err)
+			assert.Equal(t, wantResponseNormalized, response)
+		})
+	}
+}
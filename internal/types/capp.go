@@ -30,6 +30,14 @@ type UpdateCapp struct {
 	Spec        cappv1alpha1.CappSpec `json:"spec"`
 }
 
+// PatchCapp is the document which the patches of PATCH requests are applied to. Unlike in UpdateCapp, the labels
+// and annotations are objects, so that single keys can be patched.
+type PatchCapp struct {
+	Annotations map[string]string     `json:"annotations"`
+	Labels      map[string]string     `json:"labels"`
+	Spec        cappv1alpha1.CappSpec `json:"spec"`
+}
+
 type GetCappQuery struct {
 	LabelSelector string `form:"labelSelector" json:"labelSelector"`
 }
@@ -37,6 +45,12 @@ type GetCappQuery struct {
 type CreateCappQuery struct {
 	Environment string `form:"environment" json:"environment"`
 	Region      string `form:"region" json:"region"`
+	DryRunQuery
+}
+
+// DryRunQuery is the query of requests which may be validated by the cluster without being persisted.
+type DryRunQuery struct {
+	DryRun bool `form:"dryRun" json:"dryRun"`
 }
 
 type CappList struct {
@@ -51,8 +65,9 @@ type CappSummary struct {
 }
 
 type CappStateResponse struct {
-	Name  string `json:"name"`
-	State string `json:"state" binding:"oneof=enabled disabled"`
+	Name            string `json:"name"`
+	State           string `json:"state" binding:"oneof=enabled disabled"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 type CappNamespaceUri struct {
@@ -71,6 +86,7 @@ type GetCappStateResponse struct {
 	LastCreatedRevision string `json:"lastCreatedRevision"`
 	LastReadyRevision   string `json:"lastReadyRevision"`
 	State               string `json:"state" binding:"required,oneof=enabled disabled"`
+	ResourceVersion     string `json:"resourceVersion,omitempty"`
 }
 
 type GetDNSResponse struct {
@@ -29,6 +29,42 @@ type CappRevisionUri struct {
 	CappName         string `uri:"cappName" json:"cappName"`
 }
 
+// CappRollback sets the Site of a Capp rolled back to a CappRevision. The current Site is kept unless
+// a Site is set or the Site of the CappRevision is restored.
+type CappRollback struct {
+	Site        string `json:"site,omitempty"`
+	RestoreSite bool   `json:"restoreSite,omitempty" binding:"excluded_with=Site"`
+}
+
+// CappRollbackResponse is the Capp rolled back to a CappRevision, along with the CappRevision.
+type CappRollbackResponse struct {
+	Capp     Capp         `json:"capp"`
+	Revision CappRevision `json:"revision"`
+}
+
+// CappRevisionDiffQuery selects what a CappRevision is diffed against, either another CappRevision or the live Capp,
+// and whether the unified diff is rendered.
+type CappRevisionDiffQuery struct {
+	Against string `form:"against" json:"against"`
+	Format  string `form:"format" json:"format" binding:"omitempty,oneof=unified"`
+}
+
+// FieldDiff is a change of a field, found at a JSON path of a CappTemplate. The old value is unset
+// for added fields and the new value for removed fields.
+type FieldDiff struct {
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+}
+
+// CappRevisionDiff lists the changes from the CappTemplate of a CappRevision to another CappTemplate.
+type CappRevisionDiff struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Changes []FieldDiff `json:"changes"`
+	Unified string      `json:"unified,omitempty"`
+}
+
 type CappRevisionQuery struct {
 	LabelSelector string `form:"labelSelector"`
 }
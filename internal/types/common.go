@@ -9,6 +9,7 @@ type Metadata struct {
 	Name              string `json:"name" binding:"required"`
 	Namespace         string `json:"namespace"`
 	CreationTimestamp string `json:"creationTimestamp"`
+	ResourceVersion   string `json:"resourceVersion"`
 }
 
 type ListMetadata struct {
@@ -1,5 +1,18 @@
 package types
 
+import "time"
+
 type LoginOutput struct {
-	Token string `json:"token"`
+	Token               string     `json:"token"`
+	RefreshToken        string     `json:"refreshToken,omitempty"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+	CSRFToken           string     `json:"csrfToken,omitempty"`
+}
+
+type LoginQuery struct {
+	Session bool `form:"session"`
+}
+
+type LoginRefreshInput struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
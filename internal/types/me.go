@@ -0,0 +1,13 @@
+package types
+
+type Me struct {
+	Username   string          `json:"username"`
+	Groups     []string        `json:"groups"`
+	Namespaces []NamespaceRole `json:"namespaces"`
+	ListMetadata
+}
+
+type NamespaceRole struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
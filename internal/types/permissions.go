@@ -0,0 +1,24 @@
+package types
+
+type PermissionCheck struct {
+	Preset      string `json:"preset,omitempty"`
+	Verb        string `json:"verb,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+type PermissionsCheckInput struct {
+	Checks []PermissionCheck `json:"checks" binding:"required,min=1,max=100"`
+}
+
+type PermissionCheckResult struct {
+	PermissionCheck
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type PermissionsCheckOutput struct {
+	Results []PermissionCheckResult `json:"results"`
+}
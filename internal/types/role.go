@@ -0,0 +1,17 @@
+package types
+
+import rbacv1 "k8s.io/api/rbac/v1"
+
+// Role is a platform role of the role catalog, which binds members of a namespace to a ClusterRole.
+// The rules of the role are those which identify its members when their RoleBindings cannot be read.
+type Role struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	ClusterRole string              `json:"clusterRole"`
+	Rules       []rbacv1.PolicyRule `json:"rules,omitempty"`
+}
+
+// RoleCatalog lists the platform roles from the least to the most privileged.
+type RoleCatalog struct {
+	Roles []Role `json:"roles"`
+}
@@ -29,10 +29,11 @@ type GetSecretsResponse struct {
 }
 
 type GetSecretResponse struct {
-	Id         string     `json:"id"`
-	Type       string     `json:"type"`
-	SecretName string     `json:"secretName"`
-	Data       []KeyValue `json:"data"`
+	Id              string     `json:"id"`
+	Type            string     `json:"type"`
+	SecretName      string     `json:"secretName"`
+	Data            []KeyValue `json:"data"`
+	ResourceVersion string     `json:"resourceVersion,omitempty"`
 }
 
 type Secret struct {
@@ -46,10 +47,11 @@ type UpdateSecretRequest struct {
 }
 
 type UpdateSecretResponse struct {
-	Type          string     `json:"type"`
-	SecretName    string     `json:"secretName"`
-	NamespaceName string     `json:"namespaceName"`
-	Data          []KeyValue `json:"data"`
+	Type            string     `json:"type"`
+	SecretName      string     `json:"secretName"`
+	NamespaceName   string     `json:"namespaceName"`
+	Data            []KeyValue `json:"data"`
+	ResourceVersion string     `json:"resourceVersion,omitempty"`
 }
 
 type DeleteSecretResponse struct {
@@ -8,26 +8,70 @@ type ServiceAccountRequestUri struct {
 }
 
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token               string     `json:"token"`
+	Mechanism           string     `json:"mechanism"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
 }
 
-type CreateServiceAccountRequest ServiceAccountRequestUri
+type CreateServiceAccountRequest struct {
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
+}
 
 type ServiceAccountOutput struct {
-	ServiceAccounts []string `json:"serviceAccounts"`
+	ServiceAccounts []ServiceAccount `json:"serviceAccounts"`
 	ListMetadata
 }
 
 type ServiceAccount struct {
-	Name  string `json:"name" binding:"required"`
-	Token string `json:"token,omitempty"`
+	Name              string            `json:"name" binding:"required"`
+	Token             string            `json:"token,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Role              string            `json:"role,omitempty"`
+	CreationTimestamp *time.Time        `json:"creationTimestamp,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	ImagePullSecrets  []string          `json:"imagePullSecrets,omitempty"`
+	Tokens            []Token           `json:"tokens,omitempty"`
+}
+
+type ServiceAccountRole struct {
+	Role string `json:"role" binding:"required,platformrole"`
 }
 
 type TokenRequestResponse struct {
+	Name                string    `json:"name,omitempty"`
 	Token               string    `json:"token"`
 	ExpirationTimestamp time.Time `json:"expirationTimestamp,omitempty"`
+	ExpirationSeconds   int64     `json:"expirationSeconds"`
 }
 
 type CreateTokenQuery struct {
 	ExpirationSeconds string `form:"expirationSeconds" json:"expirationSeconds"`
+	Name              string `form:"name" json:"name"`
+	Description       string `form:"description" json:"description"`
+}
+
+type KubeconfigQuery struct {
+	CreateTokenQuery
+	Clusters []string `form:"clusters" json:"clusters"`
+}
+
+type TokenRequestUri struct {
+	NamespaceName      string `uri:"namespaceName" json:"namespaceName" binding:"required"`
+	ServiceAccountName string `uri:"serviceAccountName" json:"serviceAccountName" binding:"required"`
+	TokenName          string `uri:"tokenName" json:"tokenName" binding:"required"`
+}
+
+type Token struct {
+	Name                string    `json:"name"`
+	Description         string    `json:"description,omitempty"`
+	Creator             string    `json:"creator,omitempty"`
+	CreationTimestamp   time.Time `json:"creationTimestamp"`
+	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+}
+
+type TokensOutput struct {
+	Tokens []Token `json:"tokens"`
+	ListMetadata
 }
@@ -1,22 +1,37 @@
 package types
 
+import "time"
+
+// User is a member of a namespace, which is either a User or a Group subject.
+// The access of a member with an expiration is removed once it expires.
 type User struct {
-	Name string `json:"name" binding:"required"`
-	Role string `json:"role" binding:"required,oneof=admin viewer contributor"`
+	Name             string     `json:"name" binding:"required"`
+	Type             string     `json:"type" binding:"omitempty,oneof=User Group"`
+	Role             string     `json:"role" binding:"required,platformrole"`
+	Members          []string   `json:"members,omitempty"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	ExpiresInSeconds int64      `json:"expiresInSeconds,omitempty"`
+	ResourceVersion  string     `json:"resourceVersion,omitempty"`
 }
 
 type UserIdentifier struct {
 	UserName      string `json:"userName" uri:"userName" binding:"required"`
 	NamespaceName string `json:"namespaceName" binding:"required" uri:"namespaceName"`
+	Type          string `json:"type"`
+}
+
+type UserQuery struct {
+	Type string `form:"type" json:"type" binding:"omitempty,oneof=User Group"`
 }
 
 type UserInput struct {
-	Namespace string `json:"namespace" binding:"required,oneof=admin viewer contributor"`
+	Namespace string `json:"namespace" binding:"required"`
 	User
 }
 
 type UpdateUserData struct {
-	Role string `json:"role" binding:"required,oneof=admin viewer contributor"`
+	Role      string     `json:"role" binding:"required,platformrole"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type UsersOutput struct {
@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+type WSTicketInput struct {
+	Path string `json:"path" binding:"required"`
+}
+
+type WSTicketOutput struct {
+	Ticket              string    `json:"ticket"`
+	Path                string    `json:"path"`
+	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+}
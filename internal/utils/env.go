@@ -4,6 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
+)
+
+const (
+	EnvKubeAPIServer      = "KUBE_API_SERVER"
+	EnvInsecureSkipVerify = "INSECURE_SKIP_VERIFY"
 )
 
 // GetEnvBool retrieves the value of the environment variable named by the key.
@@ -37,3 +43,19 @@ func GetEnvNumber(key string, defaultValue int) (int, error) {
 
 	return valInt, nil
 }
+
+// GetEnvDuration retrieves the value of the environment variable named by the key.
+// If the variable is empty or not set, it returns the default value.
+func GetEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return defaultValue, nil
+	}
+
+	valDuration, err := time.ParseDuration(valStr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as duration", valStr)
+	}
+
+	return valDuration, nil
+}
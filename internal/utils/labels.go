@@ -1,11 +1,20 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 )
 
+const (
+	// labelValueMaxLength is the maximal length of a label value.
+	labelValueMaxLength  = 63
+	labelValueHashLength = 10
+)
+
 var (
 	cappAPIGroup         = cappv1alpha1.GroupVersion.Group
 	ManagedLabel         = cappAPIGroup + "/managed"
@@ -23,6 +32,23 @@ var (
 
 	CappNameLabel         = cappAPIGroup + "/cappName"
 	CappNameLabelSelector = CappNameLabel + "=%s"
+
+	ServiceAccountTokenLabel         = cappAPIGroup + "/service-account-token"
+	ServiceAccountTokenLabelSelector = ServiceAccountTokenLabel + "=%s"
+	TokenNameLabel                   = cappAPIGroup + "/token-name"
+
+	ServiceAccountRoleLabel = cappAPIGroup + "/service-account-role"
+	PendingRoleLabel        = cappAPIGroup + "/pending-role"
+
+	ServiceAccountDescriptionAnnotation = cappAPIGroup + "/service-account-description"
+
+	TokenDescriptionAnnotation         = cappAPIGroup + "/token-description"
+	TokenCreatorAnnotation             = cappAPIGroup + "/token-creator"
+	TokenCreationTimestampAnnotation   = cappAPIGroup + "/token-created-at"
+	TokenExpirationTimestampAnnotation = cappAPIGroup + "/token-expires-at"
+	TokenExpirationPolicyAnnotation    = cappAPIGroup + "/token-expiration-policy"
+
+	RoleBindingExpirationAnnotation = cappAPIGroup + "/expires-at"
 )
 
 // AddManagedLabel adds the managed label to the given labels map.
@@ -30,3 +56,16 @@ func AddManagedLabel(labels map[string]string) map[string]string {
 	labels[ManagedLabel] = "true"
 	return labels
 }
+
+// LabelValue returns the value of a label which refers to the named object. Names which are too long to be label
+// values are truncated, and a hash of the name keeps them unique.
+func LabelValue(name string) string {
+	if len(name) <= labelValueMaxLength {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	prefix := strings.TrimRight(name[:labelValueMaxLength-labelValueHashLength-1], "-.")
+
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(hash[:])[:labelValueHashLength])
+}
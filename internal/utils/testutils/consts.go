@@ -12,6 +12,10 @@ var (
 	ManagedByLabel = cappAPIGroup + "/managed-by"
 )
 
+const (
+	ManagedLabelValue = "true"
+)
+
 const (
 	Domain      = "dana-team.io"
 	Hostname    = "custom-capp"
@@ -56,7 +60,9 @@ const (
 const (
 	ServiceAccountsKey     = "serviceAccounts"
 	TokenKey               = "token"
+	MechanismKey           = "mechanism"
 	ExpirationTimestampKey = "expirationTimestamp"
+	CreationTimestampKey   = "creationTimestamp"
 	Secret                 = "Secret"
 	V1                     = "v1"
 	Value                  = "value"
@@ -65,11 +71,31 @@ const (
 const (
 	RoleKey              = "role"
 	AdminKey             = "admin"
+	ContributorKey       = "contributor"
 	ViewerKey            = "viewer"
 	RoleBindingsKey      = "rolebindings"
 	UsersKey             = "users"
 	RoleBindingsGroupKey = "rbac.authorization.k8s.io"
 	CappUserPrefix       = "capp-user-"
+	GroupName            = TestName + "-group"
+	UsernameKey          = "username"
+	GroupsKey            = "groups"
+	MembersKey           = "members"
+	UserKind             = "User"
+	GroupKind            = "Group"
+	LDAPGroupName        = "cn=Platform Admins,ou=groups,dc=example,dc=com"
+	GroupMemberName      = TestName + "-member"
+	ExpiresAtKey         = "expiresAt"
+	ExpiresInSecondsKey  = "expiresInSeconds"
+	UserExpiresAt        = "2100-01-01T00:00:00Z"
+	UserExpiredAt        = "2000-01-01T00:00:00Z"
+)
+
+const (
+	ResultsKey          = "results"
+	FailingResource     = "failing"
+	AccessReviewFailure = "selfsubjectaccessreviews.authorization.k8s.io failed"
+	AccessAllowedReason = "allowed by test RoleBinding"
 )
 
 var (
@@ -107,11 +133,23 @@ const (
 	StatusKey      = "status"
 	CountKey       = "count"
 	DataKey        = "data"
+
+	ResourceVersionKey = "resourceVersion"
+)
+
+const (
+	// CreatedResourceVersion and UpdatedResourceVersion are the resource versions which the fake client
+	// assigns to an object when it is created and first updated.
+	CreatedResourceVersion = "1"
+	UpdatedResourceVersion = "2"
+	StaleResourceVersion   = "0"
 )
 
 const (
 	ContentType     = "Content-Type"
 	ApplicationJson = "application/json"
+	MergePatchJson  = "application/merge-patch+json"
+	JsonPatchJson   = "application/json-patch+json"
 )
 
 var (
@@ -127,10 +165,12 @@ const (
 	SiteName                = TestName + "-site"
 	PlacementEnvironmentKey = "environment"
 	PlacementRegionKey      = "region"
+	DryRunKey               = "dryRun"
 	CappsKey                = "capps"
 	RecordsKey              = "records"
 	CappNamespace           = TestNamespace + "-" + CappsKey
 	CappImage               = "ghcr.io/dana-team/capp-gin-app:v0.2.0"
+	PreviousCappImage       = "ghcr.io/dana-team/capp-gin-app:v0.1.0"
 	ContainerName           = "capp-container"
 	StateKey                = "state"
 	DisabledState           = "disabled"
@@ -144,8 +184,11 @@ const (
 )
 
 const (
-	ServiceAccountName       = TestName + "-serviceAccount"
-	ServiceAccountAnnotation = "kubernetes.io/service-account.name"
+	ServiceAccountName        = TestName + "-serviceAccount"
+	ServiceAccountAnnotation  = "kubernetes.io/service-account.name"
+	ServiceAccountRoleSuffix  = "-platform-role"
+	ServiceAccountDescription = "Deploys the applications of the team"
+	DockerCfgSecretName       = TestName + "-dockercfg"
 )
 
 const (
@@ -177,4 +220,24 @@ const (
 	ServiceAccountParam    = "serviceaccounts"
 	ExpirationSecondsParam = "expirationSeconds"
 	TokenRequestSuffix     = "token-request"
+	NamedTokenInfix        = "platform-token"
+	NamedTokenName         = "ci"
+	NamedTokenDescription  = "Token of the CI pipeline"
+	NamedTokenCreator      = TestName + "-creator"
+	NamedTokenCreatedAt    = "2024-01-01T00:00:00Z"
+	NamedTokenExpiresAt    = "2024-01-01T10:00:00Z"
+	KubeAPIServer          = "https://api.cluster-test.domain-test.com:6443"
+	ManagedClusterName     = "cluster-a"
+	RootCAConfigMapName    = "kube-root-ca.crt"
+	RootCAConfigMapKey     = "ca.crt"
+	RootCAData             = "-----BEGIN CERTIFICATE-----\ntest\n-----END CERTIFICATE-----\n"
+	ConfiguredCAData       = "-----BEGIN CERTIFICATE-----\nconfigured\n-----END CERTIFICATE-----\n"
+)
+
+const (
+	OAuthCode                 = "testcode"
+	OAuthAccessToken          = "test_access_token"
+	OAuthRefreshedAccessToken = "test_refreshed_access_token"
+	OAuthRefreshToken         = "test_refresh_token"
+	OAuthExpiresIn            = 86400
 )
@@ -5,6 +5,7 @@ import (
 
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,6 +29,12 @@ func CreateTestSecret(fakeClient *fake.Clientset, name, namespace string) {
 	}
 }
 
+// CreateTestNamedTokenSecret creates a test secret which a named ServiceAccount token is bound to.
+func CreateTestNamedTokenSecret(fakeClient *fake.Clientset, serviceAccountName, namespace, tokenName string) {
+	secret := PrepareNamedTokenSecret(serviceAccountName, namespace, tokenName)
+	createTestSecret(fakeClient, secret)
+}
+
 // CreateTestCapp creates a test Capp object.
 func CreateTestCapp(dynClient runtimeClient.WithWatch, name, namespace, domain, site string, labels, annotations map[string]string) {
 	cappRevision := PrepareCapp(name, namespace, domain, site, labels, annotations)
@@ -64,6 +71,15 @@ func CreateTestCappRevision(dynClient runtimeClient.WithWatch, name, namespace,
 	}
 }
 
+// CreateTestCappRevisionOfCapp creates a test CappRevision object of a Capp.
+func CreateTestCappRevisionOfCapp(dynClient runtimeClient.WithWatch, name, namespace, cappName, site, image string, labels, annotations map[string]string) {
+	cappRevision := PrepareCappRevisionOfCapp(name, namespace, cappName, site, image, labels, annotations)
+	err := dynClient.Create(context.TODO(), &cappRevision)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // CreateTestRoleBinding creates a test RoleBinding object.
 func CreateTestRoleBinding(fakeClient *fake.Clientset, name, namespace, role string) {
 	roleBinding := PrepareRoleBinding(name, namespace, role)
@@ -74,6 +90,44 @@ func CreateTestRoleBinding(fakeClient *fake.Clientset, name, namespace, role str
 	}
 }
 
+// CreateTestClusterRole creates a test ClusterRole of the role, which grants the rules.
+func CreateTestClusterRole(fakeClient *fake.Clientset, role string, rules []rbacv1.PolicyRule) {
+	clusterRole := PrepareClusterRole(role, rules)
+
+	_, err := fakeClient.RbacV1().ClusterRoles().Create(context.TODO(), &clusterRole, metav1.CreateOptions{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CreateTestGroupRoleBinding creates a test RoleBinding object which binds the role to a group.
+func CreateTestGroupRoleBinding(fakeClient *fake.Clientset, name, namespace, group, role string) {
+	roleBinding := PrepareGroupRoleBinding(name, namespace, group, role)
+
+	_, err := fakeClient.RbacV1().RoleBindings(namespace).Create(context.TODO(), &roleBinding, metav1.CreateOptions{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CreateTestExpiringRoleBinding creates a test RoleBinding object whose access expires at the given time.
+func CreateTestExpiringRoleBinding(fakeClient *fake.Clientset, name, namespace, role, expiresAt string) {
+	roleBinding := PrepareExpiringRoleBinding(name, namespace, role, expiresAt)
+
+	_, err := fakeClient.RbacV1().RoleBindings(namespace).Create(context.TODO(), &roleBinding, metav1.CreateOptions{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CreateTestGroup creates a test OpenShift Group object.
+func CreateTestGroup(dynClient runtimeClient.Client, name string, users []string) {
+	group := PrepareGroup(name, users)
+	if err := dynClient.Create(context.TODO(), &group); err != nil {
+		panic(err)
+	}
+}
+
 // CreateTestConfigMap creates a test ConfigMap object.
 func CreateTestConfigMap(fakeClient *fake.Clientset, name, namespace string) {
 	configMap := PrepareConfigMap(name, namespace, map[string]string{testutils.ConfigMapDataKey: testutils.ConfigMapDataValue})
@@ -83,6 +137,15 @@ func CreateTestConfigMap(fakeClient *fake.Clientset, name, namespace string) {
 	}
 }
 
+// CreateTestRootCAConfigMap creates a test ConfigMap holding the CA bundle of the API server.
+func CreateTestRootCAConfigMap(fakeClient *fake.Clientset, namespace string) {
+	configMap := PrepareConfigMap(testutils.RootCAConfigMapName, namespace, map[string]string{testutils.RootCAConfigMapKey: testutils.RootCAData})
+	_, err := fakeClient.CoreV1().ConfigMaps(namespace).Create(context.TODO(), &configMap, metav1.CreateOptions{})
+	if err != nil {
+		panic(err)
+	}
+}
+
 // CreateTestPod creates a test Pod object.
 func CreateTestPod(fakeClient *fake.Clientset, namespace, name, cappName string, isMultipleContainers bool) {
 	pod := PreparePod(namespace, name, cappName, isMultipleContainers)
@@ -119,6 +182,16 @@ func CreateTestServiceAccount(fakeClient *fake.Clientset, namespace, name string
 	}
 }
 
+// CreateTestServiceAccountRoleBinding creates a test RoleBinding object which grants a platform role to a ServiceAccount.
+func CreateTestServiceAccountRoleBinding(fakeClient *fake.Clientset, namespace, serviceAccountName, role string) {
+	roleBinding := PrepareServiceAccountRoleBinding(serviceAccountName, namespace, role)
+
+	_, err := fakeClient.RbacV1().RoleBindings(namespace).Create(context.TODO(), &roleBinding, metav1.CreateOptions{})
+	if err != nil {
+		panic(err)
+	}
+}
+
 // CreateTestServiceAccountWithToken creates a test service account
 func CreateTestServiceAccountWithToken(fakeClient *fake.Clientset, namespace, serviceAccountName, tokenSecretName, tokenValue, dockerCfgSecretName string) {
 	tokenSecret := PrepareTokenSecret(tokenSecretName, namespace, tokenValue, serviceAccountName)
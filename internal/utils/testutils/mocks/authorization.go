@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// AddTestAccessReviewReactor makes SelfSubjectAccessReviews on the fake client allow only the given
+// "verb resource" or "verb resource/subresource" pairs. Reviews on testutils.FailingResource fail.
+func AddTestAccessReviewReactor(fakeClient *fake.Clientset, allowed ...string) {
+	fakeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		if attributes.Resource == testutils.FailingResource {
+			return true, nil, errors.New(testutils.AccessReviewFailure)
+		}
+
+		resource := attributes.Resource
+		if attributes.Subresource != "" {
+			resource += "/" + attributes.Subresource
+		}
+
+		if slices.Contains(allowed, attributes.Verb+" "+resource) {
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true, Reason: testutils.AccessAllowedReason}
+		} else {
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: false}
+		}
+
+		return true, review, nil
+	})
+}
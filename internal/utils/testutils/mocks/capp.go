@@ -208,10 +208,11 @@ func PrepareUpdateCappStateType(state string) types.CappState {
 }
 
 // PrepareCappMetadata returns a CappMetadata object.
-func PrepareCappMetadata(name, namespace string) types.Metadata {
+func PrepareCappMetadata(name, namespace, resourceVersion string) types.Metadata {
 	return types.Metadata{
-		Name:      name,
-		Namespace: namespace,
+		Name:            name,
+		Namespace:       namespace,
+		ResourceVersion: resourceVersion,
 	}
 }
 
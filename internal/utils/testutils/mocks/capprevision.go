@@ -3,6 +3,7 @@ package mocks
 import (
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -22,6 +23,16 @@ func PrepareCappRevision(name, namespace, site string, labels, annotations map[s
 	return cappRevision
 }
 
+// PrepareCappRevisionOfCapp returns a mock CappRevision object of a Capp, whose template has the given image, labels and annotations.
+func PrepareCappRevisionOfCapp(name, namespace, cappName, site, image string, labels, annotations map[string]string) cappv1alpha1.CappRevision {
+	cappRevision := PrepareCappRevision(name, namespace, site, labels, annotations)
+	cappRevision.Labels = map[string]string{testutils.LabelCappName: cappName}
+	cappRevision.Annotations = nil
+	cappRevision.Spec.CappTemplate.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image = image
+
+	return cappRevision
+}
+
 // PrepareCappRevisionSpec returns a mock CappRevision Spec object.
 func PrepareCappRevisionSpec(site string, labels, annotations map[string]string) cappv1alpha1.CappRevisionSpec {
 	cappRevisionSpec := cappv1alpha1.CappRevisionSpec{
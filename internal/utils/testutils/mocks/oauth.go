@@ -2,7 +2,12 @@ package mocks
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	userv1 "github.com/openshift/api/user/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -66,3 +71,42 @@ func PrepareClusterRoleBinding(username, clusterRoleName string) rbacv1.ClusterR
 		},
 	}
 }
+
+// PrepareOAuthAccessToken returns a mock OAuthAccessToken object.
+func PrepareOAuthAccessToken(name string) *oauthv1.OAuthAccessToken {
+	return &oauthv1.OAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+// NewOAuthServer returns a fake OAuth server, which issues authorization codes on /auth
+// and exchanges authorization codes and refresh tokens for tokens on /token.
+func NewOAuthServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://example.com/callback?code="+testutils.OAuthCode)
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		var accessToken string
+		switch {
+		case r.FormValue("grant_type") == "authorization_code" && r.FormValue("code") == testutils.OAuthCode:
+			accessToken = testutils.OAuthAccessToken
+		case r.FormValue("grant_type") == "refresh_token" && r.FormValue("refresh_token") == testutils.OAuthRefreshToken:
+			accessToken = testutils.OAuthRefreshedAccessToken
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"access_token": %q, "token_type": "bearer", "refresh_token": %q, "expires_in": %d}`,
+			accessToken, testutils.OAuthRefreshToken, testutils.OAuthExpiresIn)))
+	})
+
+	return httptest.NewServer(mux)
+}
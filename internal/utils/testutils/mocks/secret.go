@@ -1,7 +1,10 @@
 package mocks
 
 import (
+	"fmt"
+
 	"github.com/dana-team/platform-backend/internal/types"
+	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +42,28 @@ func PrepareTokenSecret(name, namespace, tokenValue, serviceAccountName string)
 	}
 }
 
+// PrepareNamedTokenSecret returns a mock secret which a named ServiceAccount token is bound to.
+func PrepareNamedTokenSecret(serviceAccountName, namespace, tokenName string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-%s", serviceAccountName, testutils.NamedTokenInfix, tokenName),
+			Namespace: namespace,
+			Labels: map[string]string{
+				testutils.ManagedLabel:         "true",
+				utils.ServiceAccountTokenLabel: utils.LabelValue(serviceAccountName),
+				utils.TokenNameLabel:           tokenName,
+			},
+			Annotations: map[string]string{
+				utils.TokenDescriptionAnnotation:         testutils.NamedTokenDescription,
+				utils.TokenCreatorAnnotation:             testutils.NamedTokenCreator,
+				utils.TokenCreationTimestampAnnotation:   testutils.NamedTokenCreatedAt,
+				utils.TokenExpirationTimestampAnnotation: testutils.NamedTokenExpiresAt,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
 // PrepareDockerConfigSecret returns a mock docker config secret object.
 func PrepareDockerConfigSecret(name, namespace, serviceAccountTokenSecretName string) corev1.Secret {
 	ownerReference := metav1.OwnerReference{
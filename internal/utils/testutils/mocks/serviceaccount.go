@@ -1,8 +1,10 @@
 package mocks
 
 import (
+	"github.com/dana-team/platform-backend/internal/utils"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,7 +28,32 @@ func PrepareServiceAccount(name, namespace string, dockerCfgSecretName string) *
 				Namespace: namespace,
 			},
 		}
+		serviceAccount.ImagePullSecrets = []corev1.LocalObjectReference{{Name: dockerCfgSecretName}}
 	}
 
 	return serviceAccount
 }
+
+// PrepareServiceAccountRoleBinding returns a mock RoleBinding object which grants a platform role to a ServiceAccount.
+func PrepareServiceAccountRoleBinding(serviceAccountName, namespace, role string) rbacv1.RoleBinding {
+	return rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountName + testutils.ServiceAccountRoleSuffix,
+			Namespace: namespace,
+			Labels: utils.AddManagedLabel(map[string]string{
+				utils.ServiceAccountRoleLabel: utils.LabelValue(serviceAccountName),
+			}),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccountName,
+				Namespace: namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind: clusterRoleKey,
+			Name: cappUserPrefix + role,
+		},
+	}
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/dana-team/platform-backend/internal/utils"
+	userv1 "github.com/openshift/api/user/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -34,10 +35,53 @@ func PrepareRoleBinding(name, namespace, role string) rbacv1.RoleBinding {
 	}
 }
 
+// PrepareGroupRoleBinding returns a mock RoleBinding object which binds the role to a group.
+func PrepareGroupRoleBinding(name, namespace, group, role string) rbacv1.RoleBinding {
+	roleBinding := PrepareRoleBinding(name, namespace, role)
+	roleBinding.Subjects = []rbacv1.Subject{
+		{
+			Kind:     rbacv1.GroupKind,
+			Name:     group,
+			APIGroup: rbacv1.GroupName,
+		},
+	}
+
+	return roleBinding
+}
+
+// PrepareExpiringRoleBinding returns a mock RoleBinding object whose access expires at the given time.
+func PrepareExpiringRoleBinding(name, namespace, role, expiresAt string) rbacv1.RoleBinding {
+	roleBinding := PrepareRoleBinding(name, namespace, role)
+	roleBinding.Annotations = map[string]string{utils.RoleBindingExpirationAnnotation: expiresAt}
+
+	return roleBinding
+}
+
+// PrepareClusterRole returns a mock ClusterRole object of the role, which grants the rules.
+func PrepareClusterRole(role string, rules []rbacv1.PolicyRule) rbacv1.ClusterRole {
+	return rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cappUserPrefix + role,
+		},
+		Rules: rules,
+	}
+}
+
+// PrepareGroup returns a mock OpenShift Group object.
+func PrepareGroup(name string, users []string) userv1.Group {
+	return userv1.Group{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Users: users,
+	}
+}
+
 // PrepareUserType returns a mock User type object.
 func PrepareUserType(name, role string) types.User {
 	return types.User{
 		Name: name,
+		Type: rbacv1.UserKind,
 		Role: role,
 	}
 }
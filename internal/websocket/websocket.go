@@ -43,15 +43,17 @@ func NewWebSocket(upgrader *websocket.Upgrader) *WebSocket {
 }
 
 // Register upgrades an HTTP connection to a WebSocket connection.
-// It retrieves the token from the context and sets it in the WebSocket headers.
+// The WebSocket protocol header is echoed back only if the client sent it, as required by the WebSocket handshake,
+// so that the token is not sent back to clients which authenticated with a ticket or an Authorization header.
 func (ws *WebSocket) Register(c *gin.Context) (*websocket.Conn, error) {
-	token, exists := c.Get("token")
-	if !exists {
+	if _, exists := c.Get(middleware.TokenCtxKey); !exists {
 		return nil, errors.New("Token not found")
 	}
 
 	h := http.Header{}
-	h.Set(middleware.WebsocketTokenHeader, token.(string))
+	if protocol := c.GetHeader(middleware.WebsocketTokenHeader); protocol != "" {
+		h.Set(middleware.WebsocketTokenHeader, protocol)
+	}
 
 	conn, err := ws.upgrader.Upgrade(c.Writer, c.Request, h)
 	if err != nil {
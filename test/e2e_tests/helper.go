@@ -78,7 +78,9 @@ func compareResponses(response, expectedResponse map[string]interface{}) {
 
 	compareError(expectedResponseNormalized, response)
 	less := func(a, b string) bool { return a < b }
-	Expect(response).Should(BeComparableTo(expectedResponseNormalized, cmpopts.SortSlices(less)))
+	// Resource versions are assigned by the cluster, so they cannot be expected.
+	isResourceVersion := func(key string, _ interface{}) bool { return key == testutils.ResourceVersionKey }
+	Expect(response).Should(BeComparableTo(expectedResponseNormalized, cmpopts.SortSlices(less), cmpopts.IgnoreMapEntries(isResourceVersion)))
 }
 
 // compareError compares two errors and asserts that the response contains the expected response.
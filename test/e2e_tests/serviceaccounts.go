@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/dana-team/platform-backend/internal/controllers"
+	"github.com/dana-team/platform-backend/internal/types"
 	"github.com/dana-team/platform-backend/internal/utils/testutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -34,7 +35,8 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 			status, response := performHTTPRequest(httpClient, nil, http.MethodGet, uri, "", "", userToken)
 			tokenSecret := getServiceAccountTokenSecret(k8sClient, serviceAccountName, namespaceName)
 			expectedResponse := map[string]interface{}{
-				testutils.TokenKey: string(tokenSecret.Data[testutils.TokenKey]),
+				testutils.TokenKey:     string(tokenSecret.Data[testutils.TokenKey]),
+				testutils.MechanismKey: controllers.TokenMechanismDockercfg,
 			}
 			Expect(status).Should(Equal(http.StatusOK))
 			compareResponses(response, expectedResponse)
@@ -70,12 +72,10 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 			uri := fmt.Sprintf("%s/v1/namespaces/%s/%s/%s", platformURL, namespaceName, testutils.ServiceAccountParam, serviceAccountName)
 			status, response := performHTTPRequest(httpClient, nil, http.MethodGet, uri, "", "", userToken)
 
-			expectedResponse := map[string]interface{}{
-				testutils.NameKey: serviceAccountName,
-			}
-
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			Expect(response).Should(HaveKey(testutils.CreationTimestampKey))
+			Expect(response).Should(HaveKeyWithValue(testutils.NameKey, serviceAccountName))
+			Expect(response[testutils.LabelsKey]).Should(HaveKeyWithValue(testutils.ManagedLabel, testutils.ManagedLabelValue))
 		})
 
 		It("Should handle a get of a non-existing ServiceAccount", func() {
@@ -97,10 +97,11 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 			status, response := performHTTPRequest(httpClient, nil, http.MethodPost, uri, "", "", userToken)
 
 			expectedResponse := map[string]interface{}{
-				testutils.NameKey: newServiceAccountName,
+				testutils.NameKey:   newServiceAccountName,
+				testutils.LabelsKey: map[string]string{testutils.ManagedLabel: testutils.ManagedLabelValue},
 			}
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			compareResponses(removeCreationTimestamps(response), expectedResponse)
 		})
 
 		It("Should handle the deletion of a ServiceAccount", func() {
@@ -136,14 +137,14 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 
 			expectedResponse := map[string]interface{}{
 				testutils.CountKey: 2,
-				testutils.ServiceAccountsKey: []string{
-					serviceAccountName,
-					secondServiceAccountName,
+				testutils.ServiceAccountsKey: []types.ServiceAccount{
+					{Name: serviceAccountName},
+					{Name: secondServiceAccountName},
 				},
 			}
 
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			compareResponses(removeCreationTimestamps(response), expectedResponse)
 		})
 
 		It("Should get all secretAccounts in a namespace with limit of 50", func() {
@@ -155,14 +156,14 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 
 			expectedResponse := map[string]interface{}{
 				testutils.CountKey: 2,
-				testutils.ServiceAccountsKey: []string{
-					serviceAccountName,
-					secondServiceAccountName,
+				testutils.ServiceAccountsKey: []types.ServiceAccount{
+					{Name: serviceAccountName},
+					{Name: secondServiceAccountName},
 				},
 			}
 
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			compareResponses(removeCreationTimestamps(response), expectedResponse)
 		})
 
 		It("Should get one ServiceAccount in a namespace with limit of 1 and page 1", func() {
@@ -174,13 +175,13 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 
 			expectedResponse := map[string]interface{}{
 				testutils.CountKey: 1,
-				testutils.ServiceAccountsKey: []string{
-					serviceAccountName,
+				testutils.ServiceAccountsKey: []types.ServiceAccount{
+					{Name: serviceAccountName},
 				},
 			}
 
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			compareResponses(removeCreationTimestamps(response), expectedResponse)
 		})
 
 		It("Should get one ServiceAccount in a namespace with limit of 1 and page 2", func() {
@@ -192,13 +193,13 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 
 			expectedResponse := map[string]interface{}{
 				testutils.CountKey: 1,
-				testutils.ServiceAccountsKey: []string{
-					secondServiceAccountName,
+				testutils.ServiceAccountsKey: []types.ServiceAccount{
+					{Name: secondServiceAccountName},
 				},
 			}
 
 			Expect(status).Should(Equal(http.StatusOK))
-			compareResponses(response, expectedResponse)
+			compareResponses(removeCreationTimestamps(response), expectedResponse)
 		})
 
 		It("Should not get ServiceAccount with limit of 1 and page 3", func() {
@@ -218,3 +219,18 @@ var _ = Describe("Validate ServiceAccounts routes and functionality", func() {
 		})
 	})
 })
+
+// removeCreationTimestamps removes the creation timestamps from a ServiceAccount response, or from each
+// ServiceAccount of a listing, since they cannot be known in advance.
+func removeCreationTimestamps(response map[string]interface{}) map[string]interface{} {
+	delete(response, testutils.CreationTimestampKey)
+	if serviceAccounts, ok := response[testutils.ServiceAccountsKey].([]interface{}); ok {
+		for _, serviceAccount := range serviceAccounts {
+			if serviceAccount, ok := serviceAccount.(map[string]interface{}); ok {
+				delete(serviceAccount, testutils.CreationTimestampKey)
+			}
+		}
+	}
+
+	return response
+}
@@ -82,13 +82,11 @@ var _ = Describe("Validate ServiceAccount token routes and functionality", func(
 			Expect(status).Should(Equal(http.StatusBadRequest))
 		})
 		It("Should handle a not found ServiceAccount in a namespace", func() {
-			tokenRequestSecret := fmt.Sprintf("%s-token-request", serviceAccountName+testutils.NonExistentSuffix)
-			createTestSecret(k8sClient, tokenRequestSecret, namespaceName)
 			uri := fmt.Sprintf("%s/v1/namespaces/%s/%s/%s/token", platformURL, namespaceName, testutils.ServiceAccountParam, serviceAccountName+testutils.NonExistentSuffix)
 			status, response := performHTTPRequest(httpClient, nil, http.MethodPost, uri, "", "", userToken)
 
 			expectedResponse := map[string]interface{}{
-				testutils.ErrorKey:  fmt.Sprintf(controllers.ErrCouldNotCreateTokenRequest, serviceAccountName+testutils.NonExistentSuffix, namespaceName),
+				testutils.ErrorKey:  fmt.Sprintf(controllers.ErrCouldNotGetServiceAccount, serviceAccountName+testutils.NonExistentSuffix, namespaceName),
 				testutils.ReasonKey: testutils.ReasonNotFound,
 			}
 
@@ -138,4 +136,26 @@ var _ = Describe("Validate ServiceAccount token routes and functionality", func(
 			compareResponses(response, expectedResponse)
 		})
 	})
+
+	Context("Validate named token routes", func() {
+		It("Should revoke a single named token of a ServiceAccount", func() {
+			for _, tokenName := range []string{testutils.NamedTokenName, "deploy"} {
+				uri := fmt.Sprintf("%s/v1/namespaces/%s/%s/%s/token?name=%s&description=%s", platformURL, namespaceName, testutils.ServiceAccountParam, serviceAccountName, tokenName, "e2e")
+				status, response := performHTTPRequest(httpClient, nil, http.MethodPost, uri, "", "", userToken)
+				Expect(status).Should(Equal(http.StatusOK))
+				Expect(response[testutils.TokenKey]).ShouldNot(BeEmpty())
+			}
+
+			uri := fmt.Sprintf("%s/v1/namespaces/%s/%s/%s/tokens/%s", platformURL, namespaceName, testutils.ServiceAccountParam, serviceAccountName, testutils.NamedTokenName)
+			status, _ := performHTTPRequest(httpClient, nil, http.MethodDelete, uri, "", "", userToken)
+			Expect(status).Should(Equal(http.StatusOK))
+
+			uri = fmt.Sprintf("%s/v1/namespaces/%s/%s/%s/tokens", platformURL, namespaceName, testutils.ServiceAccountParam, serviceAccountName)
+			status, response := performHTTPRequest(httpClient, nil, http.MethodGet, uri, "", "", userToken)
+			Expect(status).Should(Equal(http.StatusOK))
+			Expect(response["tokens"]).Should(HaveLen(1))
+			Expect(response["tokens"].([]interface{})[0].(map[string]interface{})["name"]).Should(Equal("deploy"))
+			Expect(response["tokens"].([]interface{})[0]).ShouldNot(HaveKey(testutils.TokenKey))
+		})
+	})
 })
@@ -36,10 +36,12 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 				testutils.UsersKey: []map[string]interface{}{
 					{
 						testutils.NameKey: oneUserName,
+						testutils.TypeKey: testutils.UserKind,
 						testutils.RoleKey: testutils.AdminKey,
 					},
 					{
 						testutils.NameKey: secondUserName,
+						testutils.TypeKey: testutils.UserKind,
 						testutils.RoleKey: testutils.AdminKey,
 					},
 				},
@@ -57,6 +59,7 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 
 			expectedResponse := map[string]interface{}{
 				testutils.NameKey: oneUserName,
+				testutils.TypeKey: testutils.UserKind,
 				testutils.RoleKey: testutils.AdminKey,
 			}
 
@@ -107,6 +110,7 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 			status, response := performHTTPRequest(httpClient, bytes.NewBuffer(payload), http.MethodPost, uri, "", "", userToken)
 			expectedResponse := map[string]interface{}{
 				testutils.NameKey: newUserName,
+				testutils.TypeKey: testutils.UserKind,
 				testutils.RoleKey: testutils.ViewerKey,
 			}
 
@@ -148,7 +152,7 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 
 			status, response := performHTTPRequest(httpClient, bytes.NewBuffer(payload), http.MethodPost, uri, "", "", userToken)
 			expectedResponse := map[string]interface{}{
-				testutils.ErrorKey:  "Key: 'User.Role' Error:Field validation for 'Role' failed on the 'oneof' tag",
+				testutils.ErrorKey:  "Key: 'User.Role' Error:Field validation for 'Role' failed on the 'platformrole' tag",
 				testutils.ReasonKey: testutils.ReasonBadRequest,
 			}
 
@@ -167,6 +171,7 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 			status, response := performHTTPRequest(httpClient, bytes.NewBuffer(payload), http.MethodPut, uri, "", "", userToken)
 			expectedResponse := map[string]interface{}{
 				testutils.NameKey: oneUserName,
+				testutils.TypeKey: testutils.UserKind,
 				testutils.RoleKey: testutils.ViewerKey,
 			}
 
@@ -219,7 +224,7 @@ var _ = Describe("Validate Secret routes and functionality", func() {
 
 			status, response := performHTTPRequest(httpClient, bytes.NewBuffer(payload), http.MethodPut, uri, "", "", userToken)
 			expectedResponse := map[string]interface{}{
-				testutils.ErrorKey:  "Key: 'UpdateUserData.Role' Error:Field validation for 'Role' failed on the 'oneof' tag",
+				testutils.ErrorKey:  "Key: 'UpdateUserData.Role' Error:Field validation for 'Role' failed on the 'platformrole' tag",
 				testutils.ReasonKey: testutils.ReasonBadRequest,
 			}
 